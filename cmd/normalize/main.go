@@ -0,0 +1,100 @@
+// Command normalize rewrites the email and phone of existing customers into
+// the normalized form the API stores: lowercased emails and E.164 phones.
+// Rows whose phone cannot be parsed or whose email collides with another
+// customer are left untouched and listed so they can be fixed or merged.
+//
+// Phones without a country code are read in PHONE_REGION, as by the API,
+// unless -region says otherwise.
+//
+//	go run ./cmd/normalize -dry-run
+//	go run ./cmd/normalize -region ID
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/helper"
+)
+
+func main() {
+	loadConfig, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defaultRegion := helper.DefaultPhoneRegion
+	if loadConfig.PhoneRegion != "" {
+		defaultRegion = loadConfig.PhoneRegion
+	}
+
+	dryRun := flag.Bool("dry-run", false, "report the changes without writing them")
+	region := flag.String("region", defaultRegion, "region of phone numbers without a country code")
+	batchSize := flag.Int("batch", 500, "number of customers read per batch")
+	flag.Parse()
+
+	db := config.ConnectionDB(&loadConfig)
+
+	var updated, skipped int
+	var customers []model.Customer
+
+	result := db.Order("id").FindInBatches(&customers, *batchSize, func(tx *gorm.DB, batch int) error {
+		for _, customer := range customers {
+			email := helper.NormalizeEmail(customer.Email)
+
+			phone := customer.Phone
+			if phone != "" {
+				phone, err = helper.NormalizePhone(customer.Phone, *region)
+				if err != nil {
+					log.Printf("customer %d: phone '%s': %v", customer.ID, customer.Phone, err)
+					skipped++
+					continue
+				}
+			}
+
+			if email == customer.Email && phone == customer.Phone {
+				continue
+			}
+
+			var owner int64
+			err = db.Model(&model.Customer{}).Where("lower(email) = ? AND id <> ?", email, customer.ID).Count(&owner).Error
+			if err != nil {
+				return err
+			}
+			if owner > 0 {
+				log.Printf("customer %d: email '%s' is used by another customer, merge them first", customer.ID, customer.Email)
+				skipped++
+				continue
+			}
+
+			updated++
+			if *dryRun {
+				log.Printf("customer %d: '%s' -> '%s', '%s' -> '%s'", customer.ID, customer.Email, email, customer.Phone, phone)
+				continue
+			}
+
+			err = db.Model(&model.Customer{}).Where("id = ?", customer.ID).Updates(map[string]interface{}{
+				"email":      email,
+				"phone":      phone,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": gorm.Expr("now()"),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		log.Fatal(result.Error)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d customers would be normalized, %d skipped\n", updated, skipped)
+		return
+	}
+	fmt.Printf("%d customers normalized, %d skipped\n", updated, skipped)
+}
@@ -9,7 +9,6 @@ import (
 	"scylla/pkg/utils"
 	"scylla/service"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,7 +37,7 @@ func NewAuthController(authService service.AuthService) *AuthController {
 // @Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 // @Router		/auth/login [post]
 func (controller *AuthController) Login(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.LoginRequest{}
@@ -73,7 +72,7 @@ func (controller *AuthController) Login(ctx *gin.Context) {
 // @Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 // @Router		/auth/register [post]
 func (controller *AuthController) Register(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.CreateUserRequest{}
@@ -106,7 +105,7 @@ func (controller *AuthController) Register(ctx *gin.Context) {
 // @Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 // @Router		/auth/forgot-password [post]
 func (controller *AuthController) ForgotPassword(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.ForgotPasswordRequest{}
@@ -140,7 +139,7 @@ func (controller *AuthController) ForgotPassword(ctx *gin.Context) {
 // @Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 // @Router		/auth/check-otp [post]
 func (controller *AuthController) CheckOtp(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.CheckOtpRequest{}
@@ -176,7 +175,7 @@ func (controller *AuthController) CheckOtp(ctx *gin.Context) {
 // @Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 // @Router		/auth/reset-password [patch]
 func (controller *AuthController) ResetPassword(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.ResetPasswordRequest{}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type CustomFieldController struct {
+	customFieldService service.CustomFieldService
+}
+
+func NewCustomFieldController(customFieldService service.CustomFieldService) *CustomFieldController {
+	return &CustomFieldController{
+		customFieldService: customFieldService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create custom field
+//	@Description	Define a custom customer field. Enum fields need options.
+//	@Param			data	body	entity.CreateCustomFieldRequest	true	"create custom field"
+//	@Produce		application/json
+//	@Tags			custom fields
+//	@Success		201	{object}	entity.JsonCreated{data=entity.CustomFieldResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}									"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/custom-fields [post]
+//	@Security		Bearer
+func (handler *CustomFieldController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateCustomFieldRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.customFieldService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update custom field
+//	@Description	Update the label, required flag or enum options of a custom field.
+//	@Param			customFieldId	path	string							true	"custom_field_id"
+//	@Param			data			body	entity.UpdateCustomFieldRequest	true	"update custom field"
+//	@Produce		application/json
+//	@Tags			custom fields
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/custom-fields/{customFieldId} [patch]
+//	@Security		Bearer
+func (handler *CustomFieldController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateCustomFieldRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomFieldParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.CustomFieldId
+
+	handler.customFieldService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete custom field
+//	@Description	Delete a custom field and its values on every customer.
+//	@Param			customFieldId	path	string	true	"custom_field_id"
+//	@Produce		application/json
+//	@Tags			custom fields
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/custom-fields/{customFieldId} [delete]
+//	@Security		Bearer
+func (handler *CustomFieldController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomFieldParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.customFieldService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get all custom fields.
+//	@Description	Get all custom field definitions.
+//	@Produce		application/json
+//	@Tags			custom fields
+//	@Success		200	{object}	entity.Response{data=[]entity.CustomFieldResponse{}}	"Data"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/custom-fields [get]
+//	@Security		Bearer
+func (handler *CustomFieldController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	data := handler.customFieldService.FindAll(c)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type CustomerActivityController struct {
+	activityService service.CustomerActivityService
+}
+
+func NewCustomerActivityController(activityService service.CustomerActivityService) *CustomerActivityController {
+	return &CustomerActivityController{
+		activityService: activityService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create customer activity
+//	@Description	Log a note, call, email or meeting against the customer. The author is the current user; a due_at turns the activity into a follow-up.
+//	@Param			customerId	path	string									true	"customer_id"
+//	@Param			data		body	entity.CreateCustomerActivityRequest	true	"create customer activity"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		201	{object}	entity.JsonCreated{data=entity.CustomerActivityResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/activities [post]
+//	@Security		Bearer
+func (handler *CustomerActivityController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateCustomerActivityRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.CustomerID = params.CustomerId
+
+	data := handler.activityService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update customer activity
+//	@Description	Update customer activity. The author is kept.
+//	@Param			customerId	path	string									true	"customer_id"
+//	@Param			activityId	path	string									true	"activity_id"
+//	@Param			data		body	entity.UpdateCustomerActivityRequest	true	"update customer activity"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/activities/{activityId} [patch]
+//	@Security		Bearer
+func (handler *CustomerActivityController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateCustomerActivityRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerActivityParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.ActivityId
+	request.CustomerID = params.CustomerId
+
+	handler.activityService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete customer activity
+//	@Description	Delete customer activity.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			activityId	path	string	true	"activity_id"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/activities/{activityId} [delete]
+//	@Security		Bearer
+func (handler *CustomerActivityController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerActivityParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.activityService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Get customer activity
+//	@Description	Get customer activity by id.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			activityId	path	string	true	"activity_id"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.CustomerActivityResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/activities/{activityId} [get]
+//	@Security		Bearer
+func (handler *CustomerActivityController) FindById(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerActivityParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.activityService.FindById(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Get customer activities
+//	@Description	Get the activities of a customer, newest first. With due_before only the follow-ups due before that date are listed, soonest first.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			type		query	string	false	"note, call, email or meeting"
+//	@Param			due_before	query	string	false	"due_before (yyyy-mm-dd)"
+//	@Param			limit		query	string	false	"limit"
+//	@Param			page		query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		200	{object}	entity.JsonSuccess{data=[]entity.CustomerActivityResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}											"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}											"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}								"Internal server error"
+//	@Router			/customers/{customerId}/activities [get]
+//	@Security		Bearer
+func (handler *CustomerActivityController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	var dataFilter entity.CustomerActivityQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.activityService.FindAll(c, params, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Get customer timeline
+//	@Description	Get the activities and the change history of a customer merged into one timeline, newest first.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			limit		query	string	false	"limit"
+//	@Param			page		query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			customer activities
+//	@Success		200	{object}	entity.JsonSuccess{data=[]entity.CustomerTimelineResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}											"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}								"Internal server error"
+//	@Router			/customers/{customerId}/timeline [get]
+//	@Security		Bearer
+func (handler *CustomerActivityController) FindTimeline(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	var dataFilter entity.CustomerTimelineQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.activityService.FindTimeline(c, params, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type CustomerAddressController struct {
+	addressService service.CustomerAddressService
+}
+
+func NewCustomerAddressController(addressService service.CustomerAddressService) *CustomerAddressController {
+	return &CustomerAddressController{
+		addressService: addressService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create customer address
+//	@Description	Create customer address. The postal code is checked against the country.
+//	@Param			customerId	path	string								true	"customer_id"
+//	@Param			data		body	entity.CreateCustomerAddressRequest	true	"create customer address"
+//	@Produce		application/json
+//	@Tags			customer addresses
+//	@Success		201	{object}	entity.JsonCreated{data=entity.CustomerAddressResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/addresses [post]
+//	@Security		Bearer
+func (handler *CustomerAddressController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateCustomerAddressRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.CustomerID = params.CustomerId
+
+	data := handler.addressService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update customer address
+//	@Description	Update customer address.
+//	@Param			customerId	path	string								true	"customer_id"
+//	@Param			addressId	path	string								true	"address_id"
+//	@Param			data		body	entity.UpdateCustomerAddressRequest	true	"update customer address"
+//	@Produce		application/json
+//	@Tags			customer addresses
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/addresses/{addressId} [patch]
+//	@Security		Bearer
+func (handler *CustomerAddressController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateCustomerAddressRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerAddressParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.AddressId
+	request.CustomerID = params.CustomerId
+
+	handler.addressService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete customer address
+//	@Description	Delete customer address.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			addressId	path	string	true	"address_id"
+//	@Produce		application/json
+//	@Tags			customer addresses
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/addresses/{addressId} [delete]
+//	@Security		Bearer
+func (handler *CustomerAddressController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerAddressParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.addressService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get customer address by id.
+//	@Description	Get customer address by id.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			addressId	path	string	true	"address_id"
+//	@Produce		application/json
+//	@Tags			customer addresses
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.CustomerAddressResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/addresses/{addressId} [get]
+//	@Security		Bearer
+func (handler *CustomerAddressController) FindById(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerAddressParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.addressService.FindById(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get all customer addresses.
+//	@Description	Get all addresses of a customer, defaults first.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Produce		application/json
+//	@Tags			customer addresses
+//	@Success		200	{object}	entity.Response{data=[]entity.CustomerAddressResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/addresses [get]
+//	@Security		Bearer
+func (handler *CustomerAddressController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.addressService.FindAll(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+// attachmentFormOverhead is allowed on top of the file size for the rest of
+// the multipart body.
+const attachmentFormOverhead = 1 << 20
+
+type CustomerAttachmentController struct {
+	attachmentService service.CustomerAttachmentService
+	maxSize           int64
+}
+
+func NewCustomerAttachmentController(attachmentService service.CustomerAttachmentService, maxSize int64) *CustomerAttachmentController {
+	if maxSize <= 0 {
+		maxSize = config.DefaultAttachmentMaxSize
+	}
+
+	return &CustomerAttachmentController{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+	}
+}
+
+//	 Note 		    godoc
+//
+//	@Summary		Upload customer attachment
+//	@Description	Attach a file such as a contract or an ID scan to the customer. The type is detected from the content; PDF, Word, Excel and OpenDocument text documents and JPEG, PNG or WebP images up to the configured size are accepted.
+//	@Produce		application/json
+//	@Accept			multipart/form-data
+//	@Tags			customer attachments
+//	@Param			customerId	path		string	true	"customer_id"
+//	@Param			file		formData	file	true	"attachment"
+//	@Success		201	{object}	entity.JsonCreated{data=entity.CustomerAttachmentResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}											"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}											"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}								"Internal server error"
+//	@Router			/customers/{customerId}/attachments [post]
+//	@Security		Bearer
+func (handler *CustomerAttachmentController) Upload(ctx *gin.Context) {
+	// Large files take longer to upload than the server timeouts allow.
+	utils.LiftDeadlines(ctx)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	// The body is cut off past the limit instead of being spooled to disk
+	// in full before the service sees the size.
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, handler.maxSize+attachmentFormOverhead)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	// The upload has been read, so the timeout only bounds storing it.
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	data := handler.attachmentService.Upload(c, params, file)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Upload Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Get customer attachments
+//	@Description	Get the attachments of a customer, newest first.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Produce		application/json
+//	@Tags			customer attachments
+//	@Success		200	{object}	entity.JsonSuccess{data=[]entity.CustomerAttachmentResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}											"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}								"Internal server error"
+//	@Router			/customers/{customerId}/attachments [get]
+//	@Security		Bearer
+func (handler *CustomerAttachmentController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.attachmentService.FindAll(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Get attachment download link
+//	@Description	Get a short-lived signed link to download the attachment. The link needs no token and stops working once expires_at has passed.
+//	@Param			customerId		path	string	true	"customer_id"
+//	@Param			attachmentId	path	string	true	"attachment_id"
+//	@Produce		application/json
+//	@Tags			customer attachments
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.AttachmentURLResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}									"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/customers/{customerId}/attachments/{attachmentId}/url [get]
+//	@Security		Bearer
+func (handler *CustomerAttachmentController) SignedURL(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerAttachmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.attachmentService.SignedURL(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Download attachment
+//	@Description	Download an attachment through a signed link obtained from the attachment url endpoint.
+//	@Param			attachmentId	path	string	true	"attachment_id"
+//	@Param			expires			query	int		true	"expiry as unix time"
+//	@Param			signature		query	string	true	"signature"
+//	@Produce		application/octet-stream
+//	@Tags			customer attachments
+//	@Success		200	{file}		binary								"File"
+//	@Failure		401	{object}	entity.JsonUnauthorized{}			"Invalid or expired link"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/attachments/{attachmentId}/download [get]
+func (handler *CustomerAttachmentController) Download(ctx *gin.Context) {
+	// Large files take longer to send than the server timeout allows, and
+	// the body is read from storage for as long as the download lasts.
+	utils.LiftDeadlines(ctx)
+	c := ctx.Request.Context()
+
+	var request entity.DownloadAttachmentRequest
+
+	if err := ctx.ShouldBindUri(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	attachment, body := handler.attachmentService.Download(c, request)
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete customer attachment
+//	@Description	Delete customer attachment and its stored file.
+//	@Param			customerId		path	string	true	"customer_id"
+//	@Param			attachmentId	path	string	true	"attachment_id"
+//	@Produce		application/json
+//	@Tags			customer attachments
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/attachments/{attachmentId} [delete]
+//	@Security		Bearer
+func (handler *CustomerAttachmentController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerAttachmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.attachmentService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
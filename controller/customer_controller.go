@@ -2,17 +2,15 @@ package controller
 
 import (
 	"context"
-	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"scylla/entity"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/tabular"
 	"scylla/pkg/utils"
 	"scylla/service"
-	"time"
 )
 
 type CustomerController struct {
@@ -39,7 +37,7 @@ func NewCustomerController(customerService service.CustomerService) *CustomerCon
 //	@Router			/customers [post]
 //	@Security		Bearer
 func (handler *CustomerController) Create(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.CreateCustomerRequest{}
@@ -64,30 +62,31 @@ func (handler *CustomerController) Create(ctx *gin.Context) {
 //	 Note		    godoc
 //
 //	@Summary		Create customer batch
-//	@Description	Create customer batch.
+//	@Description	Create customer batch. on_conflict decides what happens to customers whose email exists: error (default), skip or update.
 //	@Param			data	body	entity.CreateCustomerBatchRequest	true	"create customer batch"
 //	@Produce		application/json
 //	@Tags			customers
-//	@Success		201	{object}	entity.JsonCreated{data=nil}"Data"
+//	@Success		201	{object}	entity.JsonCreated{data=entity.UpsertCustomerResponse{}}"Data"
 //	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
 //	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
 //	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 //	@Router			/customers/batch [post]
 //	@Security		Bearer
 func (handler *CustomerController) CreateBatch(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.CreateCustomerBatchRequest{}
 	err := ctx.ShouldBindJSON(&request)
 	helper.ErrorPanic(err)
 
-	handler.customerService.CreateBatch(c, request)
+	data := handler.customerService.CreateBatch(c, request)
 
 	webResponse := entity.Response{
 		Code:    http.StatusCreated,
 		Status:  "Created",
 		Message: "Created Batch Successful",
+		Data:    data,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
 	ctx.Header("Content-Type", "application/json")
@@ -100,16 +99,18 @@ func (handler *CustomerController) CreateBatch(ctx *gin.Context) {
 //	@Description	update customer.
 //	@Param			data		body	entity.UpdateCustomerRequest	true	"update customer"
 //	@Param			customerId	path	string							true	"customer_id"
+//	@Param			If-Match	header	string							true	"ETag returned by get customer"
 //	@Produce		application/json
 //	@Tags			customers
 //	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
 //	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
 //	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}		"Version mismatch"
 //	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 //	@Router			/customers/{customerId} [patch]
 //	@Security		Bearer
 func (handler *CustomerController) Update(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.UpdateCustomerRequest{}
@@ -123,6 +124,7 @@ func (handler *CustomerController) Update(ctx *gin.Context) {
 	}
 
 	request.ID = params.CustomerId
+	request.Version = utils.ParseIfMatch(ctx)
 
 	handler.customerService.Update(c, request)
 
@@ -133,6 +135,94 @@ func (handler *CustomerController) Update(ctx *gin.Context) {
 		Data:    nil,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, request.Version+1)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update batch customer
+//	@Description	Update many customers in one transaction, either by listing items with their own changes or by a filter with one change set. Returns 207 when some items failed, and 422 when nothing was applied (all failed, or atomic mode rolled back).
+//	@Param			data	body	entity.UpdateBatchCustomerRequest	true	"update batch customer"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.UpdateBatchCustomerResponse{}}	"Data"
+//	@Success		207	{object}	entity.Response{data=entity.UpdateBatchCustomerResponse{}}		"Partially applied"
+//	@Failure		400	{object}	entity.JsonBadRequest{}											"Validation error"
+//	@Failure		422	{object}	entity.Response{data=entity.UpdateBatchCustomerResponse{}}		"Nothing applied"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}								"Internal server error"
+//	@Router			/customers/batch [patch]
+//	@Security		Bearer
+func (handler *CustomerController) UpdateBatch(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateBatchCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.customerService.UpdateBatch(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Batch Successful",
+		Data:    data,
+	}
+	if data.Failed > 0 && data.Applied {
+		webResponse.Code = http.StatusMultiStatus
+		webResponse.Status = "MULTI STATUS"
+		webResponse.Message = "Update Batch Partially Applied"
+	} else if data.Failed > 0 {
+		webResponse.Code = http.StatusUnprocessableEntity
+		webResponse.Status = "UNPROCESSABLE ENTITY"
+		webResponse.Message = "Update Batch Not Applied"
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(webResponse.Code, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		delete customer
+//	@Description	delete customer.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			If-Match	header	string	true	"ETag returned by get customer"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}		"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId} [delete]
+//	@Security		Bearer
+func (handler *CustomerController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request := entity.DeleteCustomerRequest{
+		ID:      params.CustomerId,
+		Version: utils.ParseIfMatch(ctx),
+	}
+
+	handler.customerService.Delete(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
 	ctx.Header("Content-Type", "application/json")
 	ctx.JSON(http.StatusOK, webResponse)
 }
@@ -151,7 +241,7 @@ func (handler *CustomerController) Update(ctx *gin.Context) {
 //	@Router			/customers/batch [delete]
 //	@Security		Bearer
 func (handler *CustomerController) DeleteBatch(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.DeleteBatchCustomerRequest{}
@@ -185,7 +275,7 @@ func (handler *CustomerController) DeleteBatch(ctx *gin.Context) {
 //	@Router			/customers/{customerId} [get]
 //	@Security		Bearer
 func (handler *CustomerController) FindById(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	var params entity.CustomerParams
@@ -202,6 +292,7 @@ func (handler *CustomerController) FindById(ctx *gin.Context) {
 		Data:   data,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, data.Version)
 	ctx.Header("Content-Type", "application/json")
 	ctx.JSON(http.StatusOK, webResponse)
 }
@@ -216,8 +307,13 @@ func (handler *CustomerController) FindById(ctx *gin.Context) {
 //	@Param			start_date	query	string	false	"start_date"
 //	@Param			username	query	string	false	"username"
 //	@Param			email		query	string	false	"email"
+//	@Param			tag			query	string	false	"comma separated tag names, all must match"
+//	@Param			status		query	string	false	"comma separated statuses"
+//	@Param			owner_id	query	int		false	"id of the account manager"
+//	@Param			mine		query	bool	false	"only customers managed by the current user"
+//	@Param			custom_fields[key]	query	string	false	"custom field value, e.g. custom_fields[industry]=retail"
 //	@Param			end_date	query	string	false	"end_date"
-//	@Param			sort		query	string	false	"sort"
+//	@Param			sort		query	string	false	"comma separated column:direction, e.g. created_at:desc; columns id, username, email, phone, status, owner_id, created_at"
 //	@Tags			customers
 //	@Success		200	{object}	entity.Response{data=[]entity.CustomerResponse{}}	"Data"
 //	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
@@ -226,7 +322,7 @@ func (handler *CustomerController) FindById(ctx *gin.Context) {
 //	@Router			/customers [get]
 //	@Security		Bearer
 func (handler *CustomerController) FindAllPaging(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	var dataFilter entity.CustomerQueryFilter
@@ -234,6 +330,7 @@ func (handler *CustomerController) FindAllPaging(ctx *gin.Context) {
 	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
 		panic(exception.NewBadRequestHandler(err.Error()))
 	}
+	dataFilter.CustomFields = ctx.QueryMap("custom_fields")
 
 	response, paging := handler.customerService.FindAllPaging(c, dataFilter)
 
@@ -250,22 +347,33 @@ func (handler *CustomerController) FindAllPaging(ctx *gin.Context) {
 
 //	 Note 		    godoc
 //
-//	@Summary		Export Excel customer.
-//	@Description	Export Excel customer.
+//	@Summary		Export customer.
+//	@Description	Start a job exporting customer as xlsx, CSV, JSON or NDJSON, picked by the format parameter or else the Accept header. xlsx is the default. Poll the job until it has succeeded, then download the file from its result.
 //	@Produce		application/json
 //	@Tags			customers
 //	@Param			start_date	query		string	false	"start_date"
 //	@Param			end_date	query		string	false	"end_date"
 //	@Param			username	query		string	false	"username"
 //	@Param			email		query		string	false	"email"
-//	@Success		200			{object}	entity.JsonSuccess{data=string}"Data"
-//	@Failure		400			{object}	entity.JsonBadRequest{}				"Validation error"
-//	@Failure		404			{object}	entity.JsonNotFound{}				"Data not found"
-//	@Failure		500			{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Param			tag			query		string	false	"comma separated tag names, all must match"
+//	@Param			status		query		string	false	"comma separated statuses"
+//	@Param			owner_id	query		int		false	"id of the account manager"
+//	@Param			mine		query		bool	false	"only customers managed by the current user"
+//	@Param			format		query		string	false	"xlsx, csv, json or ndjson"
+//	@Param			delimiter	query		string	false	"CSV delimiter: a character or comma (default), semicolon, tab or pipe"
+//	@Param			encoding	query		string	false	"CSV encoding: utf-8 (default), utf-8-bom, utf-16le, windows-1252 or iso-8859-1"
+//	@Param			columns		query		string	false	"comma separated columns in order: id, username, email, phone, address, tags, status, owner, created_at and custom field keys; all by default"
+//	@Param			sheet		query		string	false	"xlsx worksheet name"
+//	@Param			timezone	query		string	false	"IANA time zone of timestamps, e.g. Asia/Jakarta; UTC by default"
+//	@Param			date_format	query		string	false	"CSV and xlsx date format built from yyyy, yy, mm, m, dd and d, e.g. dd/mm/yyyy; rejected for JSON and NDJSON"
+//	@Param			locale		query		string	false	"CSV date format and decimal separator, xlsx date format: en-US, en-GB, id-ID, de-DE, fr-FR, nl-NL or ja-JP; rejected for JSON and NDJSON"
+//	@Success		202			{object}	entity.JsonAccepted{data=entity.JobResponse{}}	"Job"
+//	@Failure		400			{object}	entity.JsonBadRequest{}							"Validation error"
+//	@Failure		500			{object}	entity.JsonInternalServerError{}				"Internal server error"
 //	@Router			/customers/export [get]
 //	@Security		Bearer
 func (controller *CustomerController) Export(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	var dataFilter entity.CustomerQueryFilter
@@ -273,57 +381,385 @@ func (controller *CustomerController) Export(ctx *gin.Context) {
 	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
 		panic(exception.NewBadRequestHandler(err.Error()))
 	}
+	dataFilter.CustomFields = ctx.QueryMap("custom_fields")
 
-	filePath, err := controller.customerService.Export(c, dataFilter)
-	helper.ErrorPanic(err)
-	defer os.Remove(filePath) // Remove the file after the function exits
+	exportRequest := entity.ExportRequest{}
+	if err := ctx.ShouldBindQuery(&exportRequest); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	options := utils.ExportOptions(ctx, exportRequest)
 
-	fileName := filepath.Base(filePath)
-	// Set headers for the Excel file
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	data := controller.customerService.Export(c, dataFilter, options)
 
-	// Read the Excel file and write to the response body
-	data, err := os.ReadFile(filePath)
-	helper.ErrorPanic(err)
+	webResponse := entity.Response{
+		Code:    http.StatusAccepted,
+		Status:  "Accepted",
+		Message: "Export Queued",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusAccepted, webResponse)
+}
 
-	// Write data to the response body
-	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
+//	 Note 		    godoc
+//
+//	@Summary		Download the customer import template.
+//	@Description	Excel workbook with the import columns, an example row, dropdowns for enumerated columns and a Notes sheet describing each column's rules.
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Tags			customers
+//	@Success		200	{file}		binary								"File"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/import/template [get]
+//	@Security		Bearer
+func (controller *CustomerController) ImportTemplate(ctx *gin.Context) {
+	// The workbook is written straight to the client, for as long as the
+	// client takes to read it.
+	utils.LiftDeadlines(ctx)
+	c := utils.WithActor(ctx.Request.Context(), ctx)
+
+	options := tabular.Options{Format: tabular.FormatXLSX}
+	utils.StreamExport(ctx, options, "customer_import_template", func(w io.Writer) error {
+		return controller.customerService.ImportTemplate(c, w)
+	})
 }
 
 //	 Note 		    godoc
 //
-//	@Summary		Import Excel customer.
-//	@Description	Import Excel customer.
+//	@Summary		Import customer.
+//	@Description	Start a job importing customer from an xlsx, CSV, JSON or NDJSON file, detected from its content. Columns are found by header name, ignoring case, spaces and punctuation, or by a saved profile and the mapping. An optional Owner column takes the email of the account manager. With on_conflict=skip or update, rows whose email already exists are skipped or overwritten instead of rejected. mode=partial writes the valid rows even when others are invalid, mode=atomic writes every row or none and mode=dry_run writes nothing. The job's result holds an entity.UpsertCustomerResponse, or an entity.ImportPreviewResponse for dry runs. When rows are rejected the job fails and its error links to the file annotated with the errors in error_file.
 //	@Produce		application/json
 //	@Accept			multipart/form-data
 //	@Tags			customers
-//	@Param			file	formData	file	true	"Import Excel customer"
-//	@Success		200		{object}	entity.JsonSuccess{data=string}"Data"
+//	@Param			file		formData	file	true	"xlsx, CSV, JSON or NDJSON file of customers"
+//	@Param			on_conflict	formData	string	false	"error (default), skip or update rows whose email exists"
+//	@Param			mode		formData	string	false	"partial (default), atomic or dry_run"
+//	@Param			profile		formData	string	false	"name of a saved customers import profile"
+//	@Param			mapping		formData	string	false	"JSON object from file header to column key, overriding the profile"
+//	@Param			delimiter	formData	string	false	"CSV delimiter, guessed from the header line by default"
+//	@Param			encoding	formData	string	false	"CSV encoding when the file has no byte order mark: utf-8 (default), utf-16le, windows-1252 or iso-8859-1"
+//	@Success		202			{object}	entity.JsonAccepted{data=entity.JobResponse{}}"Job"
 //	@Failure		400		{object}	entity.JsonBadRequest{}				"Validation error"
 //	@Failure		404		{object}	entity.JsonNotFound{}				"Data not found"
 //	@Failure		500		{object}	entity.JsonInternalServerError{}	"Internal server error"
 //	@Router			/customers/import [post]
 //	@Security		Bearer
 func (controller *CustomerController) Import(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Large files take longer to upload than the server timeouts allow.
+	utils.LiftDeadlines(ctx)
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
 		panic(exception.NewBadRequestHandler(err.Error()))
 	}
 
-	err = controller.customerService.Import(c, file)
-	helper.ErrorPanic(err)
+	request := entity.ImportCustomerRequest{}
+	if err := ctx.ShouldBind(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	// The upload has been read, so the timeout only bounds queueing the job.
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	data := controller.customerService.Import(c, file, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusAccepted,
+		Status:  "Accepted",
+		Message: "Import Queued",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusAccepted, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get customer history.
+//	@Description	Get the change history of a customer, newest first.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			limit		query	string	false	"limit"
+//	@Param			page		query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.Response{data=[]entity.CustomerRevisionResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/history [get]
+//	@Security		Bearer
+func (handler *CustomerController) FindHistory(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	var dataFilter entity.CustomerHistoryQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.customerService.FindHistory(c, params, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Revert customer.
+//	@Description	Restore a customer to the state recorded by a revision.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			revisionId	path	string	true	"revision_id"
+//	@Param			If-Match	header	string	true	"ETag returned by get customer"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}		"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/history/{revisionId}/revert [post]
+//	@Security		Bearer
+func (handler *CustomerController) Revert(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var request entity.RevertCustomerRequest
+
+	if err := ctx.ShouldBindUri(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.Version = utils.ParseIfMatch(ctx)
+
+	handler.customerService.Revert(c, request)
 
 	webResponse := entity.Response{
 		Code:    http.StatusOK,
-		Status:  "Ok",
-		Message: "Import Successful",
+		Status:  "OK",
+		Message: "Revert Successful",
 		Data:    nil,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
 	ctx.Header("Content-Type", "application/json")
 	ctx.JSON(http.StatusOK, webResponse)
 }
+
+//	 Note		godoc
+//
+//	@Summary		Find duplicate customers.
+//	@Description	List pairs of customers that look like the same person, highest score first. The score adds 0.5 for a matching email, 0.3 for a matching phone and up to 0.2 for name similarity.
+//	@Param			min_score	query	number	false	"minimum score between 0 and 1"
+//	@Param			limit		query	string	false	"limit"
+//	@Param			page		query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.Response{data=[]entity.CustomerDuplicateResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/duplicates [get]
+//	@Security		Bearer
+func (handler *CustomerController) FindDuplicates(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var dataFilter entity.CustomerDuplicateQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.customerService.FindDuplicates(c, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Merge customers.
+//	@Description	Merge duplicates into the survivor. Addresses, tags, activities and attachments move to the survivor, the duplicates are deleted and the merge is recorded in the history of every customer involved.
+//	@Param			If-Match	header	string						true	"ETag of the survivor"
+//	@Param			data		body	entity.MergeCustomerRequest	true	"merge customers"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.CustomerResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}								"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}						"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/customers/merge [post]
+//	@Security		Bearer
+func (handler *CustomerController) Merge(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.MergeCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	request.Version = utils.ParseIfMatch(ctx)
+
+	data := handler.customerService.Merge(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Merge Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, data.Version)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Transition customer status.
+//	@Description	Move the customer to another lifecycle status. Only the configured transitions are allowed (by default lead→active, active→churned and churned→active); the time each status was entered is kept and a customer.status_changed event is published.
+//	@Param			customerId	path	string								true	"customer_id"
+//	@Param			data		body	entity.TransitionCustomerRequest	true	"transition customer"
+//	@Param			If-Match	header	string								true	"ETag of the customer"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.CustomerResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}								"Data not found"
+//	@Failure		409	{object}	entity.JsonConflict{}								"Transition not allowed"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}						"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/customers/{customerId}/transition [post]
+//	@Security		Bearer
+func (handler *CustomerController) Transition(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.TransitionCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.CustomerID = params.CustomerId
+	request.Version = utils.ParseIfMatch(ctx)
+
+	data := handler.customerService.Transition(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Transition Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, data.Version)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Assign customer owner.
+//	@Description	Assign or reassign the account manager of the customer. A null owner_id unassigns it.
+//	@Param			customerId	path	string								true	"customer_id"
+//	@Param			If-Match	header	string								true	"ETag of the customer"
+//	@Param			data		body	entity.AssignCustomerOwnerRequest	true	"assign owner"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.CustomerResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}								"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}						"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/customers/{customerId}/owner [put]
+//	@Security		Bearer
+func (handler *CustomerController) AssignOwner(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.AssignCustomerOwnerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.CustomerID = params.CustomerId
+	request.Version = utils.ParseIfMatch(ctx)
+
+	data := handler.customerService.AssignOwner(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Assign Owner Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, data.Version)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Reassign customers.
+//	@Description	Move every customer of one account manager to another, e.g. when a user leaves. A null to_owner_id leaves the customers unassigned.
+//	@Param			data	body	entity.ReassignCustomersRequest	true	"reassign customers"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.ReassignCustomersResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/reassign [post]
+//	@Security		Bearer
+func (handler *CustomerController) Reassign(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.ReassignCustomersRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.customerService.Reassign(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Reassign Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type CustomerPrivacyController struct {
+	privacyService service.CustomerPrivacyService
+}
+
+func NewCustomerPrivacyController(privacyService service.CustomerPrivacyService) *CustomerPrivacyController {
+	return &CustomerPrivacyController{
+		privacyService: privacyService,
+	}
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get customer personal data.
+//	@Description	Get everything stored about a customer, including addresses, history and erasure receipts, for a data subject access request.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Produce		application/json
+//	@Tags			customer privacy
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.PersonalDataResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}									"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}									"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/customers/{customerId}/personal-data [get]
+//	@Security		Bearer
+func (handler *CustomerPrivacyController) PersonalData(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.privacyService.PersonalData(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Erase customer personal data
+//	@Description	Anonymize the customer's username, email, phone, address, custom fields, addresses and history in place and record an erasure receipt. The customer id, tags and revision timestamps are kept.
+//	@Param			customerId	path	string						true	"customer_id"
+//	@Param			data		body	entity.EraseCustomerRequest	true	"erase customer"
+//	@Produce		application/json
+//	@Tags			customer privacy
+//	@Success		201	{object}	entity.JsonCreated{data=entity.CustomerErasureResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}										"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/erase [post]
+//	@Security		Bearer
+func (handler *CustomerPrivacyController) Erase(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.EraseCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.CustomerParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.CustomerID = params.CustomerId
+
+	data := handler.privacyService.Erase(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Erase Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
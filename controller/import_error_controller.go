@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type ImportErrorController struct {
+	importErrorService service.ImportErrorService
+}
+
+func NewImportErrorController(importErrorService service.ImportErrorService) *ImportErrorController {
+	return &ImportErrorController{
+		importErrorService: importErrorService,
+	}
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Download import errors
+//	@Description	Download the file of a failed import annotated with its errors, through the signed error_file link of the import response. Offending cells are highlighted and an Errors column lists the problems of each row.
+//	@Param			errorFileId	path	string	true	"error_file_id"
+//	@Param			expires		query	int		true	"expiry as unix time"
+//	@Param			signature	query	string	true	"signature"
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Tags			imports
+//	@Success		200	{file}		binary								"File"
+//	@Failure		401	{object}	entity.JsonUnauthorized{}			"Invalid or expired link"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/imports/errors/{errorFileId} [get]
+func (handler *ImportErrorController) Download(ctx *gin.Context) {
+	// Sending the workbook to a slow client takes longer than the server
+	// timeouts allow.
+	utils.LiftDeadlines(ctx)
+	c := ctx.Request.Context()
+
+	var request entity.DownloadImportErrorsRequest
+
+	if err := ctx.ShouldBindUri(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	body := handler.importErrorService.Download(c, request)
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", body, map[string]string{
+		"Content-Disposition":    "attachment; filename=import-errors.xlsx",
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type ImportProfileController struct {
+	importProfileService service.ImportProfileService
+}
+
+func NewImportProfileController(importProfileService service.ImportProfileService) *ImportProfileController {
+	return &ImportProfileController{
+		importProfileService: importProfileService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create import profile
+//	@Description	Save a named column mapping for the imports of customers or users. The mapping goes from a header in the file to the key of the column it holds; an empty key ignores the header.
+//	@Param			data	body	entity.CreateImportProfileRequest	true	"create import profile"
+//	@Produce		application/json
+//	@Tags			import profiles
+//	@Success		201	{object}	entity.JsonCreated{data=entity.ImportProfileResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}									"Validation error"
+//	@Failure		409	{object}	entity.JsonConflict{}									"Name already taken"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/import-profiles [post]
+//	@Security		Bearer
+func (handler *ImportProfileController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateImportProfileRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.importProfileService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update import profile
+//	@Description	Rename an import profile or replace its mapping.
+//	@Param			importProfileId	path	string							true	"import_profile_id"
+//	@Param			data			body	entity.UpdateImportProfileRequest	true	"update import profile"
+//	@Produce		application/json
+//	@Tags			import profiles
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		409	{object}	entity.JsonConflict{}				"Name already taken"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/import-profiles/{importProfileId} [patch]
+//	@Security		Bearer
+func (handler *ImportProfileController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateImportProfileRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.ImportProfileParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.ImportProfileId
+
+	handler.importProfileService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete import profile
+//	@Description	Delete an import profile.
+//	@Param			importProfileId	path	string	true	"import_profile_id"
+//	@Produce		application/json
+//	@Tags			import profiles
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/import-profiles/{importProfileId} [delete]
+//	@Security		Bearer
+func (handler *ImportProfileController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.ImportProfileParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.importProfileService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get all import profiles.
+//	@Description	Get the saved import profiles, optionally of one resource.
+//	@Param			resource	query	string	false	"customers or users"
+//	@Produce		application/json
+//	@Tags			import profiles
+//	@Success		200	{object}	entity.Response{data=[]entity.ImportProfileResponse{}}	"Data"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/import-profiles [get]
+//	@Security		Bearer
+func (handler *ImportProfileController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var dataFilter entity.ImportProfileQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.importProfileService.FindAll(c, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
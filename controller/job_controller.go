@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type JobController struct {
+	jobService service.JobService
+}
+
+func NewJobController(jobService service.JobService) *JobController {
+	return &JobController{
+		jobService: jobService,
+	}
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get job.
+//	@Description	Poll an import or export job started by the current user: its state (queued, running, succeeded, failed or cancelled), progress percentage, result and error.
+//	@Param			jobId	path	string	true	"job_id"
+//	@Produce		application/json
+//	@Tags			jobs
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.JobResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}							"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}							"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}				"Internal server error"
+//	@Router			/jobs/{jobId} [get]
+//	@Security		Bearer
+func (handler *JobController) FindById(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.JobParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.jobService.FindById(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Download job result.
+//	@Description	Download the file produced by a succeeded export job.
+//	@Param			jobId	path	string	true	"job_id"
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv,application/json,application/x-ndjson
+//	@Tags			jobs
+//	@Success		200	{file}		binary								"File"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		409	{object}	entity.JsonConflict{}				"Job has not succeeded"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/jobs/{jobId}/result [get]
+//	@Security		Bearer
+func (handler *JobController) Result(ctx *gin.Context) {
+	// Large results take longer to send than the server timeout allows, and
+	// the body is read from storage for as long as the download lasts.
+	utils.LiftDeadlines(ctx)
+	c := utils.WithActor(ctx.Request.Context(), ctx)
+
+	var params entity.JobParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	job, body := handler.jobService.Result(c, params)
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, job.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Cancel job.
+//	@Description	Cancel a queued or running job. Rows an import already wrote are kept.
+//	@Param			jobId	path	string	true	"job_id"
+//	@Produce		application/json
+//	@Tags			jobs
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.JobResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}							"Data not found"
+//	@Failure		409	{object}	entity.JsonConflict{}							"Job already finished"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}				"Internal server error"
+//	@Router			/jobs/{jobId}/cancel [post]
+//	@Security		Bearer
+func (handler *JobController) Cancel(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.JobParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.jobService.Cancel(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Cancel Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
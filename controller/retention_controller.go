@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type RetentionController struct {
+	retentionService service.RetentionService
+}
+
+func NewRetentionController(retentionService service.RetentionService) *RetentionController {
+	return &RetentionController{
+		retentionService: retentionService,
+	}
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get retention report.
+//	@Description	Dry run of the retention policies: how many rows or files each would purge now, and the oldest of them. Nothing is deleted.
+//	@Produce		application/json
+//	@Tags			retention
+//	@Success		200	{object}	entity.Response{data=[]entity.RetentionReportResponse{}}	"Data"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}							"Internal server error"
+//	@Router			/retention/report [get]
+//	@Security		Bearer
+func (handler *RetentionController) Report(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	data := handler.retentionService.Report(c)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get retention logs.
+//	@Description	Get the log of executed purges, newest first.
+//	@Param			policy	query	string	false	"policy name"
+//	@Param			limit	query	string	false	"limit"
+//	@Param			page	query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			retention
+//	@Success		200	{object}	entity.Response{data=[]entity.RetentionLogResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}									"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}						"Internal server error"
+//	@Router			/retention/logs [get]
+//	@Security		Bearer
+func (handler *RetentionController) FindLogs(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var dataFilter entity.RetentionLogQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.retentionService.FindLogs(c, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
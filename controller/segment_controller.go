@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type SegmentController struct {
+	segmentService service.SegmentService
+}
+
+func NewSegmentController(segmentService service.SegmentService) *SegmentController {
+	return &SegmentController{
+		segmentService: segmentService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create segment
+//	@Description	Create a saved customer segment. The filter uses the query keys of GET /customers.
+//	@Param			data	body	entity.CreateSegmentRequest	true	"create segment"
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		201	{object}	entity.JsonCreated{data=entity.SegmentResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/segments [post]
+//	@Security		Bearer
+func (handler *SegmentController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateSegmentRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.segmentService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update segment
+//	@Description	Update segment.
+//	@Param			segmentId	path	string						true	"segment_id"
+//	@Param			data		body	entity.UpdateSegmentRequest	true	"update segment"
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/segments/{segmentId} [patch]
+//	@Security		Bearer
+func (handler *SegmentController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateSegmentRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.SegmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.SegmentId
+
+	handler.segmentService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete segment
+//	@Description	Delete segment.
+//	@Param			segmentId	path	string	true	"segment_id"
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/segments/{segmentId} [delete]
+//	@Security		Bearer
+func (handler *SegmentController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.SegmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.segmentService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get segment by id.
+//	@Description	Get segment by id.
+//	@Param			segmentId	path	string	true	"segment_id"
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		200	{object}	entity.JsonSuccess{data=entity.SegmentResponse{}}	"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}								"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/segments/{segmentId} [get]
+//	@Security		Bearer
+func (handler *SegmentController) FindById(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.SegmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	data := handler.segmentService.FindById(c, params)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get all segments.
+//	@Description	Get all segments.
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		200	{object}	entity.Response{data=[]entity.SegmentResponse{}}	"Data"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/segments [get]
+//	@Security		Bearer
+func (handler *SegmentController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	data := handler.segmentService.FindAll(c)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get segment customers.
+//	@Description	Evaluate the segment filter and return the matching customers.
+//	@Param			segmentId	path	string	true	"segment_id"
+//	@Param			limit		query	string	false	"limit"
+//	@Param			page		query	string	false	"page"
+//	@Produce		application/json
+//	@Tags			segments
+//	@Success		200	{object}	entity.Response{data=[]entity.CustomerResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}								"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}								"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}					"Internal server error"
+//	@Router			/segments/{segmentId}/customers [get]
+//	@Security		Bearer
+func (handler *SegmentController) FindCustomers(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.SegmentParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	var dataFilter entity.SegmentQueryFilter
+
+	if err := ctx.ShouldBindQuery(&dataFilter); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	response, paging := handler.segmentService.FindCustomers(c, params, dataFilter)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+		Meta:   &paging,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
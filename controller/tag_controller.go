@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/service"
+)
+
+type TagController struct {
+	tagService service.TagService
+}
+
+func NewTagController(tagService service.TagService) *TagController {
+	return &TagController{
+		tagService: tagService,
+	}
+}
+
+//	 Note     		godoc
+//
+//	@Summary		Create tag
+//	@Description	Create tag.
+//	@Param			data	body	entity.CreateTagRequest	true	"create tag"
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		201	{object}	entity.JsonCreated{data=entity.TagResponse{}}	"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}							"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}				"Internal server error"
+//	@Router			/tags [post]
+//	@Security		Bearer
+func (handler *TagController) Create(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.CreateTagRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	data := handler.tagService.Create(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusCreated,
+		Status:  "Created",
+		Message: "Created Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusCreated, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Update tag
+//	@Description	Rename a tag.
+//	@Param			tagId	path	string					true	"tag_id"
+//	@Param			data	body	entity.UpdateTagRequest	true	"update tag"
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/tags/{tagId} [patch]
+//	@Security		Bearer
+func (handler *TagController) Update(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.UpdateTagRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	var params entity.TagParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request.ID = params.TagId
+
+	handler.tagService.Update(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Update Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	    Note		    godoc
+//
+//	@Summary		Delete tag
+//	@Description	Delete tag and remove it from every customer.
+//	@Param			tagId	path	string	true	"tag_id"
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/tags/{tagId} [delete]
+//	@Security		Bearer
+func (handler *TagController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.TagParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	handler.tagService.Delete(c, params)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		godoc
+//
+//	@Summary		Get all tags.
+//	@Description	Get all tags.
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		200	{object}	entity.Response{data=[]entity.TagResponse{}}	"Data"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}				"Internal server error"
+//	@Router			/tags [get]
+//	@Security		Bearer
+func (handler *TagController) FindAll(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	data := handler.tagService.FindAll(c)
+
+	webResponse := entity.Response{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		    godoc
+//
+//	@Summary		Bulk tag customers
+//	@Description	Add every given tag to every given customer. Existing tags are kept.
+//	@Param			data	body	entity.BulkTagCustomerRequest	true	"bulk tag customers"
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/tags [post]
+//	@Security		Bearer
+func (handler *TagController) Tag(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.BulkTagCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	handler.tagService.Tag(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Tag Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+//	 Note		    godoc
+//
+//	@Summary		Bulk untag customers
+//	@Description	Remove every given tag from every given customer.
+//	@Param			data	body	entity.BulkTagCustomerRequest	true	"bulk untag customers"
+//	@Produce		application/json
+//	@Tags			tags
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/tags [delete]
+//	@Security		Bearer
+func (handler *TagController) Untag(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	request := entity.BulkTagCustomerRequest{}
+	err := ctx.ShouldBindJSON(&request)
+	helper.ErrorPanic(err)
+
+	handler.tagService.Untag(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Untag Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
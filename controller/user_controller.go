@@ -2,17 +2,15 @@ package controller
 
 import (
 	"context"
-	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"scylla/entity"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/tabular"
 	"scylla/pkg/utils"
 	"scylla/service"
-	"time"
 )
 
 type UserController struct {
@@ -39,7 +37,7 @@ func NewUserController(userService service.UserService) *UserController {
 //	@Router			/users [post]
 //	@Security		Bearer
 func (controller *UserController) Create(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.CreateUserRequest{}
@@ -65,16 +63,18 @@ func (controller *UserController) Create(ctx *gin.Context) {
 //	@Description	Update user.
 //	@Param			userId	path	string						true	"user_id"
 //	@Param			data	body	entity.UpdateUserRequest	true	"update user"
+//	@Param			If-Match	header	string					true	"ETag returned by get user"
 //	@Tags			users
 //	@Produce		application/json
 //	@Success		200	{object}	entity.JsonSuccess{data=nil}"Data"
 //	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
 //	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}		"Version mismatch"
 //	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
 //	@Router			/users/{userId} [patch]
 //	@Security		Bearer
 func (controller *UserController) Update(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.UpdateUserRequest{}
@@ -88,6 +88,7 @@ func (controller *UserController) Update(ctx *gin.Context) {
 	}
 
 	request.ID = params.UserId
+	request.Version = utils.ParseIfMatch(ctx)
 
 	controller.userService.Update(c, request)
 
@@ -98,6 +99,50 @@ func (controller *UserController) Update(ctx *gin.Context) {
 		Data:    nil,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, request.Version+1)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusOK, webResponse)
+}
+
+// Note		godoc
+//
+//	@Summary		Delete user
+//	@Description	Delete user.
+//	@Param			userId		path	string	true	"user_id"
+//	@Param			If-Match	header	string	true	"ETag returned by get user"
+//	@Produce		application/json
+//	@Tags			users
+//	@Success		200	{object}	entity.JsonSuccess{data=nil}"Data"
+//	@Failure		400	{object}	entity.JsonBadRequest{}				"Validation error"
+//	@Failure		404	{object}	entity.JsonNotFound{}				"Data not found"
+//	@Failure		412	{object}	entity.JsonPreconditionFailed{}		"Version mismatch"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/users/{userId} [delete]
+//	@Security		Bearer
+func (controller *UserController) Delete(ctx *gin.Context) {
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
+	defer cancel()
+
+	var params entity.UserParams
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	request := entity.DeleteUserRequest{
+		ID:      params.UserId,
+		Version: utils.ParseIfMatch(ctx),
+	}
+
+	controller.userService.Delete(c, request)
+
+	webResponse := entity.Response{
+		Code:    http.StatusOK,
+		Status:  "Ok",
+		Message: "Delete Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
 	ctx.Header("Content-Type", "application/json")
 	ctx.JSON(http.StatusOK, webResponse)
 }
@@ -116,7 +161,7 @@ func (controller *UserController) Update(ctx *gin.Context) {
 //	@Router			/users/batch [post]
 //	@Security		Bearer
 func (controller *UserController) DeleteBatch(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	request := entity.DeleteBatchUserRequest{}
@@ -150,7 +195,7 @@ func (controller *UserController) DeleteBatch(ctx *gin.Context) {
 //	@Router			/users/{userId} [get]
 //	@Security		Bearer
 func (controller *UserController) FindById(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	var params entity.UserParams
@@ -167,6 +212,7 @@ func (controller *UserController) FindById(ctx *gin.Context) {
 		Data:   response,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
+	utils.SetETag(ctx, response.Version)
 	ctx.Header("Content-Type", "application/json")
 	ctx.JSON(http.StatusOK, webResponse)
 }
@@ -188,7 +234,7 @@ func (controller *UserController) FindById(ctx *gin.Context) {
 //	@Router			/users [get]
 //	@Security		Bearer
 func (controller *UserController) FindAll(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout())
 	defer cancel()
 
 	var dataFilter entity.UserQueryFilter
@@ -211,23 +257,29 @@ func (controller *UserController) FindAll(ctx *gin.Context) {
 
 // Note 		    godoc
 //
-//	@Summary		Export Excel user.
-//	@Description	Export Excel user.
+//	@Summary		Export user.
+//	@Description	Start a job exporting user as xlsx, CSV, JSON or NDJSON, picked by the format parameter or else the Accept header. xlsx is the default. Poll the job until it has succeeded, then download the file from its result.
 //	@Produce		application/json
-//	@Accept			multipart/form-data
 //	@Tags			users
 //	@Param			start_date	query		string	false	"start_date"
 //	@Param			end_date	query		string	false	"end_date"
 //	@Param			username	query		string	false	"username"
 //	@Param			email		query		string	false	"email"
-//	@Success		200			{object}	entity.JsonSuccess{data=string}"Data"
-//	@Failure		400			{object}	entity.JsonBadRequest{}				"Validation error"
-//	@Failure		404			{object}	entity.JsonNotFound{}				"Data not found"
-//	@Failure		500			{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Param			format		query		string	false	"xlsx, csv, json or ndjson"
+//	@Param			delimiter	query		string	false	"CSV delimiter: a character or comma (default), semicolon, tab or pipe"
+//	@Param			encoding	query		string	false	"CSV encoding: utf-8 (default), utf-8-bom, utf-16le, windows-1252 or iso-8859-1"
+//	@Param			columns		query		string	false	"comma separated columns in order: id, username, email, created_at, updated_at; all by default"
+//	@Param			sheet		query		string	false	"xlsx worksheet name"
+//	@Param			timezone	query		string	false	"IANA time zone of timestamps, e.g. Asia/Jakarta; UTC by default"
+//	@Param			date_format	query		string	false	"CSV and xlsx date format built from yyyy, yy, mm, m, dd and d, e.g. dd/mm/yyyy; rejected for JSON and NDJSON"
+//	@Param			locale		query		string	false	"CSV date format and decimal separator, xlsx date format: en-US, en-GB, id-ID, de-DE, fr-FR, nl-NL or ja-JP; rejected for JSON and NDJSON"
+//	@Success		202			{object}	entity.JsonAccepted{data=entity.JobResponse{}}	"Job"
+//	@Failure		400			{object}	entity.JsonBadRequest{}							"Validation error"
+//	@Failure		500			{object}	entity.JsonInternalServerError{}				"Internal server error"
 //	@Router			/users/export [get]
 //	@Security		Bearer
 func (controller *UserController) Export(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
 	defer cancel()
 
 	var dataFilter entity.UserQueryFilter
@@ -236,56 +288,92 @@ func (controller *UserController) Export(ctx *gin.Context) {
 		panic(exception.NewBadRequestHandler(err.Error()))
 	}
 
-	filePath, err := controller.userService.Export(c, dataFilter)
-	helper.ErrorPanic(err)
-	defer os.Remove(filePath) // Remove the file after the function exits
+	exportRequest := entity.ExportRequest{}
+	if err := ctx.ShouldBindQuery(&exportRequest); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	options := utils.ExportOptions(ctx, exportRequest)
 
-	fileName := filepath.Base(filePath)
-	// Set headers for the Excel file
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	data := controller.userService.Export(c, dataFilter, options)
 
-	// Read the Excel file and write to the response body
-	data, err := os.ReadFile(filePath)
-	helper.ErrorPanic(err)
+	webResponse := entity.Response{
+		Code:    http.StatusAccepted,
+		Status:  "Accepted",
+		Message: "Export Queued",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(ctx, &webResponse)
+	ctx.Header("Content-Type", "application/json")
+	ctx.JSON(http.StatusAccepted, webResponse)
+}
 
-	// Write data to the response body
-	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
+// Note 		    godoc
+//
+//	@Summary		Download the user import template.
+//	@Description	Excel workbook with the import columns, an example row, dropdowns for enumerated columns and a Notes sheet describing each column's rules.
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Tags			users
+//	@Success		200	{file}		binary								"File"
+//	@Failure		500	{object}	entity.JsonInternalServerError{}	"Internal server error"
+//	@Router			/users/import/template [get]
+//	@Security		Bearer
+func (controller *UserController) ImportTemplate(ctx *gin.Context) {
+	// The workbook is written straight to the client, for as long as the
+	// client takes to read it.
+	utils.LiftDeadlines(ctx)
+	c := ctx.Request.Context()
+
+	options := tabular.Options{Format: tabular.FormatXLSX}
+	utils.StreamExport(ctx, options, "user_import_template", func(w io.Writer) error {
+		return controller.userService.ImportTemplate(c, w)
+	})
 }
 
 //	 Note 		    godoc
 //
-//	@Summary		Import Excel user.
-//	@Description	Import Excel user.
+//	@Summary		Import user.
+//	@Description	Start a job importing user from an xlsx, CSV, JSON or NDJSON file, detected from its content. Columns are found by header name, ignoring case, spaces and punctuation, or by a saved profile and the mapping. mode=partial writes the valid rows even when others are invalid, mode=atomic writes every row or none and mode=dry_run writes nothing. The job's result holds an entity.ImportUserResponse, or an entity.ImportPreviewResponse for dry runs. When rows are rejected the job fails and its error links to the file annotated with the errors in error_file.
 //	@Produce		application/json
 //	@Tags			users
-//	@Param			data	formData	file	true	"import Excel user"
-//	@Success		200		{object}	entity.JsonSuccess{data=string}"Data"
+//	@Param			data	formData	file	true	"xlsx, CSV, JSON or NDJSON file of users"
+//	@Param			mode	formData	string	false	"partial (default), atomic or dry_run"
+//	@Param			profile	formData	string	false	"name of a saved users import profile"
+//	@Param			mapping	formData	string	false	"JSON object from file header to column key, overriding the profile"
+//	@Param			delimiter	formData	string	false	"CSV delimiter, guessed from the header line by default"
+//	@Param			encoding	formData	string	false	"CSV encoding when the file has no byte order mark: utf-8 (default), utf-16le, windows-1252 or iso-8859-1"
+//	@Success		202		{object}	entity.JsonAccepted{data=entity.JobResponse{}}	"Job"
 //	@Failure		400		{object}	entity.JsonBadRequest{}				"Validation error"
 //	@Failure		404		{object}	entity.JsonNotFound{}				"Data not found"
 //	@Failure		500		{object}	entity.JsonInternalServerError{}	"Internal server error"
 //	@Router			/users/import [post]
 //	@Security		Bearer
 func (controller *UserController) Import(ctx *gin.Context) {
-	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Large files take longer to upload than the server timeouts allow.
+	utils.LiftDeadlines(ctx)
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
 		panic(exception.NewBadRequestHandler(err.Error()))
 	}
 
-	// Call the usersService method to handle the import
-	err = controller.userService.Import(c, file)
-	helper.ErrorPanic(err)
+	request := entity.ImportUserRequest{}
+	if err := ctx.ShouldBind(&request); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	// The upload has been read, so the timeout only bounds queueing the job.
+	c, cancel := context.WithTimeout(utils.WithActor(context.Background(), ctx), utils.RequestTimeout())
+	defer cancel()
+
+	data := controller.userService.Import(c, file, request)
 
 	webResponse := entity.Response{
-		Code:    http.StatusOK,
-		Status:  "Ok",
-		Message: "Import Successful",
-		Data:    nil,
+		Code:    http.StatusAccepted,
+		Status:  "Accepted",
+		Message: "Import Queued",
+		Data:    data,
 	}
 	utils.ResponseInterceptor(ctx, &webResponse)
 	ctx.Header("Content-Type", "application/json")
-	ctx.JSON(http.StatusOK, webResponse)
+	ctx.JSON(http.StatusAccepted, webResponse)
 }
@@ -27,13 +27,19 @@ type CreateUserRequest struct {
 
 type UpdateUserRequest struct {
 	ID       int    `json:"username" validate:"required"`
+	Version  int    `json:"-"        validate:"required"`
 	Username string `json:"username" validate:"required,max=200,min=2"`
 	Email    string `json:"email"    validate:"required,email,unique=users;email;id"`
 	Password string `json:"password" validate:"required,min=8,max=100"`
 }
 
 type UserParams struct {
-	UserId int `params:"userId" validate:"required"`
+	UserId int `uri:"userId" validate:"required"`
+}
+
+type DeleteUserRequest struct {
+	ID      int `json:"id" validate:"required"`
+	Version int `json:"-" validate:"required"`
 }
 
 type DeleteBatchUserRequest struct {
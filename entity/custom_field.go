@@ -0,0 +1,32 @@
+package entity
+
+type CustomFieldResponse struct {
+	ID        int      `json:"id"`
+	Key       string   `json:"key"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type CreateCustomFieldRequest struct {
+	Key      string   `json:"key" validate:"required,max=64,fieldKey,unique=custom_field_definitions;key"`
+	Label    string   `json:"label" validate:"required,max=125"`
+	Type     string   `json:"type" validate:"required,oneof=string number boolean date enum"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options" validate:"required_if=Type enum,dive,required"`
+}
+
+// The key and type of a field cannot change once customers may hold values
+// for it; create a new field instead.
+type UpdateCustomFieldRequest struct {
+	ID       int      `json:"-" validate:"required"`
+	Label    string   `json:"label" validate:"required,max=125"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options" validate:"dive,required"`
+}
+
+type CustomFieldParams struct {
+	CustomFieldId int `uri:"customFieldId" validate:"required"`
+}
@@ -1,31 +1,73 @@
 package entity
 
 type CustomerResponse struct {
-	ID        int    `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
-	Address   string `json:"address"`
-	CreatedAt string `json:"created_at"`
+	ID        int      `json:"id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone"`
+	Address   string   `json:"address"`
+	Version   int      `json:"version"`
+	Status    string   `json:"status"`
+	OwnerID   *int     `json:"owner_id"`
+	Tags      []string `json:"tags" gorm:"-"`
+	CreatedAt string   `json:"created_at"`
+
+	CustomFields     map[string]interface{} `json:"custom_fields" gorm:"serializer:json"`
+	StatusTimestamps map[string]string      `json:"status_timestamps" gorm:"serializer:json"`
 }
 
+// What batch create and import do with a row whose email already exists.
+const (
+	OnConflictError  = "error"
+	OnConflictSkip   = "skip"
+	OnConflictUpdate = "update"
+)
+
 type CreateCustomerBatchRequest struct {
-	Customers []CreateCustomerRequest `json:"customers" validate:"required,dive"`
+	Customers  []CreateCustomerRequest `json:"customers" validate:"required,dive"`
+	OnConflict string                  `json:"on_conflict" validate:"omitempty,oneof=error skip update"`
+}
+
+type ImportCustomerRequest struct {
+	OnConflict string `form:"on_conflict" validate:"omitempty,oneof=error skip update"`
+	Mode       string `form:"mode" validate:"omitempty,oneof=partial atomic dry_run"`
+	Profile    string `form:"profile" validate:"omitempty,max=125"`
+	Mapping    string `form:"mapping"`
+	Delimiter  string `form:"delimiter"`
+	Encoding   string `form:"encoding"`
+}
+
+type UpsertCustomerResponse struct {
+	Inserted    int   `json:"inserted"`
+	Updated     int   `json:"updated"`
+	Skipped     int   `json:"skipped"`
+	InsertedIDs []int `json:"inserted_ids"`
+	UpdatedIDs  []int `json:"updated_ids"`
+	SkippedIDs  []int `json:"skipped_ids"`
 }
 
 type CreateCustomerRequest struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,unique=customers;email"`
-	Phone    string `json:"phone" validate:"required"`
-	Address  string `json:"address" validate:"required"`
+	Username     string                 `json:"username" validate:"required"`
+	Email        string                 `json:"email" validate:"required,email,unique=customers;lower(email)"`
+	Phone        string                 `json:"phone" validate:"required,phone"`
+	Address      string                 `json:"address" validate:"required"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
 }
 
 type UpdateCustomerRequest struct {
 	ID       int    `json:"id" validate:"required"`
+	Version  int    `json:"-" validate:"required"`
 	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,unique=customers;email;id"`
-	Phone    string `json:"phone" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Phone    string `json:"phone" validate:"required,phone"`
 	Address  string `json:"address" validate:"required"`
+
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
+type DeleteCustomerRequest struct {
+	ID      int `json:"id" validate:"required"`
+	Version int `json:"-" validate:"required"`
 }
 
 type DeleteBatchCustomerRequest struct {
@@ -33,7 +75,7 @@ type DeleteBatchCustomerRequest struct {
 }
 
 type CustomerParams struct {
-	CustomerId int `params:"customerId" validate:"required"`
+	CustomerId int `uri:"customerId" validate:"required"`
 }
 
 type CustomerQueryFilter struct {
@@ -43,5 +85,123 @@ type CustomerQueryFilter struct {
 	EndDate   string `form:"end_date"`
 	Username  string `form:"username"`
 	Email     string `form:"email"`
+	Tag       string `form:"tag"`
+	Status    string `form:"status"`
+	OwnerID   int    `form:"owner_id"`
+	Mine      bool   `form:"mine"`
 	Sort      string `form:"sort"`
+
+	// CustomFields is read from custom_fields[key]=value query parameters.
+	CustomFields map[string]string `form:"-"`
+}
+
+type CustomerDuplicateQueryFilter struct {
+	MinScore float64 `form:"min_score"`
+	Limit    int     `form:"limit"`
+	Page     int     `form:"page"`
+}
+
+// Score weighs an exact normalized email match at 0.5, an exact phone match
+// (digits only) at 0.3 and the trigram similarity of the names at up to 0.2.
+type CustomerDuplicateResponse struct {
+	CustomerID        int     `json:"customer_id"`
+	CustomerUsername  string  `json:"customer_username"`
+	CustomerEmail     string  `json:"customer_email"`
+	CandidateID       int     `json:"candidate_id"`
+	CandidateUsername string  `json:"candidate_username"`
+	CandidateEmail    string  `json:"candidate_email"`
+	EmailMatch        bool    `json:"email_match"`
+	PhoneMatch        bool    `json:"phone_match"`
+	NameSimilarity    float64 `json:"name_similarity"`
+	Score             float64 `json:"score"`
+}
+
+// Fields picks, per field, the customer whose value the survivor keeps,
+// e.g. {"phone": 12}. Fields left out keep the survivor's value, or the first
+// non-empty value among the duplicates when the survivor's is empty.
+type MergeCustomerRequest struct {
+	SurvivorID   int            `json:"survivor_id" validate:"required"`
+	DuplicateIDs []int          `json:"duplicate_ids" validate:"required,notEmptyIntSlice"`
+	Fields       map[string]int `json:"fields" validate:"dive,keys,oneof=username email phone address,endkeys,required"`
+	Version      int            `json:"-" validate:"required"`
+}
+
+// UpdateBatchCustomerRequest takes either Items, each with its own changes,
+// or a Filter (a customer query string such as tag=vip) with one Changes set
+// applied to every match. With Atomic set a single failure rolls back the
+// whole batch; otherwise the failed items are skipped and the rest applied.
+type UpdateBatchCustomerRequest struct {
+	Items   []UpdateBatchCustomerItem `json:"items" validate:"omitempty,max=1000,dive"`
+	Filter  string                    `json:"filter" validate:"omitempty,customerFilter"`
+	Changes *CustomerChanges          `json:"changes" validate:"required_with=Filter"`
+	Atomic  bool                      `json:"atomic"`
+}
+
+// Version is optional; when set the item fails unless the customer is still
+// at that version.
+type UpdateBatchCustomerItem struct {
+	ID      int             `json:"id" validate:"required"`
+	Version int             `json:"version"`
+	Changes CustomerChanges `json:"changes" validate:"required"`
+}
+
+// CustomerChanges holds the fields to overwrite; omitted fields are kept.
+// CustomFields are merged into the customer's existing values.
+type CustomerChanges struct {
+	Username     *string                `json:"username" validate:"omitempty,min=1"`
+	Email        *string                `json:"email" validate:"omitempty,email"`
+	Phone        *string                `json:"phone" validate:"omitempty,phone"`
+	Address      *string                `json:"address" validate:"omitempty,min=1"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
+type UpdateBatchCustomerResponse struct {
+	Applied bool                        `json:"applied"`
+	Updated int                         `json:"updated"`
+	Failed  int                         `json:"failed"`
+	Results []UpdateBatchCustomerResult `json:"results"`
+}
+
+// Status is updated, failed, or rolled_back for items that were valid but
+// undone because another item failed in atomic mode.
+type UpdateBatchCustomerResult struct {
+	ID      int    `json:"id"`
+	Status  string `json:"status"`
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type TransitionCustomerRequest struct {
+	CustomerID int    `json:"-" validate:"required"`
+	Version    int    `json:"-" validate:"required"`
+	Status     string `json:"status" validate:"required"`
+	Reason     string `json:"reason" validate:"max=500"`
+}
+
+// CustomerStatusChanged is the payload of the customer.status_changed event.
+type CustomerStatusChanged struct {
+	CustomerID int    `json:"customer_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Reason     string `json:"reason"`
+	Actor      string `json:"actor"`
+}
+
+// OwnerID is the user who manages the customer; null unassigns it.
+type AssignCustomerOwnerRequest struct {
+	CustomerID int  `json:"-" validate:"required"`
+	OwnerID    *int `json:"owner_id"`
+	Version    int  `json:"-" validate:"required"`
+}
+
+// ReassignCustomersRequest moves every customer of FromOwnerID, e.g. a user
+// who is leaving, to ToOwnerID, or leaves them unassigned when it is null.
+type ReassignCustomersRequest struct {
+	FromOwnerID int  `json:"from_owner_id" validate:"required"`
+	ToOwnerID   *int `json:"to_owner_id"`
+}
+
+type ReassignCustomersResponse struct {
+	Reassigned  int   `json:"reassigned"`
+	CustomerIDs []int `json:"customer_ids"`
 }
@@ -0,0 +1,65 @@
+package entity
+
+type CustomerActivityResponse struct {
+	ID         int    `json:"id"`
+	CustomerID int    `json:"customer_id"`
+	Type       string `json:"type"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	Author     string `json:"author"`
+	DueAt      string `json:"due_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// DueAt marks the activity as a follow-up, in RFC 3339 such as
+// 2026-10-20T09:00:00+07:00.
+type CreateCustomerActivityRequest struct {
+	CustomerID int    `json:"-" validate:"required"`
+	Type       string `json:"type" validate:"required,oneof=note call email meeting"`
+	Subject    string `json:"subject" validate:"max=255"`
+	Body       string `json:"body" validate:"required"`
+	DueAt      string `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type UpdateCustomerActivityRequest struct {
+	ID         int    `json:"-" validate:"required"`
+	CustomerID int    `json:"-" validate:"required"`
+	Type       string `json:"type" validate:"required,oneof=note call email meeting"`
+	Subject    string `json:"subject" validate:"max=255"`
+	Body       string `json:"body" validate:"required"`
+	DueAt      string `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type CustomerActivityParams struct {
+	CustomerId int `uri:"customerId" validate:"required"`
+	ActivityId int `uri:"activityId" validate:"required"`
+}
+
+// DueBefore keeps the follow-ups due before the given date (yyyy-mm-dd).
+type CustomerActivityQueryFilter struct {
+	Type      string `form:"type"`
+	DueBefore string `form:"due_before" validate:"omitempty,date"`
+	Limit     int    `form:"limit"`
+	Page      int    `form:"page"`
+}
+
+// What a timeline entry holds.
+const (
+	TimelineActivity = "activity"
+	TimelineChange   = "change"
+)
+
+// CustomerTimelineResponse is one entry of the combined timeline: either an
+// activity or a change from the customer's history, as told by Kind.
+type CustomerTimelineResponse struct {
+	Kind       string                    `json:"kind"`
+	OccurredAt string                    `json:"occurred_at"`
+	Activity   *CustomerActivityResponse `json:"activity,omitempty"`
+	Change     *CustomerRevisionResponse `json:"change,omitempty"`
+}
+
+type CustomerTimelineQueryFilter struct {
+	Limit int `form:"limit"`
+	Page  int `form:"page"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+type CustomerAddressResponse struct {
+	ID         int    `json:"id"`
+	CustomerID int    `json:"customer_id"`
+	Type       string `json:"type"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	IsDefault  bool   `json:"is_default"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type CreateCustomerAddressRequest struct {
+	CustomerID int    `json:"-" validate:"required"`
+	Type       string `json:"type" validate:"required,oneof=billing shipping other"`
+	Line1      string `json:"line1" validate:"required,max=255"`
+	Line2      string `json:"line2" validate:"max=255"`
+	City       string `json:"city" validate:"required,max=125"`
+	Region     string `json:"region" validate:"max=125"`
+	PostalCode string `json:"postal_code" validate:"omitempty,postcode_iso3166_alpha2_field=Country"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type UpdateCustomerAddressRequest struct {
+	ID         int    `json:"-" validate:"required"`
+	CustomerID int    `json:"-" validate:"required"`
+	Type       string `json:"type" validate:"required,oneof=billing shipping other"`
+	Line1      string `json:"line1" validate:"required,max=255"`
+	Line2      string `json:"line2" validate:"max=255"`
+	City       string `json:"city" validate:"required,max=125"`
+	Region     string `json:"region" validate:"max=125"`
+	PostalCode string `json:"postal_code" validate:"omitempty,postcode_iso3166_alpha2_field=Country"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type CustomerAddressParams struct {
+	CustomerId int `uri:"customerId" validate:"required"`
+	AddressId  int `uri:"addressId" validate:"required"`
+}
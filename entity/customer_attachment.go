@@ -0,0 +1,29 @@
+package entity
+
+type CustomerAttachmentResponse struct {
+	ID          int    `json:"id"`
+	CustomerID  int    `json:"customer_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	UploadedBy  string `json:"uploaded_by"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type CustomerAttachmentParams struct {
+	CustomerId   int `uri:"customerId" validate:"required"`
+	AttachmentId int `uri:"attachmentId" validate:"required"`
+}
+
+type AttachmentURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// DownloadAttachmentRequest is read from a signed download link; Expires is
+// a unix time.
+type DownloadAttachmentRequest struct {
+	AttachmentId int    `uri:"attachmentId" validate:"required"`
+	Expires      int64  `form:"expires" validate:"required"`
+	Signature    string `form:"signature" validate:"required,hexadecimal"`
+}
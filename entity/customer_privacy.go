@@ -0,0 +1,27 @@
+package entity
+
+// PersonalDataResponse bundles everything stored about one customer for a
+// data subject access request.
+type PersonalDataResponse struct {
+	Customer    CustomerResponse             `json:"customer"`
+	Addresses   []CustomerAddressResponse    `json:"addresses"`
+	History     []CustomerRevisionResponse   `json:"history"`
+	Activities  []CustomerActivityResponse   `json:"activities"`
+	Attachments []CustomerAttachmentResponse `json:"attachments"`
+	Erasures    []CustomerErasureResponse    `json:"erasures"`
+	ExportedAt  string                       `json:"exported_at"`
+}
+
+type CustomerErasureResponse struct {
+	ID           int      `json:"id"`
+	CustomerID   int      `json:"customer_id"`
+	Actor        string   `json:"actor"`
+	Reason       string   `json:"reason"`
+	ErasedFields []string `json:"erased_fields"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+type EraseCustomerRequest struct {
+	CustomerID int    `json:"-" validate:"required"`
+	Reason     string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type CustomerRevisionResponse struct {
+	ID         int                    `json:"id"`
+	CustomerID int                    `json:"customer_id"`
+	Action     string                 `json:"action"`
+	Actor      string                 `json:"actor"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  string                 `json:"created_at"`
+}
+
+type CustomerHistoryQueryFilter struct {
+	Limit int `form:"limit"`
+	Page  int `form:"page"`
+}
+
+type RevertCustomerRequest struct {
+	CustomerId int `uri:"customerId" validate:"required"`
+	RevisionId int `uri:"revisionId" validate:"required"`
+	Version    int `json:"-" validate:"required"`
+}
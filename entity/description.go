@@ -23,6 +23,14 @@ type JsonCreated struct {
 	TraceID string      `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
 }
 
+type JsonAccepted struct {
+	Code    int         `json:"code" example:"202"`
+	Status  string      `json:"status" example:"ACCEPTED"`
+	Message string      `json:"message,omitempty" example:"Accepted"`
+	Data    interface{} `json:"data,omitempty"`
+	TraceID string      `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
 type JsonInternalServerError struct {
 	Code    int    `json:"code" example:"500"`
 	Status  string `json:"status" example:"INTERNAL SERVER ERROR"`
@@ -36,3 +44,24 @@ type JsonNotFound struct {
 	Errors  string `json:"errors,omitempty" example:"record not found"`
 	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
 }
+
+type JsonConflict struct {
+	Code    int    `json:"code" example:"409"`
+	Status  string `json:"status" example:"CONFLICT"`
+	Errors  string `json:"errors,omitempty" example:"transition is not allowed"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
+type JsonPreconditionFailed struct {
+	Code    int    `json:"code" example:"412"`
+	Status  string `json:"status" example:"PRECONDITION FAILED"`
+	Errors  string `json:"errors,omitempty" example:"resource has been modified"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
+type JsonUnauthorized struct {
+	Code    int    `json:"code" example:"401"`
+	Status  string `json:"status" example:"UNAUTHORIZED"`
+	Errors  string `json:"errors,omitempty" example:"download link has expired"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
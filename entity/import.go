@@ -0,0 +1,75 @@
+package entity
+
+// How an import writes the valid rows of a file that also has invalid ones.
+// Partial writes the valid rows, atomic writes all rows in one transaction
+// or none of them and dry run only reports what would be written.
+const (
+	ImportModePartial = "partial"
+	ImportModeAtomic  = "atomic"
+	ImportModeDryRun  = "dry_run"
+)
+
+// What an import would do with a row.
+const (
+	ImportActionInsert = "insert"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"
+	ImportActionReject = "reject"
+)
+
+// Profile names a saved import profile and Mapping is a JSON object from
+// file header to column key that overrides it. Delimiter and Encoding are
+// read by CSV files; the delimiter is guessed when left empty.
+type ImportUserRequest struct {
+	Mode      string `form:"mode" validate:"omitempty,oneof=partial atomic dry_run"`
+	Profile   string `form:"profile" validate:"omitempty,max=125"`
+	Mapping   string `form:"mapping"`
+	Delimiter string `form:"delimiter"`
+	Encoding  string `form:"encoding"`
+}
+
+// ExportRequest picks the format of an export; without Format the Accept
+// header decides. Delimiter and Encoding apply to CSV. Columns is a comma
+// separated list of column keys in the order to write them, Timezone an
+// IANA name for timestamps and DateFormat and Locale lay out dates and
+// decimals of CSV files.
+type ExportRequest struct {
+	Format     string `form:"format"`
+	Delimiter  string `form:"delimiter"`
+	Encoding   string `form:"encoding"`
+	Columns    string `form:"columns"`
+	Sheet      string `form:"sheet"`
+	Timezone   string `form:"timezone"`
+	DateFormat string `form:"date_format"`
+	Locale     string `form:"locale"`
+}
+
+// ImportUserResponse is the result payload of a user import.
+type ImportUserResponse struct {
+	Inserted int `json:"inserted"`
+}
+
+type ImportPreviewRow struct {
+	Row    int      `json:"row"`
+	Action string   `json:"action"`
+	Email  string   `json:"email"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportPreviewResponse struct {
+	Inserted int                `json:"inserted"`
+	Updated  int                `json:"updated"`
+	Skipped  int                `json:"skipped"`
+	Rejected int                `json:"rejected"`
+	Rows     []ImportPreviewRow `json:"rows"`
+	// ErrorFile links to the uploaded file annotated with the errors.
+	ErrorFile string `json:"error_file,omitempty"`
+}
+
+// DownloadImportErrorsRequest is read from the signed link to the error
+// workbook of a failed import; Expires is a unix time.
+type DownloadImportErrorsRequest struct {
+	ErrorFileId string `uri:"errorFileId" validate:"required,uuid"`
+	Expires     int64  `form:"expires" validate:"required"`
+	Signature   string `form:"signature" validate:"required,hexadecimal"`
+}
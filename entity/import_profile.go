@@ -0,0 +1,32 @@
+package entity
+
+type ImportProfileResponse struct {
+	ID        int               `json:"id"`
+	Resource  string            `json:"resource"`
+	Name      string            `json:"name"`
+	Mapping   map[string]string `json:"mapping"`
+	CreatedBy string            `json:"created_by"`
+	CreatedAt string            `json:"created_at"`
+}
+
+// Mapping goes from a header in the partner's file to the key of the column
+// it holds; an empty key ignores the header.
+type CreateImportProfileRequest struct {
+	Resource string            `json:"resource" validate:"required,oneof=customers users"`
+	Name     string            `json:"name" validate:"required,max=125"`
+	Mapping  map[string]string `json:"mapping" validate:"required"`
+}
+
+type UpdateImportProfileRequest struct {
+	ID      int               `json:"-" validate:"required"`
+	Name    string            `json:"name" validate:"required,max=125"`
+	Mapping map[string]string `json:"mapping" validate:"required"`
+}
+
+type ImportProfileParams struct {
+	ImportProfileId int `uri:"importProfileId" validate:"required"`
+}
+
+type ImportProfileQueryFilter struct {
+	Resource string `form:"resource" validate:"omitempty,oneof=customers users"`
+}
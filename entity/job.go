@@ -0,0 +1,40 @@
+package entity
+
+import "encoding/json"
+
+// JobResponse is the state of a background import or export. Progress is a
+// percentage. Result holds what the work reported, such as the counts of an
+// import, and Error why it failed; an export that succeeded is downloaded
+// from the job's result endpoint as FileName.
+type JobResponse struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Resource    string          `json:"resource"`
+	State       string          `json:"state"`
+	Progress    int             `json:"progress"`
+	Result      json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error       json.RawMessage `json:"error,omitempty" swaggertype:"object"`
+	FileName    string          `json:"file_name,omitempty"`
+	ContentType string          `json:"content_type,omitempty"`
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   string          `json:"created_at"`
+	StartedAt   string          `json:"started_at,omitempty"`
+	FinishedAt  string          `json:"finished_at,omitempty"`
+}
+
+// JobError is the error payload of a failed job. Imports with invalid rows
+// also list the errors by column and link to the file annotated with them.
+type JobError struct {
+	Message   string      `json:"message"`
+	Errors    interface{} `json:"errors,omitempty"`
+	ErrorFile string      `json:"error_file,omitempty"`
+}
+
+type JobParams struct {
+	JobId string `uri:"jobId" validate:"required,uuid"`
+}
+
+// ExportResponse is the result payload of an export job.
+type ExportResponse struct {
+	Rows int `json:"rows"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+// RetentionReportResponse is the dry run of one policy: what the next run
+// would purge.
+type RetentionReportResponse struct {
+	Policy string `json:"policy"`
+	Target string `json:"target"`
+	After  string `json:"after"`
+	Due    int64  `json:"due"`
+	Oldest string `json:"oldest,omitempty"`
+}
+
+type RetentionLogResponse struct {
+	ID         int    `json:"id"`
+	Policy     string `json:"policy"`
+	Target     string `json:"target"`
+	Affected   int64  `json:"affected"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+type RetentionLogQueryFilter struct {
+	Policy string `form:"policy"`
+	Limit  int    `form:"limit"`
+	Page   int    `form:"page"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+type SegmentResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Filter      string `json:"filter"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Filter is a query string using the same keys as GET /customers, e.g.
+// "tag=vip,region-east&start_date=2024-01-01&end_date=2024-12-31".
+type CreateSegmentRequest struct {
+	Name        string `json:"name" validate:"required,max=125,unique=segments;name"`
+	Description string `json:"description" validate:"max=255"`
+	Filter      string `json:"filter" validate:"required,customerFilter"`
+}
+
+type UpdateSegmentRequest struct {
+	ID          int    `json:"-" validate:"required"`
+	Name        string `json:"name" validate:"required,max=125,unique=segments;name;id"`
+	Description string `json:"description" validate:"max=255"`
+	Filter      string `json:"filter" validate:"required,customerFilter"`
+}
+
+type SegmentParams struct {
+	SegmentId int `uri:"segmentId" validate:"required"`
+}
+
+type SegmentQueryFilter struct {
+	Limit int `form:"limit"`
+	Page  int `form:"page"`
+}
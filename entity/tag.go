@@ -0,0 +1,25 @@
+package entity
+
+type TagResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required,max=125,unique=tags;name"`
+}
+
+type UpdateTagRequest struct {
+	ID   int    `json:"-" validate:"required"`
+	Name string `json:"name" validate:"required,max=125,unique=tags;name;id"`
+}
+
+type TagParams struct {
+	TagId int `uri:"tagId" validate:"required"`
+}
+
+type BulkTagCustomerRequest struct {
+	CustomerIDs []int `json:"customer_ids" validate:"required,notEmptyIntSlice"`
+	TagIDs      []int `json:"tag_ids" validate:"required,notEmptyIntSlice"`
+}
@@ -4,5 +4,6 @@ type UserResponse struct {
 	ID        int    `json:"id"`
 	Username  string `json:"username"`
 	Email     string `json:"email"`
+	Version   int    `json:"version"`
 	CreatedAt string `json:"created_at"`
 }
@@ -12,10 +12,11 @@ type Response struct {
 }
 
 type Error struct {
-	Code    int         `json:"code"`
-	Status  string      `json:"status"`
-	Errors  interface{} `json:"errors,omitempty"`
-	TraceID string      `json:"trace_id"`
+	Code      int         `json:"code"`
+	Status    string      `json:"status"`
+	Errors    interface{} `json:"errors,omitempty"`
+	ErrorFile string      `json:"error_file,omitempty"`
+	TraceID   string      `json:"trace_id"`
 }
 
 type Paging struct {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -9,8 +10,10 @@ import (
 	"scylla/controller"
 	"scylla/docs"
 	"scylla/pkg/config"
+	"scylla/pkg/event"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/storage"
 	"scylla/pkg/utils"
 	"scylla/repository"
 	"scylla/routes"
@@ -39,6 +42,33 @@ func main() {
 	//Validate
 	validate := utils.InitializeValidator(db)
 
+	//Customer lifecycle
+	customerLifecycle, err := config.ParseCustomerLifecycle(loadConfig.CustomerTransitions)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	//Phone numbers
+	if loadConfig.PhoneRegion != "" {
+		if err := helper.SetPhoneRegion(loadConfig.PhoneRegion); err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	}
+
+	//Timeouts
+	utils.SetRequestTimeout(loadConfig.RequestTimeout)
+	readTimeout, writeTimeout := loadConfig.ServerReadTimeout, loadConfig.ServerWriteTimeout
+	if readTimeout <= 0 {
+		readTimeout = config.DefaultServerReadTimeout
+	}
+	if writeTimeout <= 0 {
+		writeTimeout = config.DefaultServerWriteTimeout
+	}
+
+	//Events
+	eventBus := event.NewBusImpl()
+	eventBus.Subscribe(event.CustomerStatusChanged, event.LogHandler)
+
 	//Swagger
 	if loadConfig.Environment != "dev" {
 		docs.SwaggerInfo.Host = loadConfig.SwaggerHost
@@ -48,25 +78,121 @@ func main() {
 		docs.SwaggerInfo.BasePath = "/api/v1"
 	}
 
+	//Storage
+	var fileStorage storage.Storage
+	switch loadConfig.StorageDriver {
+	case config.StorageS3:
+		fileStorage, err = storage.NewS3Storage(storage.S3Options{
+			Endpoint:  loadConfig.S3Endpoint,
+			Region:    loadConfig.S3Region,
+			Bucket:    loadConfig.S3Bucket,
+			AccessKey: loadConfig.S3AccessKey,
+			SecretKey: loadConfig.S3SecretKey,
+			PathStyle: loadConfig.S3PathStyle,
+		})
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	case config.StorageLocal, "":
+		storageDir := loadConfig.StorageDir
+		if storageDir == "" {
+			storageDir = config.DefaultStorageDir
+		}
+		fileStorage = storage.NewLocalStorage(storageDir)
+	default:
+		panic(exception.NewInternalServerErrorHandler("unknown STORAGE_DRIVER " + loadConfig.StorageDriver))
+	}
+
+	//Signed links
+	if loadConfig.URLSigningKey == "" {
+		panic(exception.NewInternalServerErrorHandler("URL_SIGNING_KEY is not set"))
+	}
+
+	importOptions := config.ImportOptions{
+		Workers:    loadConfig.ImportWorkers,
+		BatchSize:  loadConfig.ImportBatchSize,
+		ErrorTTL:   loadConfig.ImportErrorTTL,
+		SigningKey: loadConfig.URLSigningKey,
+		PublicURL:  loadConfig.PublicURL,
+	}
+
+	attachmentOptions := config.AttachmentOptions{
+		MaxSize:    loadConfig.AttachmentMaxSize,
+		URLTTL:     loadConfig.SignedURLTTL,
+		SigningKey: loadConfig.URLSigningKey,
+		PublicURL:  loadConfig.PublicURL,
+	}
+
 	//Init Repository
 	userRepo := repository.NewUserRepoImpl(db)
 	passResetRepo := repository.NewPassResetRepoImpl(db)
 	customerRepo := repository.NewCustomerRepoImpl(db)
+	customerRevisionRepo := repository.NewCustomerRevisionRepoImpl(db)
+	customerAddressRepo := repository.NewCustomerAddressRepoImpl(db)
+	customerErasureRepo := repository.NewCustomerErasureRepoImpl(db)
+	customerActivityRepo := repository.NewCustomerActivityRepoImpl(db)
+	customerAttachmentRepo := repository.NewCustomerAttachmentRepoImpl(db)
+	tagRepo := repository.NewTagRepoImpl(db)
+	segmentRepo := repository.NewSegmentRepoImpl(db)
+	customFieldRepo := repository.NewCustomFieldRepoImpl(db)
+	importProfileRepo := repository.NewImportProfileRepoImpl(db)
+	retentionRepo := repository.NewRetentionRepoImpl(db)
+	jobRepo := repository.NewJobRepoImpl(db)
+	transactor := repository.NewTransactorImpl(db)
 
 	//Init Service
+	jobService := service.NewJobServiceImpl(jobRepo, fileStorage, loadConfig.JobWorkers, validate)
 	authService := service.NewAuthServiceImpl(userRepo, passResetRepo, validate)
-	customerService := service.NewCustomerServiceImpl(customerRepo, validate)
-	userSevice := service.NewUserServiceImpl(userRepo, validate)
+	customerService := service.NewCustomerServiceImpl(customerRepo, customerRevisionRepo, transactor, tagRepo, customFieldRepo, userRepo, importProfileRepo, customerLifecycle, eventBus, fileStorage, jobService, importOptions, validate)
+	customerAddressService := service.NewCustomerAddressServiceImpl(customerAddressRepo, customerRepo, validate)
+	customerPrivacyService := service.NewCustomerPrivacyServiceImpl(customerRepo, customerAddressRepo, customerRevisionRepo, tagRepo, customerActivityRepo, customerAttachmentRepo, customerErasureRepo, fileStorage, validate)
+	customerActivityService := service.NewCustomerActivityServiceImpl(customerActivityRepo, customerRepo, customerRevisionRepo, validate)
+	customerAttachmentService := service.NewCustomerAttachmentServiceImpl(customerAttachmentRepo, customerRepo, fileStorage, attachmentOptions, validate)
+	tagService := service.NewTagServiceImpl(tagRepo, customerRepo, validate)
+	segmentService := service.NewSegmentServiceImpl(segmentRepo, customerService, validate)
+	customFieldService := service.NewCustomFieldServiceImpl(customFieldRepo, validate)
+	importProfileService := service.NewImportProfileServiceImpl(importProfileRepo, customFieldRepo, validate)
+	importErrorService := service.NewImportErrorServiceImpl(fileStorage, importOptions, validate)
+	retentionService := service.NewRetentionServiceImpl(retentionRepo, fileStorage, config.RetentionPolicies(loadConfig.ImportErrorTTL))
+	userSevice := service.NewUserServiceImpl(userRepo, importProfileRepo, fileStorage, jobService, importOptions, validate)
 
 	//Init controller
 	authController := controller.NewAuthController(authService)
 	customerController := controller.NewCustomerController(customerService)
+	customerAddressController := controller.NewCustomerAddressController(customerAddressService)
+	customerPrivacyController := controller.NewCustomerPrivacyController(customerPrivacyService)
+	customerActivityController := controller.NewCustomerActivityController(customerActivityService)
+	customerAttachmentController := controller.NewCustomerAttachmentController(customerAttachmentService, attachmentOptions.MaxSize)
+	tagController := controller.NewTagController(tagService)
+	segmentController := controller.NewSegmentController(segmentService)
+	customFieldController := controller.NewCustomFieldController(customFieldService)
+	importProfileController := controller.NewImportProfileController(importProfileService)
+	importErrorController := controller.NewImportErrorController(importErrorService)
+	retentionController := controller.NewRetentionController(retentionService)
+	jobController := controller.NewJobController(jobService)
 	userController := controller.NewUserController(userSevice)
 
+	//Scheduler
+	go retentionService.Start(context.Background(), loadConfig.RetentionInterval)
+
+	//Jobs
+	go jobService.Monitor(context.Background())
+
 	//routes v1
 	routesV1 := routes.NewRoutesV1(
 		authController,
 		customerController,
+		customerAddressController,
+		customerPrivacyController,
+		customerActivityController,
+		customerAttachmentController,
+		tagController,
+		segmentController,
+		customFieldController,
+		importProfileController,
+		importErrorController,
+		retentionController,
+		jobController,
 		userController,
 	)
 
@@ -82,8 +208,8 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"PUT", "PATCH", "POST", "GET", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -95,8 +221,8 @@ func main() {
 	server := &http.Server{
 		Addr:           ":" + loadConfig.ServerPort,
 		Handler:        app,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
 
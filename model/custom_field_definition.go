@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	CustomFieldString  = "string"
+	CustomFieldNumber  = "number"
+	CustomFieldBoolean = "boolean"
+	CustomFieldDate    = "date"
+	CustomFieldEnum    = "enum"
+)
+
+type CustomFieldDefinition struct {
+	ID        int       `json:"id"         gorm:"type:int;primary_key"`
+	Key       string    `json:"key"        gorm:"type:varchar(64);uniqueIndex;not null"`
+	Label     string    `json:"label"      gorm:"type:varchar(125);not null"`
+	Type      string    `json:"type"       gorm:"type:varchar(25);not null"`
+	Required  bool      `json:"required"`
+	Options   []string  `json:"options"    gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (CustomFieldDefinition) TableName() string {
+	return "custom_field_definitions"
+}
@@ -3,13 +3,18 @@ package model
 import "time"
 
 type Customer struct {
-	ID        int       `json:"id" gorm:"type:int;primary_key"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Address   string    `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               int                    `json:"id" gorm:"type:int;primary_key"`
+	Username         string                 `json:"username"`
+	Email            string                 `json:"email"`
+	Phone            string                 `json:"phone"`
+	Address          string                 `json:"address"`
+	CustomFields     map[string]interface{} `json:"custom_fields" gorm:"type:jsonb;serializer:json"`
+	OwnerID          *int                   `json:"owner_id"`
+	Status           string                 `json:"status" gorm:"default:lead"`
+	StatusTimestamps map[string]time.Time   `json:"status_timestamps" gorm:"type:jsonb;serializer:json"`
+	Version          int                    `json:"version" gorm:"default:1"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
 }
 
 func (Customer) TableName() string {
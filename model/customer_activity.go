@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type CustomerActivity struct {
+	ID         int        `json:"id"          gorm:"type:int;primary_key"`
+	CustomerID int        `json:"customer_id" gorm:"not null"`
+	Type       string     `json:"type"        gorm:"type:varchar(25);not null"`
+	Subject    string     `json:"subject"     gorm:"type:varchar(255)"`
+	Body       string     `json:"body"        gorm:"not null"`
+	Author     string     `json:"author"      gorm:"type:varchar(255);not null"`
+	DueAt      *time.Time `json:"due_at"`
+	CreatedAt  time.Time  `json:"created_at"  gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at"  gorm:"autoUpdateTime"`
+}
+
+func (CustomerActivity) TableName() string {
+	return "customer_activities"
+}
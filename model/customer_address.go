@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type CustomerAddress struct {
+	ID         int       `json:"id"          gorm:"type:int;primary_key"`
+	CustomerID int       `json:"customer_id" gorm:"not null"`
+	Type       string    `json:"type"        gorm:"type:varchar(25);not null"`
+	Line1      string    `json:"line1"       gorm:"column:line1;type:varchar(255);not null"`
+	Line2      string    `json:"line2"       gorm:"column:line2;type:varchar(255)"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"     gorm:"type:char(2)"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"  gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at"  gorm:"autoUpdateTime"`
+}
+
+func (CustomerAddress) TableName() string {
+	return "customer_addresses"
+}
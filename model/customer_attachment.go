@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type CustomerAttachment struct {
+	ID          int       `json:"id"           gorm:"type:int;primary_key"`
+	CustomerID  int       `json:"customer_id"  gorm:"not null"`
+	FileName    string    `json:"file_name"    gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(125);not null"`
+	Size        int64     `json:"size"         gorm:"not null"`
+	StorageKey  string    `json:"-"            gorm:"type:varchar(255);not null;unique"`
+	UploadedBy  string    `json:"uploaded_by"  gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time `json:"created_at"   gorm:"autoCreateTime"`
+}
+
+func (CustomerAttachment) TableName() string {
+	return "customer_attachments"
+}
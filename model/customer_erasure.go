@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// CustomerErasure is the receipt left behind when a customer's personal data
+// is anonymized. Rows are never updated or deleted.
+type CustomerErasure struct {
+	ID           int       `json:"id"            gorm:"type:int;primary_key"`
+	CustomerID   int       `json:"customer_id"   gorm:"not null"`
+	Actor        string    `json:"actor"`
+	Reason       string    `json:"reason"        gorm:"type:varchar(500);not null"`
+	ErasedFields []string  `json:"erased_fields" gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time `json:"created_at"    gorm:"autoCreateTime"`
+}
+
+func (CustomerErasure) TableName() string {
+	return "customer_erasures"
+}
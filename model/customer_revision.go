@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionDelete = "delete"
+	RevisionImport = "import"
+	RevisionRevert = "revert"
+	RevisionMerge  = "merge"
+
+	RevisionTransition = "transition"
+	RevisionAssign     = "assign"
+)
+
+type CustomerRevision struct {
+	ID         int             `json:"id"          gorm:"type:int;primary_key"`
+	CustomerID int             `json:"customer_id" gorm:"not null"`
+	Action     string          `json:"action"      gorm:"type:varchar(25);not null"`
+	Actor      string          `json:"actor"`
+	Changes    json.RawMessage `json:"changes"     gorm:"type:jsonb"`
+	Snapshot   json.RawMessage `json:"snapshot"    gorm:"type:jsonb"`
+	CreatedAt  time.Time       `json:"created_at"  gorm:"autoCreateTime"`
+}
+
+func (CustomerRevision) TableName() string {
+	return "customer_revisions"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Resources that can be imported from a file.
+const (
+	ImportResourceCustomers = "customers"
+	ImportResourceUsers     = "users"
+)
+
+// ImportProfile is a saved column mapping for the imports of a resource,
+// from file header to column key.
+type ImportProfile struct {
+	ID        int               `json:"id"         gorm:"type:int;primary_key"`
+	Resource  string            `json:"resource"   gorm:"type:varchar(25);not null"`
+	Name      string            `json:"name"       gorm:"type:varchar(125);not null"`
+	Mapping   map[string]string `json:"mapping"    gorm:"type:jsonb;serializer:json"`
+	CreatedBy string            `json:"created_by" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ImportProfile) TableName() string {
+	return "import_profiles"
+}
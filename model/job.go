@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Kinds of work a job does.
+const (
+	JobImport = "import"
+	JobExport = "export"
+)
+
+// States of a job. Queued and running jobs are unfinished; the others are
+// final.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is an import or export run in the background. Result and Error hold
+// the JSON payloads the work left behind. UploadKey is the stored file an
+// import reads, removed once the job ends, and FileKey the stored file an
+// export produced, named FileName. Owner is the server running the job,
+// which refreshes HeartbeatAt while it does and stops the job once
+// CancelRequested is set.
+type Job struct {
+	ID          string          `json:"id"           gorm:"type:uuid;primary_key"`
+	Type        string          `json:"type"         gorm:"type:varchar(25);not null"`
+	Resource    string          `json:"resource"     gorm:"type:varchar(25);not null"`
+	State       string          `json:"state"        gorm:"type:varchar(25);not null"`
+	Progress    int             `json:"progress"     gorm:"not null"`
+	Result      json.RawMessage `json:"result"       gorm:"type:jsonb"`
+	Error       json.RawMessage `json:"error"        gorm:"type:jsonb"`
+	UploadKey   string          `json:"-"            gorm:"type:varchar(255);not null"`
+	FileKey     string          `json:"-"            gorm:"type:varchar(255);not null"`
+	FileName    string          `json:"file_name"    gorm:"type:varchar(255);not null"`
+	ContentType string          `json:"content_type" gorm:"type:varchar(125);not null"`
+	CreatedBy   string          `json:"created_by"   gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time       `json:"created_at"   gorm:"autoCreateTime"`
+	StartedAt   *time.Time      `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+
+	Owner           string     `json:"-" gorm:"type:varchar(64);not null"`
+	HeartbeatAt     *time.Time `json:"-"`
+	CancelRequested bool       `json:"-" gorm:"not null"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Finished reports whether the job reached a final state.
+func (job Job) Finished() bool {
+	return job.State != JobQueued && job.State != JobRunning
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type RetentionLog struct {
+	ID         int       `json:"id"          gorm:"type:int;primary_key"`
+	Policy     string    `json:"policy"      gorm:"type:varchar(125);not null"`
+	Target     string    `json:"target"      gorm:"type:varchar(255);not null"`
+	Affected   int64     `json:"affected"`
+	Error      string    `json:"error"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+func (RetentionLog) TableName() string {
+	return "retention_logs"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type Segment struct {
+	ID          int       `json:"id"          gorm:"type:int;primary_key"`
+	Name        string    `json:"name"        gorm:"type:varchar(125);uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	Filter      string    `json:"filter"      gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"  gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at"  gorm:"autoUpdateTime"`
+}
+
+func (Segment) TableName() string {
+	return "segments"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        int       `json:"id"         gorm:"type:int;primary_key"`
+	Name      string    `json:"name"       gorm:"type:varchar(125);uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+type CustomerTag struct {
+	CustomerID int       `json:"customer_id" gorm:"primaryKey"`
+	TagID      int       `json:"tag_id"      gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"  gorm:"autoCreateTime"`
+}
+
+func (CustomerTag) TableName() string {
+	return "customer_tags"
+}
@@ -7,6 +7,7 @@ type User struct {
 	Username  string    `json:"username"   gorm:"type:varchar(255);not null"`
 	Email     string    `json:"email"      gorm:"uniqueIndex;not null"`
 	Password  string    `json:"password"   gorm:"not null"`
+	Version   int       `json:"version"    gorm:"default:1"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,42 @@
+package config
+
+import "time"
+
+// Storage drivers selectable with STORAGE_DRIVER.
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+// DefaultStorageDir is where the local driver keeps files when STORAGE_DIR
+// is not set.
+const DefaultStorageDir = "storage"
+
+// DefaultAttachmentMaxSize is used when ATTACHMENT_MAX_SIZE is not set.
+const DefaultAttachmentMaxSize = 10 << 20
+
+// DefaultSignedURLTTL is used when SIGNED_URL_TTL is not set.
+const DefaultSignedURLTTL = 5 * time.Minute
+
+// AttachmentContentTypes are the detected content types accepted for
+// upload: contracts as PDF, Word, Excel or OpenDocument text files and scans
+// as images. Other zip archives are refused.
+var AttachmentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       true,
+	"application/vnd.oasis.opendocument.text":                                 true,
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// AttachmentOptions hold the upload limit and how download links are
+// signed. SigningKey signs the links the API serves itself; PublicURL is
+// prefixed to them so clients get an absolute link.
+type AttachmentOptions struct {
+	MaxSize    int64
+	URLTTL     time.Duration
+	SigningKey string
+	PublicURL  string
+}
@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// DefaultImportWorkers is used when IMPORT_WORKERS is not set.
+const DefaultImportWorkers = 4
+
+// DefaultImportBatchSize is used when IMPORT_BATCH_SIZE is not set.
+const DefaultImportBatchSize = 500
+
+// DefaultImportErrorTTL is used when IMPORT_ERROR_TTL is not set.
+const DefaultImportErrorTTL = 24 * time.Hour
+
+// ImportOptions bound the spreadsheet imports: Workers rows are validated at
+// a time and valid rows are written BatchSize at a time. Links to the error
+// workbook of a failed import stay valid for ErrorTTL and are signed like
+// attachment links, see AttachmentOptions.
+type ImportOptions struct {
+	Workers    int
+	BatchSize  int
+	ErrorTTL   time.Duration
+	SigningKey string
+	PublicURL  string
+}
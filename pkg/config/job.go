@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+// DefaultJobWorkers is used when JOB_WORKERS is not set.
+const DefaultJobWorkers = 2
+
+// JobHeartbeat is how often a server marks the jobs it runs as alive and
+// picks up the cancellations requested through other servers.
+const JobHeartbeat = 10 * time.Second
+
+// JobAbandonedAfter is how long an unfinished job may go without a
+// heartbeat before it is failed: the server running it has stopped.
+const JobAbandonedAfter = 6 * JobHeartbeat
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InitialCustomerStatus is the status every new customer starts in.
+const InitialCustomerStatus = "lead"
+
+// DefaultCustomerTransitions lists the allowed lifecycle moves as from:to
+// pairs. CUSTOMER_TRANSITIONS replaces it, e.g.
+// "lead:active,active:churned,churned:active".
+const DefaultCustomerTransitions = "lead:active,active:churned,churned:active"
+
+// CustomerLifecycle maps each status to the statuses it may move to.
+type CustomerLifecycle map[string][]string
+
+// ParseCustomerLifecycle reads a comma separated list of from:to pairs.
+func ParseCustomerLifecycle(transitions string) (CustomerLifecycle, error) {
+	if strings.TrimSpace(transitions) == "" {
+		transitions = DefaultCustomerTransitions
+	}
+
+	lifecycle := CustomerLifecycle{}
+	for _, pair := range strings.Split(transitions, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || parts[0] == parts[1] {
+			return nil, fmt.Errorf("invalid customer transition '%s'", pair)
+		}
+		lifecycle[parts[0]] = append(lifecycle[parts[0]], parts[1])
+		if _, ok := lifecycle[parts[1]]; !ok {
+			lifecycle[parts[1]] = nil
+		}
+	}
+
+	if _, ok := lifecycle[InitialCustomerStatus]; !ok {
+		return nil, fmt.Errorf("customer transitions must start from '%s'", InitialCustomerStatus)
+	}
+	return lifecycle, nil
+}
+
+func (lifecycle CustomerLifecycle) Allows(from string, to string) bool {
+	for _, next := range lifecycle[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (lifecycle CustomerLifecycle) Has(status string) bool {
+	_, ok := lifecycle[status]
+	return ok
+}
@@ -0,0 +1,77 @@
+package config
+
+import "testing"
+
+func TestDefaultCustomerLifecycle(t *testing.T) {
+	lifecycle, err := ParseCustomerLifecycle("")
+	if err != nil {
+		t.Fatalf("ParseCustomerLifecycle: %v", err)
+	}
+
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{"lead", "active", true},
+		{"active", "churned", true},
+		{"churned", "active", true},
+		{"lead", "churned", false},
+		{"active", "lead", false},
+		{"churned", "lead", false},
+		{"active", "active", false},
+		{"lead", "unknown", false},
+		{"unknown", "active", false},
+	}
+
+	for _, test := range tests {
+		if got := lifecycle.Allows(test.from, test.to); got != test.want {
+			t.Errorf("Allows(%s, %s) = %t, want %t", test.from, test.to, got, test.want)
+		}
+	}
+
+	for _, status := range []string{"lead", "active", "churned"} {
+		if !lifecycle.Has(status) {
+			t.Errorf("Has(%s) = false, want true", status)
+		}
+	}
+	if lifecycle.Has("unknown") {
+		t.Error("Has(unknown) = true, want false")
+	}
+}
+
+func TestCustomCustomerLifecycle(t *testing.T) {
+	lifecycle, err := ParseCustomerLifecycle(" lead:prospect, prospect:active ,lead:lost,active:lost ")
+	if err != nil {
+		t.Fatalf("ParseCustomerLifecycle: %v", err)
+	}
+
+	if !lifecycle.Allows("lead", "prospect") || !lifecycle.Allows("lead", "lost") || !lifecycle.Allows("active", "lost") {
+		t.Errorf("lifecycle %v is missing a declared transition", lifecycle)
+	}
+	if lifecycle.Allows("lead", "active") || lifecycle.Allows("lost", "lead") {
+		t.Errorf("lifecycle %v allows an undeclared transition", lifecycle)
+	}
+
+	// A status only ever moved to is still known, with nowhere to go.
+	if !lifecycle.Has("lost") || len(lifecycle["lost"]) != 0 {
+		t.Errorf("lost = %v, %t; want a known final status", lifecycle["lost"], lifecycle.Has("lost"))
+	}
+}
+
+func TestParseCustomerLifecycleInvalid(t *testing.T) {
+	for _, transitions := range []string{
+		"lead",
+		"lead:active,active",
+		"lead:",
+		":active",
+		"lead:active:churned",
+		"lead:lead",
+		"lead:active,,active:churned",
+		"prospect:active,active:churned",
+	} {
+		if lifecycle, err := ParseCustomerLifecycle(transitions); err == nil {
+			t.Errorf("ParseCustomerLifecycle(%q) = %v, want an error", transitions, lifecycle)
+		}
+	}
+}
@@ -13,7 +13,10 @@ type Config struct {
 	DBName     string `mapstructure:"POSTGRES_DB"`
 	DBPort     string `mapstructure:"POSTGRES_PORT"`
 
-	ServerPort string `mapstructure:"PORT"`
+	ServerPort         string        `mapstructure:"PORT"`
+	ServerReadTimeout  time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	RequestTimeout     time.Duration `mapstructure:"REQUEST_TIMEOUT"`
 
 	TokenSecret    string        `mapstructure:"TOKEN_SECRET"`
 	TokenExpiresIn time.Duration `mapstructure:"TOKEN_EXPIRED_IN"`
@@ -28,6 +31,30 @@ type Config struct {
 	SMTPPass  string `mapstructure:"SMTP_PASS"`
 	SMTPPort  int    `mapstructure:"SMTP_PORT"`
 	SMTPUser  string `mapstructure:"SMTP_USER"`
+
+	RetentionInterval time.Duration `mapstructure:"RETENTION_INTERVAL"`
+
+	CustomerTransitions string `mapstructure:"CUSTOMER_TRANSITIONS"`
+	PhoneRegion         string `mapstructure:"PHONE_REGION"`
+
+	StorageDriver     string        `mapstructure:"STORAGE_DRIVER"`
+	StorageDir        string        `mapstructure:"STORAGE_DIR"`
+	S3Endpoint        string        `mapstructure:"S3_ENDPOINT"`
+	S3Region          string        `mapstructure:"S3_REGION"`
+	S3Bucket          string        `mapstructure:"S3_BUCKET"`
+	S3AccessKey       string        `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey       string        `mapstructure:"S3_SECRET_KEY"`
+	S3PathStyle       bool          `mapstructure:"S3_PATH_STYLE"`
+	AttachmentMaxSize int64         `mapstructure:"ATTACHMENT_MAX_SIZE"`
+	SignedURLTTL      time.Duration `mapstructure:"SIGNED_URL_TTL"`
+	PublicURL         string        `mapstructure:"PUBLIC_URL"`
+	URLSigningKey     string        `mapstructure:"URL_SIGNING_KEY"`
+
+	ImportWorkers   int           `mapstructure:"IMPORT_WORKERS"`
+	ImportBatchSize int           `mapstructure:"IMPORT_BATCH_SIZE"`
+	ImportErrorTTL  time.Duration `mapstructure:"IMPORT_ERROR_TTL"`
+
+	JobWorkers int `mapstructure:"JOB_WORKERS"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
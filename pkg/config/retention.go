@@ -0,0 +1,63 @@
+package config
+
+import "time"
+
+// RetentionPolicy declares how long data is kept. Table policies delete the
+// rows of Table whose Column is older than After, optionally narrowed by
+// Condition, together with the stored files whose keys are in the Files
+// columns of those rows. File policies delete the stored files under Prefix
+// last modified more than After ago.
+type RetentionPolicy struct {
+	Name      string
+	Table     string
+	Column    string
+	Condition string
+	Files     []string
+	Prefix    string
+	After     time.Duration
+}
+
+// DefaultRetentionInterval is used when RETENTION_INTERVAL is not set.
+const DefaultRetentionInterval = time.Hour
+
+// DefaultJobRetention is how long finished jobs and their files are kept.
+const DefaultJobRetention = 7 * 24 * time.Hour
+
+// RetentionPolicies are executed by the retention scheduler. Error workbooks
+// of imports are kept as long as their links stay valid. Customers are
+// deleted outright, so there is no trash to purge for them.
+func RetentionPolicies(importErrorTTL time.Duration) []RetentionPolicy {
+	if importErrorTTL <= 0 {
+		importErrorTTL = DefaultImportErrorTTL
+	}
+
+	return []RetentionPolicy{
+		{
+			// password_resets.created_at holds the moment the OTP expires.
+			Name:   "expired_password_resets",
+			Table:  "password_resets",
+			Column: "created_at",
+			After:  24 * time.Hour,
+		},
+		{
+			Name:   "retention_logs",
+			Table:  "retention_logs",
+			Column: "started_at",
+			After:  90 * 24 * time.Hour,
+		},
+		{
+			// finished_at is NULL while a job is queued or running, so those
+			// never match.
+			Name:   "finished_jobs",
+			Table:  "jobs",
+			Column: "finished_at",
+			Files:  []string{"upload_key", "file_key"},
+			After:  DefaultJobRetention,
+		},
+		{
+			Name:   "import_error_files",
+			Prefix: "import-errors/",
+			After:  importErrorTTL,
+		},
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+// DefaultServerReadTimeout and DefaultServerWriteTimeout are used when
+// SERVER_READ_TIMEOUT and SERVER_WRITE_TIMEOUT are not set. Handlers moving
+// files lift them for their own request.
+const (
+	DefaultServerReadTimeout  = 10 * time.Second
+	DefaultServerWriteTimeout = 10 * time.Second
+)
+
+// DefaultRequestTimeout is used when REQUEST_TIMEOUT is not set.
+const DefaultRequestTimeout = 30 * time.Second
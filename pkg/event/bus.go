@@ -0,0 +1,71 @@
+package event
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Names of the events published by the services.
+const (
+	CustomerStatusChanged = "customer.status_changed"
+)
+
+type Event struct {
+	Name       string
+	OccurredAt time.Time
+	Payload    interface{}
+}
+
+type Handler func(ctx context.Context, e Event)
+
+// Bus delivers events to the handlers subscribed to their name, in process.
+type Bus interface {
+	Publish(ctx context.Context, e Event)
+	Subscribe(name string, handler Handler)
+}
+
+type BusImpl struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBusImpl() Bus {
+	return &BusImpl{handlers: make(map[string][]Handler)}
+}
+
+func (bus *BusImpl) Subscribe(name string, handler Handler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.handlers[name] = append(bus.handlers[name], handler)
+}
+
+// Publish runs the handlers synchronously in subscription order. A handler
+// that panics is logged and skipped so it cannot fail the publisher.
+func (bus *BusImpl) Publish(ctx context.Context, e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	bus.mu.RLock()
+	handlers := append([]Handler(nil), bus.handlers[e.Name]...)
+	bus.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("event: %s handler failed: %v", e.Name, r)
+				}
+			}()
+			handler(ctx, e)
+		}()
+	}
+}
+
+// LogHandler writes the event to the application log.
+func LogHandler(ctx context.Context, e Event) {
+	log.Printf("event: %s at %s: %+v", e.Name, e.OccurredAt.Format(time.RFC3339), e.Payload)
+}
@@ -0,0 +1,15 @@
+package exception
+
+type ConflictErrorStruct struct {
+	ErrorMsg string
+}
+
+func NewConflictHandler(msg string) *ConflictErrorStruct {
+	return &ConflictErrorStruct{
+		ErrorMsg: msg,
+	}
+}
+
+func (e *ConflictErrorStruct) Error() string {
+	return e.ErrorMsg
+}
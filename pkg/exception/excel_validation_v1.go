@@ -4,6 +4,8 @@ import "fmt"
 
 type NewExcelValidationError struct {
 	Errors map[string][]string
+	// ErrorFile links to the uploaded file annotated with the errors.
+	ErrorFile string
 }
 
 func (e *NewExcelValidationError) AddHandler(field string, row int, message string) {
@@ -19,6 +19,12 @@ func ExceptionHandlers(ctx *gin.Context, err interface{}) {
 		return
 	} else if unauthorizedError(ctx, err) {
 		return
+	} else if preconditionFailedError(ctx, err) {
+		return
+	} else if preconditionRequiredError(ctx, err) {
+		return
+	} else if conflictError(ctx, err) {
+		return
 	} else if excelValidationError(ctx, err) {
 		return
 	} else if excelValidation(ctx, err) {
@@ -75,12 +81,26 @@ func validationError(ctx *gin.Context, err interface{}) bool {
 				report[fieldName] = fmt.Sprintf("%s value ​​in the array cannot be empty", fieldName)
 			case "date":
 				report[fieldName] = fmt.Sprintf("%s value must be date (yyyy-mm-dd)", fieldName)
+			case "datetime":
+				report[fieldName] = fmt.Sprintf("%s value must be a date time in the layout %s", fieldName, e.Param())
 			case "notEmptyIntSlice":
 				report[fieldName] = fmt.Sprintf("%s value ​​in the array cannot be empty is int", fieldName)
 			case "isInt":
 				report[fieldName] = fmt.Sprintf("%s value must be of type int", fieldName)
 			case "isString":
 				report[fieldName] = fmt.Sprintf("%s value must be of type string", fieldName)
+			case "iso3166_1_alpha2":
+				report[fieldName] = fmt.Sprintf("%s value must be an ISO 3166-1 alpha-2 country code", fieldName)
+			case "fieldKey":
+				report[fieldName] = fmt.Sprintf("%s value must be lowercase letters, digits and underscores, starting with a letter", fieldName)
+			case "required_if":
+				report[fieldName] = fmt.Sprintf("%s is required when %s", fieldName, e.Param())
+			case "customerFilter":
+				report[fieldName] = fmt.Sprintf("%s value must be a customer query string such as tag=vip&email=example.com", fieldName)
+			case "phone":
+				report[fieldName] = fmt.Sprintf("%s value must be a valid phone number, e.g. +6281234567890", fieldName)
+			case "postcode_iso3166_alpha2_field":
+				report[fieldName] = fmt.Sprintf("%s value is not a valid postal code for the given country", fieldName)
 			}
 		}
 
@@ -128,6 +148,51 @@ func unauthorizedError(ctx *gin.Context, err interface{}) bool {
 	return false
 }
 
+func preconditionFailedError(ctx *gin.Context, err interface{}) bool {
+	exception, ok := err.(*PreconditionFailedErrorStruct)
+	if ok {
+		traceID, _ := ctx.Get("trace_id")
+		ctx.JSON(http.StatusPreconditionFailed, entity.Error{
+			Code:    http.StatusPreconditionFailed,
+			Status:  "PRECONDITION FAILED",
+			Errors:  exception.Error(),
+			TraceID: traceID.(string),
+		})
+		return true
+	}
+	return false
+}
+
+func conflictError(ctx *gin.Context, err interface{}) bool {
+	exception, ok := err.(*ConflictErrorStruct)
+	if ok {
+		traceID, _ := ctx.Get("trace_id")
+		ctx.JSON(http.StatusConflict, entity.Error{
+			Code:    http.StatusConflict,
+			Status:  "CONFLICT",
+			Errors:  exception.Error(),
+			TraceID: traceID.(string),
+		})
+		return true
+	}
+	return false
+}
+
+func preconditionRequiredError(ctx *gin.Context, err interface{}) bool {
+	exception, ok := err.(*PreconditionRequiredErrorStruct)
+	if ok {
+		traceID, _ := ctx.Get("trace_id")
+		ctx.JSON(http.StatusPreconditionRequired, entity.Error{
+			Code:    http.StatusPreconditionRequired,
+			Status:  "PRECONDITION REQUIRED",
+			Errors:  exception.Error(),
+			TraceID: traceID.(string),
+		})
+		return true
+	}
+	return false
+}
+
 func notFoundError(ctx *gin.Context, err interface{}) bool {
 	exception, ok := err.(*NotFoundErrorStruct)
 	if ok {
@@ -163,10 +228,11 @@ func excelValidationError(ctx *gin.Context, err interface{}) bool {
 	if ok {
 		traceID, _ := ctx.Get("trace_id")
 		ctx.JSON(http.StatusBadRequest, entity.Error{
-			Code:    http.StatusBadRequest,
-			Status:  "BAD REQUEST",
-			Errors:  exception.Errors,
-			TraceID: traceID.(string),
+			Code:      http.StatusBadRequest,
+			Status:    "BAD REQUEST",
+			Errors:    exception.Errors,
+			ErrorFile: exception.ErrorFile,
+			TraceID:   traceID.(string),
 		})
 		return true
 	}
@@ -178,10 +244,11 @@ func excelValidation(ctx *gin.Context, err interface{}) bool {
 	if ok {
 		traceID, _ := ctx.Get("trace_id")
 		ctx.JSON(http.StatusBadRequest, entity.Error{
-			Code:    http.StatusBadRequest,
-			Status:  "BAD REQUEST",
-			Errors:  exception.Errors,
-			TraceID: traceID.(string),
+			Code:      http.StatusBadRequest,
+			Status:    "BAD REQUEST",
+			Errors:    exception.Errors,
+			ErrorFile: exception.ErrorFile,
+			TraceID:   traceID.(string),
 		})
 		return true
 	}
@@ -0,0 +1,15 @@
+package exception
+
+type PreconditionFailedErrorStruct struct {
+	ErrorMsg string
+}
+
+func NewPreconditionFailedHandler(msg string) *PreconditionFailedErrorStruct {
+	return &PreconditionFailedErrorStruct{
+		ErrorMsg: msg,
+	}
+}
+
+func (e *PreconditionFailedErrorStruct) Error() string {
+	return e.ErrorMsg
+}
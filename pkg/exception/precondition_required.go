@@ -0,0 +1,15 @@
+package exception
+
+type PreconditionRequiredErrorStruct struct {
+	ErrorMsg string
+}
+
+func NewPreconditionRequiredHandler(msg string) *PreconditionRequiredErrorStruct {
+	return &PreconditionRequiredErrorStruct{
+		ErrorMsg: msg,
+	}
+}
+
+func (e *PreconditionRequiredErrorStruct) Error() string {
+	return e.ErrorMsg
+}
@@ -0,0 +1,103 @@
+package helper
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultPhoneRegion is assumed for phone numbers written without a country
+// calling code until SetPhoneRegion chooses another one.
+const DefaultPhoneRegion = "ID"
+
+var phoneRegion = DefaultPhoneRegion
+
+var e164Regex = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "")
+
+// callingCodes maps the supported ISO 3166-1 alpha-2 regions to their
+// country calling code.
+var callingCodes = map[string]string{
+	"AU": "61",
+	"CN": "86",
+	"DE": "49",
+	"FR": "33",
+	"GB": "44",
+	"ID": "62",
+	"IN": "91",
+	"JP": "81",
+	"KR": "82",
+	"MY": "60",
+	"NL": "31",
+	"PH": "63",
+	"SG": "65",
+	"TH": "66",
+	"US": "1",
+	"VN": "84",
+}
+
+// NormalizeEmail trims and lowercases an email address so that lookups and
+// the unique check are case-insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone rewrites phone to E.164. Numbers starting with + or 00 are
+// taken as international; anything else is read as a national number of
+// region, dropping the trunk prefix 0 ("0812-3456-789" in ID becomes
+// "+628123456789"). Digits that already start with the region's calling
+// code, as in "628123456789", are kept as they are.
+func NormalizePhone(phone string, region string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	default:
+		code, ok := callingCodes[strings.ToUpper(region)]
+		if !ok {
+			return phone, errors.New("unsupported phone region " + region)
+		}
+		if strings.HasPrefix(number, "0") {
+			number = code + strings.TrimLeft(number, "0")
+		} else if !strings.HasPrefix(number, code) || len(number)-len(code) < 8 {
+			number = code + number
+		}
+	}
+
+	number = "+" + number
+	if !e164Regex.MatchString(number) {
+		return phone, errors.New("phone number is not valid")
+	}
+	return number, nil
+}
+
+// PhoneRegion is the region of phone numbers written without a country
+// calling code.
+func PhoneRegion() string {
+	return phoneRegion
+}
+
+// SetPhoneRegion sets the region returned by PhoneRegion. It is meant to be
+// called once at startup, before requests are served.
+func SetPhoneRegion(region string) error {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if _, ok := callingCodes[region]; !ok {
+		return errors.New("unsupported phone region " + region)
+	}
+	phoneRegion = region
+	return nil
+}
+
+// FormatPhone returns the E.164 form of phone in the phone region, or phone
+// unchanged when it cannot be parsed so validation can reject it.
+func FormatPhone(phone string) string {
+	number, err := NormalizePhone(phone, PhoneRegion())
+	if err != nil {
+		return phone
+	}
+	return number
+}
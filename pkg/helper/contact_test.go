@@ -0,0 +1,88 @@
+package helper
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := map[string]string{
+		"ann@example.com":         "ann@example.com",
+		"  Ann@Example.COM \t":    "ann@example.com",
+		"BOB.SMITH@EXAMPLE.CO.ID": "bob.smith@example.co.id",
+		"":                        "",
+	}
+
+	for email, want := range tests {
+		if got := NormalizeEmail(email); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone  string
+		region string
+		want   string
+	}{
+		{"0812-3456-789", "ID", "+628123456789"},
+		{"(0812) 3456 789", "id", "+628123456789"},
+		{"628123456789", "ID", "+628123456789"},
+		{"8123456789", "ID", "+628123456789"},
+		{"+62 812 3456 789", "US", "+628123456789"},
+		{"0062 812 3456 789", "US", "+628123456789"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"(415) 555-0132", "US", "+14155550132"},
+		{"6591234567", "SG", "+6591234567"},
+		{"9123 4567", "SG", "+6591234567"},
+	}
+
+	for _, test := range tests {
+		got, err := NormalizePhone(test.phone, test.region)
+		if err != nil || got != test.want {
+			t.Errorf("NormalizePhone(%q, %s) = %q, %v; want %q", test.phone, test.region, got, err, test.want)
+		}
+	}
+}
+
+func TestNormalizePhoneInvalid(t *testing.T) {
+	tests := []struct {
+		phone  string
+		region string
+	}{
+		{"0812-3456-789", "XX"},
+		{"12345", "ID"},
+		{"+0812345678", "ID"},
+		{"0812-ABCD-789", "ID"},
+		{"+62 812 3456 7890 1234", "ID"},
+	}
+
+	for _, test := range tests {
+		got, err := NormalizePhone(test.phone, test.region)
+		if err == nil {
+			t.Errorf("NormalizePhone(%q, %s) = %q, want an error", test.phone, test.region, got)
+		}
+		if got != test.phone {
+			t.Errorf("NormalizePhone(%q, %s) returned %q with its error, want the input unchanged", test.phone, test.region, got)
+		}
+	}
+}
+
+func TestSetPhoneRegion(t *testing.T) {
+	t.Cleanup(func() { phoneRegion = DefaultPhoneRegion })
+
+	if err := SetPhoneRegion(" sg "); err != nil {
+		t.Fatalf("SetPhoneRegion: %v", err)
+	}
+	if got := PhoneRegion(); got != "SG" {
+		t.Errorf("PhoneRegion() = %s, want SG", got)
+	}
+	if got := FormatPhone("9123 4567"); got != "+6591234567" {
+		t.Errorf("FormatPhone in SG = %s, want +6591234567", got)
+	}
+
+	if err := SetPhoneRegion("XX"); err == nil {
+		t.Error("SetPhoneRegion(XX) succeeded, want an error")
+	}
+	if got := PhoneRegion(); got != "SG" {
+		t.Errorf("PhoneRegion() after a rejected region = %s, want SG", got)
+	}
+}
@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"fmt"
+	"scylla/model"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomFieldValue checks a single value against its definition and returns
+// it in the form stored in customers.custom_fields. JSON requests send typed
+// values while spreadsheet cells always arrive as strings, so both are
+// accepted.
+func CustomFieldValue(definition model.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	text, isText := value.(string)
+	if isText {
+		text = strings.TrimSpace(text)
+	}
+
+	switch definition.Type {
+	case model.CustomFieldNumber:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		if number, err := strconv.ParseFloat(text, 64); isText && err == nil {
+			return number, nil
+		}
+		return nil, fmt.Errorf("%s value must be number", definition.Key)
+	case model.CustomFieldBoolean:
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+		if flag, err := strconv.ParseBool(text); isText && err == nil {
+			return flag, nil
+		}
+		return nil, fmt.Errorf("%s value must be true or false", definition.Key)
+	case model.CustomFieldDate:
+		if _, err := time.Parse("2006-01-02", text); isText && err == nil {
+			return text, nil
+		}
+		return nil, fmt.Errorf("%s value must be date (yyyy-mm-dd)", definition.Key)
+	case model.CustomFieldEnum:
+		for _, option := range definition.Options {
+			if isText && option == text {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("%s value must be %s", definition.Key, strings.Join(definition.Options, " "))
+	default:
+		if isText {
+			return text, nil
+		}
+		return nil, fmt.Errorf("%s value must be of type string", definition.Key)
+	}
+}
+
+// ValidateCustomFields checks a full set of custom field values against the
+// admin-defined schema. It returns the normalized values and one message per
+// problem found; unknown keys and missing required fields are both reported.
+func ValidateCustomFields(definitions []model.CustomFieldDefinition, values map[string]interface{}) (map[string]interface{}, []string) {
+	result := make(map[string]interface{})
+	var errs []string
+
+	known := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		known[definition.Key] = true
+
+		value, ok := values[definition.Key]
+		if !ok || value == nil || value == "" {
+			if definition.Required {
+				errs = append(errs, fmt.Sprintf("%s is required", definition.Key))
+			}
+			continue
+		}
+
+		normalized, err := CustomFieldValue(definition, value)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		result[definition.Key] = normalized
+	}
+
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Sprintf("%s is not a defined custom field", key))
+	}
+
+	return result, errs
+}
+
+// FindCustomField looks a definition up by key or label, ignoring case, as
+// spreadsheet headers may use either.
+func FindCustomField(definitions []model.CustomFieldDefinition, header string) (model.CustomFieldDefinition, bool) {
+	header = strings.TrimSpace(header)
+	for _, definition := range definitions {
+		if strings.EqualFold(definition.Key, header) || strings.EqualFold(definition.Label, header) {
+			return definition, true
+		}
+	}
+	return model.CustomFieldDefinition{}, false
+}
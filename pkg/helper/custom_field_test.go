@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"reflect"
+	"scylla/model"
+	"testing"
+)
+
+var testDefinitions = []model.CustomFieldDefinition{
+	{Key: "industry", Label: "Industry", Type: model.CustomFieldEnum, Options: []string{"retail", "finance"}, Required: true},
+	{Key: "employees", Label: "Employees", Type: model.CustomFieldNumber},
+	{Key: "partner", Label: "Partner", Type: model.CustomFieldBoolean},
+	{Key: "renewal", Label: "Renewal Date", Type: model.CustomFieldDate},
+	{Key: "notes", Label: "Notes", Type: model.CustomFieldString},
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name:   "typed json values",
+			values: map[string]interface{}{"industry": "retail", "employees": 12.0, "partner": true, "renewal": "2026-01-31", "notes": " vip "},
+			want:   map[string]interface{}{"industry": "retail", "employees": 12.0, "partner": true, "renewal": "2026-01-31", "notes": "vip"},
+		},
+		{
+			name:   "spreadsheet cells",
+			values: map[string]interface{}{"industry": " finance ", "employees": "12", "partner": "false", "renewal": ""},
+			want:   map[string]interface{}{"industry": "finance", "employees": 12.0, "partner": false},
+		},
+	}
+
+	for _, test := range tests {
+		got, errs := ValidateCustomFields(testDefinitions, test.values)
+		if len(errs) != 0 || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, %q, want %v", test.name, got, errs, test.want)
+		}
+	}
+}
+
+func TestValidateCustomFieldsRejected(t *testing.T) {
+	values := map[string]interface{}{
+		"employees": "a dozen",
+		"partner":   "sometimes",
+		"renewal":   "31/01/2026",
+		"notes":     12.0,
+		"zeta":      "x",
+		"alpha":     "y",
+	}
+
+	_, errs := ValidateCustomFields(testDefinitions, values)
+
+	want := []string{
+		"industry is required",
+		"employees value must be number",
+		"partner value must be true or false",
+		"renewal value must be date (yyyy-mm-dd)",
+		"notes value must be of type string",
+		"alpha is not a defined custom field",
+		"zeta is not a defined custom field",
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors %q, want %q", errs, want)
+	}
+
+	if _, errs := ValidateCustomFields(testDefinitions, map[string]interface{}{"industry": "mining"}); len(errs) != 1 {
+		t.Errorf("enum outside its options gave %q", errs)
+	}
+}
+
+func TestFindCustomField(t *testing.T) {
+	for _, header := range []string{"renewal", " Renewal Date ", "RENEWAL DATE"} {
+		if definition, ok := FindCustomField(testDefinitions, header); !ok || definition.Key != "renewal" {
+			t.Errorf("FindCustomField(%q) = %s, %v", header, definition.Key, ok)
+		}
+	}
+	if _, ok := FindCustomField(testDefinitions, "renewal_date"); ok {
+		t.Errorf("FindCustomField matched an unknown header")
+	}
+}
@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"encoding/json"
+	"reflect"
+	"scylla/entity"
+)
+
+// Diff compares two values field by field through their JSON form and
+// returns only the fields whose value changed. Either side may be nil,
+// which is how creations and deletions are recorded.
+func Diff(before interface{}, after interface{}, ignore ...string) map[string]entity.FieldChange {
+	oldFields := map[string]interface{}{}
+	newFields := map[string]interface{}{}
+	json.Unmarshal([]byte(StructToJson(before)), &oldFields)
+	json.Unmarshal([]byte(StructToJson(after)), &newFields)
+
+	for _, field := range ignore {
+		delete(oldFields, field)
+		delete(newFields, field)
+	}
+
+	changes := map[string]entity.FieldChange{}
+	for field, value := range newFields {
+		if !reflect.DeepEqual(oldFields[field], value) {
+			changes[field] = entity.FieldChange{Old: oldFields[field], New: value}
+		}
+	}
+	for field, value := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changes[field] = entity.FieldChange{Old: value, New: nil}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,75 @@
+package helper
+
+import (
+	"reflect"
+	"scylla/entity"
+	"testing"
+)
+
+type diffCustomer struct {
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	Tags      []string `json:"tags"`
+	Version   int      `json:"version"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	before := diffCustomer{Username: "ann", Email: "ann@example.com", Tags: []string{"vip"}, Version: 1, UpdatedAt: "2024-01-01"}
+	after := diffCustomer{Username: "ann", Email: "ann@example.org", Tags: []string{"vip", "b2b"}, Version: 2, UpdatedAt: "2024-01-02"}
+
+	got := Diff(before, after, "version", "updated_at")
+	want := map[string]entity.FieldChange{
+		"email": {Old: "ann@example.com", New: "ann@example.org"},
+		"tags":  {Old: []interface{}{"vip"}, New: []interface{}{"vip", "b2b"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	customer := diffCustomer{Username: "ann", Tags: []string{"vip"}}
+
+	if got := Diff(customer, customer); len(got) != 0 {
+		t.Errorf("Diff of equal values = %v, want no changes", got)
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	customer := diffCustomer{Username: "ann", Version: 1}
+
+	created := Diff(nil, customer, "tags", "updated_at")
+	want := map[string]entity.FieldChange{
+		"username": {Old: nil, New: "ann"},
+		"email":    {Old: nil, New: ""},
+		"version":  {Old: nil, New: float64(1)},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("Diff(nil, customer) = %v, want %v", created, want)
+	}
+
+	deleted := Diff(customer, nil, "tags", "updated_at")
+	want = map[string]entity.FieldChange{
+		"username": {Old: "ann", New: nil},
+		"email":    {Old: "", New: nil},
+		"version":  {Old: float64(1), New: nil},
+	}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Diff(customer, nil) = %v, want %v", deleted, want)
+	}
+}
+
+func TestDiffMaps(t *testing.T) {
+	before := map[string]interface{}{"status": "lead", "owner_id": 3}
+	after := map[string]interface{}{"status": "customer"}
+
+	got := Diff(before, after)
+	want := map[string]entity.FieldChange{
+		"status":   {Old: "lead", New: "customer"},
+		"owner_id": {Old: float64(3), New: nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+}
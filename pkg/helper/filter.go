@@ -0,0 +1,116 @@
+package helper
+
+import (
+	"fmt"
+	"net/url"
+	"scylla/entity"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// segmentFilterKeys are the GET /customers query keys a saved segment may
+// use. Paging is left to whoever evaluates the segment.
+var segmentFilterKeys = map[string]bool{
+	"start_date": true,
+	"end_date":   true,
+	"username":   true,
+	"email":      true,
+	"tag":        true,
+	"status":     true,
+	"owner_id":   true,
+	"sort":       true,
+}
+
+// ParseCustomerFilter turns a segment filter expression (a query string)
+// into the filter used by the customer listing.
+func ParseCustomerFilter(expression string) (filter entity.CustomerQueryFilter, err error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(strings.TrimSpace(expression), "?"))
+	if err != nil {
+		return filter, err
+	}
+
+	if len(values) == 0 {
+		return filter, fmt.Errorf("filter is empty")
+	}
+
+	for key := range values {
+		if name, ok := customFieldFilterKey(key); ok {
+			if filter.CustomFields == nil {
+				filter.CustomFields = make(map[string]string)
+			}
+			filter.CustomFields[name] = values.Get(key)
+			delete(values, key)
+			continue
+		}
+		if !segmentFilterKeys[key] {
+			return filter, fmt.Errorf("filter key '%s' is not supported", key)
+		}
+	}
+
+	if err = binding.MapFormWithTag(&filter, values, "form"); err != nil {
+		return filter, err
+	}
+
+	_, err = ParseCustomerSort(filter.Sort)
+	return filter, err
+}
+
+// customerSortColumns are the columns the customer listing may be sorted by.
+var customerSortColumns = map[string]bool{
+	"id":         true,
+	"username":   true,
+	"email":      true,
+	"phone":      true,
+	"status":     true,
+	"owner_id":   true,
+	"created_at": true,
+}
+
+// ParseCustomerSort turns a sort expression such as "created_at:desc,id:asc"
+// into ORDER BY clauses. Only known columns and directions get through, as
+// the clauses end up in the query as they are.
+func ParseCustomerSort(sort string) (clauses []string, err error) {
+	if strings.TrimSpace(sort) == "" {
+		return nil, nil
+	}
+
+	for _, row := range strings.Split(sort, ",") {
+		column, direction, _ := strings.Cut(strings.TrimSpace(row), ":")
+		direction = strings.ToUpper(direction)
+		if !customerSortColumns[column] {
+			return nil, fmt.Errorf("cannot sort by '%s'", column)
+		}
+		if direction == "" {
+			direction = "ASC"
+		}
+		if direction != "ASC" && direction != "DESC" {
+			return nil, fmt.Errorf("sort direction '%s' must be asc or desc", direction)
+		}
+		clauses = append(clauses, column+" "+direction)
+	}
+	return clauses, nil
+}
+
+// customFieldFilterKey extracts "industry" from "custom_fields[industry]".
+func customFieldFilterKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, "custom_fields[") || !strings.HasSuffix(key, "]") {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(key, "custom_fields["), "]")
+	return name, name != ""
+}
+
+// SplitList splits a comma separated list such as tag names, dropping blanks
+// and repeats, so the count of a tag filter matches the distinct names.
+func SplitList(tags string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCustomerFilter(t *testing.T) {
+	filter, err := ParseCustomerFilter("?status=lead,active&tag=vip&owner_id=7&custom_fields[industry]=retail&sort=created_at:desc")
+	if err != nil {
+		t.Fatalf("ParseCustomerFilter: %v", err)
+	}
+
+	if filter.Status != "lead,active" || filter.Tag != "vip" || filter.OwnerID != 7 || filter.Sort != "created_at:desc" {
+		t.Errorf("parsed %+v", filter)
+	}
+	if !reflect.DeepEqual(filter.CustomFields, map[string]string{"industry": "retail"}) {
+		t.Errorf("custom fields %v, want industry=retail", filter.CustomFields)
+	}
+}
+
+func TestParseCustomerFilterRejected(t *testing.T) {
+	tests := []string{
+		"",
+		"?",
+		"limit=10",
+		"page=2",
+		"owner_id=ann",
+		"sort=password:asc",
+		"sort=id;DROP TABLE customers:asc",
+		"sort=id:sideways",
+		"custom_fields[]=retail",
+	}
+
+	for _, expression := range tests {
+		if _, err := ParseCustomerFilter(expression); err == nil {
+			t.Errorf("ParseCustomerFilter(%q) did not fail", expression)
+		}
+	}
+}
+
+func TestParseCustomerSort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", nil},
+		{"created_at:desc", []string{"created_at DESC"}},
+		{"status:ASC, username", []string{"status ASC", "username ASC"}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseCustomerSort(test.sort)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseCustomerSort(%q) = %q, %v, want %q", test.sort, got, err, test.want)
+		}
+	}
+
+	for _, sort := range []string{"id desc", "id:desc nulls first", "(SELECT 1):asc", "email:asc,"} {
+		if _, err := ParseCustomerSort(sort); err == nil {
+			t.Errorf("ParseCustomerSort(%q) did not fail", sort)
+		}
+	}
+}
@@ -12,18 +12,25 @@ import (
 var modelMap = map[string]reflect.Type{
 	"customers": reflect.TypeOf(model.Customer{}),
 	"users":     reflect.TypeOf(model.User{}),
+	"tags":      reflect.TypeOf(model.Tag{}),
+	"segments":  reflect.TypeOf(model.Segment{}),
+
+	"custom_field_definitions": reflect.TypeOf(model.CustomFieldDefinition{}),
 }
 
 func ValidateUnique(db *gorm.DB, fl validator.FieldLevel) bool {
 	value := fl.Field().Interface()
 	tableName := getModelFromTag(fl)
 
-	exists := UniqueExistsInTable(db, value, tableName)
+	exists := UniqueExistsInTable(db, value, tableName, getOwnId(fl, tableName))
 
 	return !exists
 }
 
-func UniqueExistsInTable(db *gorm.DB, value interface{}, tableName string) bool {
+// UniqueExistsInTable tells whether a row other than the one identified by
+// ownId already holds value. ownId is only used when tableName names the id
+// column as its third part.
+func UniqueExistsInTable(db *gorm.DB, value interface{}, tableName string, ownId interface{}) bool {
 	parts := strings.Split(tableName, ";")
 	modelName := parts[0]
 	columnName := parts[1]
@@ -37,7 +44,7 @@ func UniqueExistsInTable(db *gorm.DB, value interface{}, tableName string) bool
 
 	var err error
 	if len(parts) > 2 {
-		err = db.Table(modelName).Where(columnName+" = ? AND "+parts[2], value).First(modelInstance).Error
+		err = db.Table(modelName).Where(columnName+" = ? AND "+parts[2]+" <> ?", value, ownId).First(modelInstance).Error
 	} else {
 		err = db.Table(modelName).Where(columnName+" = ?", value).First(modelInstance).Error
 	}
@@ -47,6 +54,28 @@ func UniqueExistsInTable(db *gorm.DB, value interface{}, tableName string) bool
 	return true
 }
 
+// getOwnId reads the id of the row being updated from the request field
+// named like the id column, so the row does not collide with itself.
+func getOwnId(fl validator.FieldLevel, tableName string) interface{} {
+	parts := strings.Split(tableName, ";")
+	if len(parts) < 3 {
+		return nil
+	}
+
+	parent := reflect.Indirect(fl.Parent())
+	if parent.Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := parent.FieldByNameFunc(func(name string) bool {
+		return strings.EqualFold(name, parts[2])
+	})
+	if !field.IsValid() {
+		return nil
+	}
+	return field.Interface()
+}
+
 func getModelFromTag(fl validator.FieldLevel) string {
 	// Assuming 'validate' tag is in the format "unique=tableName;columnName;columnID" columnID is optional when update data
 	validateTag := fl.Param()
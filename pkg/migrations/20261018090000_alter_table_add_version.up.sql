@@ -0,0 +1,5 @@
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_customer_revisions_customer_id;
+
+DROP TABLE IF EXISTS customer_revisions;
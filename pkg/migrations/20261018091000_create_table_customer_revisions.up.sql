@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS customer_revisions (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL,
+    action VARCHAR(25) NOT NULL,
+    actor VARCHAR(125) NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    snapshot JSONB NULL,
+    created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_revisions_customer_id
+    ON customer_revisions (customer_id, created_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS unique_customer_addresses_default;
+
+ALTER TABLE customer_addresses
+DROP CONSTRAINT IF EXISTS fk_customer_addresses_customer;
+
+DROP TABLE IF EXISTS customer_addresses;
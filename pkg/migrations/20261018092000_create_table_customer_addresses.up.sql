@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS customer_addresses (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL,
+    type VARCHAR(25) NOT NULL,
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255) NULL,
+    city VARCHAR(125) NULL,
+    region VARCHAR(125) NULL,
+    postal_code VARCHAR(25) NULL,
+    country CHAR(2) NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'fk_customer_addresses_customer'
+          AND connamespace = (SELECT oid FROM pg_namespace WHERE nspname = 'public')
+    ) THEN
+ALTER TABLE customer_addresses
+    ADD CONSTRAINT fk_customer_addresses_customer
+        FOREIGN KEY (customer_id)
+            REFERENCES customers (id)
+            ON DELETE CASCADE;
+END IF;
+END $$;
+
+-- At most one default address per customer and type.
+CREATE UNIQUE INDEX IF NOT EXISTS unique_customer_addresses_default
+    ON customer_addresses (customer_id, type)
+    WHERE is_default;
+
+-- Carry the legacy free-text address over as the default billing address.
+-- Country and postal code are unknown for these rows and stay NULL.
+INSERT INTO customer_addresses (customer_id, type, line1, is_default, created_at)
+SELECT id, 'billing', address, TRUE, created_at
+FROM customers
+WHERE address IS NOT NULL
+  AND address <> ''
+  AND NOT EXISTS (
+      SELECT 1 FROM customer_addresses WHERE customer_addresses.customer_id = customers.id
+  );
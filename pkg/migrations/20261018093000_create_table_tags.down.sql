@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS segments;
+
+DROP TABLE IF EXISTS customer_tags;
+
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(125) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS customer_tags (
+    customer_id INT NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY (customer_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_tags_tag_id ON customer_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS segments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(125) NOT NULL,
+    description VARCHAR(255) NULL,
+    filter TEXT NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_segments_name ON segments (name);
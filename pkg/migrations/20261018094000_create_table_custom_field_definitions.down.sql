@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_customers_custom_fields;
+
+ALTER TABLE customers
+DROP COLUMN IF EXISTS custom_fields;
+
+DROP TABLE IF EXISTS custom_field_definitions;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    key VARCHAR(64) NOT NULL,
+    label VARCHAR(125) NOT NULL,
+    type VARCHAR(25) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    options JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_custom_field_definitions_key
+    ON custom_field_definitions (key);
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_customers_custom_fields
+    ON customers USING GIN (custom_fields);
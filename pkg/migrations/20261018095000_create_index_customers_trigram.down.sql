@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_customers_phone_digits;
+
+DROP INDEX IF EXISTS idx_customers_email_lower;
+
+DROP INDEX IF EXISTS idx_customers_username_trgm;
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_customers_username_trgm
+    ON customers USING GIN (lower(username) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_customers_email_lower
+    ON customers (lower(trim(email)));
+
+CREATE INDEX IF NOT EXISTS idx_customers_phone_digits
+    ON customers (regexp_replace(phone, '\D', '', 'g'));
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trg_customer_erasures_immutable ON customer_erasures;
+
+DROP FUNCTION IF EXISTS customer_erasures_immutable();
+
+DROP INDEX IF EXISTS idx_customer_erasures_customer_id;
+
+DROP TABLE IF EXISTS customer_erasures;
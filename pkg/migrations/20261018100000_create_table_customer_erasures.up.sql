@@ -0,0 +1,24 @@
+-- Erasure receipts outlive the customer, so there is no foreign key.
+CREATE TABLE IF NOT EXISTS customer_erasures (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL,
+    actor VARCHAR(125) NULL,
+    reason VARCHAR(500) NOT NULL,
+    erased_fields JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_erasures_customer_id
+    ON customer_erasures (customer_id);
+
+CREATE OR REPLACE FUNCTION customer_erasures_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'customer erasure receipts are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_customer_erasures_immutable ON customer_erasures;
+
+CREATE TRIGGER trg_customer_erasures_immutable
+    BEFORE UPDATE OR DELETE ON customer_erasures
+    FOR EACH ROW EXECUTE FUNCTION customer_erasures_immutable();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_retention_logs_started_at;
+
+DROP TABLE IF EXISTS retention_logs;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS retention_logs (
+    id SERIAL PRIMARY KEY,
+    policy VARCHAR(125) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    affected BIGINT NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    started_at timestamptz NOT NULL DEFAULT (now()),
+    finished_at timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_logs_started_at
+    ON retention_logs (started_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_customers_status;
+
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS status_timestamps,
+    DROP COLUMN IF EXISTS status;
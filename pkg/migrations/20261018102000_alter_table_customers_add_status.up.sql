@@ -0,0 +1,11 @@
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS status VARCHAR(25) NOT NULL DEFAULT 'lead',
+    ADD COLUMN IF NOT EXISTS status_timestamps JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Existing customers became leads when they were created.
+UPDATE customers
+SET status_timestamps = jsonb_build_object('lead', created_at)
+WHERE status_timestamps = '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_customers_status
+    ON customers (status);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_customers_owner_id;
+
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS owner_id;
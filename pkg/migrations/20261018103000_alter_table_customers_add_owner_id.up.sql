@@ -0,0 +1,5 @@
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_owner_id
+    ON customers (owner_id);
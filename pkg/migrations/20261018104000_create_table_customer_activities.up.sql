@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS customer_activities (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    type VARCHAR(25) NOT NULL CHECK (type IN ('note', 'call', 'email', 'meeting')),
+    subject VARCHAR(255) NULL,
+    body TEXT NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    due_at timestamptz NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_activities_customer_created
+    ON customer_activities (customer_id, created_at DESC);
+
+-- Open follow-ups are looked up by due date.
+CREATE INDEX IF NOT EXISTS idx_customer_activities_due_at
+    ON customer_activities (due_at)
+    WHERE due_at IS NOT NULL;
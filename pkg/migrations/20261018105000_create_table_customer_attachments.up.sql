@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS customer_attachments (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(125) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    uploaded_by VARCHAR(255) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_attachments_customer_id
+    ON customer_attachments (customer_id);
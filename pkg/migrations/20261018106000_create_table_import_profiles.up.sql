@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id SERIAL PRIMARY KEY,
+    resource VARCHAR(25) NOT NULL CHECK (resource IN ('customers', 'users')),
+    name VARCHAR(125) NOT NULL,
+    mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_by VARCHAR(255) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL,
+    UNIQUE (resource, name)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    type VARCHAR(25) NOT NULL CHECK (type IN ('import', 'export')),
+    resource VARCHAR(25) NOT NULL CHECK (resource IN ('customers', 'users')),
+    state VARCHAR(25) NOT NULL DEFAULT 'queued' CHECK (state IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    progress SMALLINT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    result JSONB NULL,
+    error JSONB NULL,
+    upload_key VARCHAR(255) NOT NULL DEFAULT '',
+    file_key VARCHAR(255) NOT NULL DEFAULT '',
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(125) NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    started_at timestamptz NULL,
+    finished_at timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_unfinished
+    ON jobs (state) WHERE state IN ('queued', 'running');
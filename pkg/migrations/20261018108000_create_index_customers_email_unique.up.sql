@@ -0,0 +1,5 @@
+-- Emails are unique regardless of case. Rows stored before emails were
+-- normalized on write must be lowercased first with `make normalize`,
+-- otherwise this index cannot be built.
+CREATE UNIQUE INDEX IF NOT EXISTS unique_customers_email_lower
+    ON customers (lower(email));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_jobs_unfinished_owner;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS cancel_requested,
+    DROP COLUMN IF EXISTS heartbeat_at,
+    DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,9 @@
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS owner VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS heartbeat_at timestamptz NULL,
+    ADD COLUMN IF NOT EXISTS cancel_requested BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Unfinished jobs recorded before owners existed have no heartbeat, so the
+-- next sweep fails them as abandoned.
+CREATE INDEX IF NOT EXISTS idx_jobs_unfinished_owner
+    ON jobs (owner) WHERE state IN ('queued', 'running');
@@ -0,0 +1,494 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unsignedPayload lets requests be signed without hashing the body first,
+// which keeps uploads streaming.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// multipartPartSize is the size of the parts an upload of unknown size is
+// sent in. S3 needs every part but the last to be at least 5 MiB.
+const multipartPartSize = 8 << 20
+
+// S3Options configure an S3-compatible backend. PathStyle addresses the
+// bucket as endpoint/bucket/key, as MinIO and most local stand-ins expect,
+// instead of bucket.endpoint/key.
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// S3Storage talks to S3 or a compatible server over plain HTTP, signing each
+// request with AWS Signature Version 4.
+type S3Storage struct {
+	options  S3Options
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3Storage(options S3Options) (*S3Storage, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint '%s' must be an absolute url", options.Endpoint)
+	}
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+
+	return &S3Storage{
+		options:  options,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Put uploads body in one request when its size is known or it fits in one
+// part, and as a multipart upload otherwise, holding one part in memory at
+// a time.
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if size >= 0 {
+		return s.putObject(ctx, key, body, size, contentType)
+	}
+
+	part := make([]byte, multipartPartSize)
+	n, err := io.ReadFull(body, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return s.putObject(ctx, key, bytes.NewReader(part[:n]), int64(n), contentType)
+	}
+	if err != nil {
+		return err
+	}
+	return s.putMultipart(ctx, key, io.MultiReader(bytes.NewReader(part), body), contentType)
+}
+
+func (s *S3Storage) putObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// completedPart is a part listed in CompleteMultipartUpload.
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// putMultipart uploads body in parts of multipartPartSize and aborts the
+// upload when any step fails, so no partial object is left behind.
+func (s *S3Storage) putMultipart(ctx context.Context, key string, body io.Reader, contentType string) (err error) {
+	uploadId, err := s.createMultipart(ctx, key, contentType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			s.abortMultipart(context.WithoutCancel(ctx), key, uploadId)
+		}
+	}()
+
+	var parts []completedPart
+	buffer := make([]byte, multipartPartSize)
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(body, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return readErr
+		}
+		if n == 0 {
+			break
+		}
+
+		etag, err := s.uploadPart(ctx, key, uploadId, number, buffer[:n])
+		if err != nil {
+			return err
+		}
+		parts = append(parts, completedPart{PartNumber: number, ETag: etag})
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	return s.completeMultipart(ctx, key, uploadId, parts)
+}
+
+func (s *S3Storage) createMultipart(ctx context.Context, key string, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.multipartURL(key, url.Values{"uploads": {""}}), nil)
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		UploadId string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("s3 create multipart upload %s: %w", key, err)
+	}
+	return created.UploadId, nil
+}
+
+func (s *S3Storage) uploadPart(ctx context.Context, key string, uploadId string, number int, part []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadId}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.multipartURL(key, query), bytes.NewReader(part))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (s *S3Storage) completeMultipart(ctx context.Context, key string, uploadId string, parts []completedPart) error {
+	payload, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.multipartURL(key, url.Values{"uploadId": {uploadId}}), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 can answer 200 and still fail the upload in the body.
+	var result struct {
+		XMLName xml.Name
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 complete multipart upload %s: %s", key, result.Message)
+	}
+	return nil
+}
+
+// abortMultipart discards the parts of a failed upload. Failing to do so
+// only leaves them until a bucket lifecycle rule removes them, so it is
+// logged.
+func (s *S3Storage) abortMultipart(ctx context.Context, key string, uploadId string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.multipartURL(key, url.Values{"uploadId": {uploadId}}), nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = s.do(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		log.Printf("s3: aborting multipart upload of %s: %v", key, err)
+	}
+}
+
+func (s *S3Storage) multipartURL(key string, query url.Values) string {
+	target := s.objectURL(key)
+	target.RawQuery = canonicalQuery(query)
+	return target.String()
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listBucketResult is the part of a ListObjectsV2 response List reads.
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2 until every object under prefix is read.
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		target := s.objectURL("")
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		target.RawQuery = canonicalQuery(query)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list %s: %w", prefix, err)
+		}
+
+		for _, content := range page.Contents {
+			objects = append(objects, Object{Key: content.Key, LastModified: content.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// SignedURL presigns a GET of the object valid for expiry, asking the server
+// to serve it as a download named fileName.
+func (s *S3Storage) SignedURL(ctx context.Context, key string, fileName string, expiry time.Duration) (string, error) {
+	now := time.Now().UTC()
+	target := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.options.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if fileName != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+
+	headers := map[string]string{"host": target.Host}
+	signature := s.signature(now, http.MethodGet, target.EscapedPath(), query, headers, unsignedPayload)
+	query.Set("X-Amz-Signature", signature)
+
+	target.RawQuery = canonicalQuery(query)
+	return target.String(), nil
+}
+
+func (s *S3Storage) objectURL(key string) *url.URL {
+	target := *s.endpoint
+	escaped := uriEncode(key, false)
+	if s.options.PathStyle {
+		target.Path = strings.TrimSuffix(s.endpoint.Path, "/") + "/" + s.options.Bucket + "/" + key
+		target.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + "/" + uriEncode(s.options.Bucket, false) + "/" + escaped
+	} else {
+		target.Host = s.options.Bucket + "." + target.Host
+		target.Path = "/" + key
+		target.RawPath = "/" + escaped
+	}
+	return &target
+}
+
+// do signs and sends req, turning error statuses into errors.
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	now := time.Now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           now.Format("20060102T150405Z"),
+	}
+	signature := s.signature(now, req.Method, req.URL.EscapedPath(), req.URL.Query(), headers, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.options.AccessKey, s.scope(now), signedHeaders(headers), signature,
+	))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.options.Region + "/s3/aws4_request"
+}
+
+// signature computes the Signature Version 4 of a request described by its
+// method, escaped path, query, signed headers (lowercase names) and payload
+// hash.
+func (s *S3Storage) signature(now time.Time, method string, path string, query url.Values, headers map[string]string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range sortedKeys(headers) {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders(headers),
+		payloadHash,
+	}, "\n")
+
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		s.scope(now),
+		hex.EncodeToString(hashed[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func signedHeaders(headers map[string]string) string {
+	return strings.Join(sortedKeys(headers), ";")
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// canonicalQuery encodes the query sorted by key, with every name and value
+// escaped as SigV4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything but the RFC 3986 unreserved characters, and
+// slashes too when encodeSlash is set.
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
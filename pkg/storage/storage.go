@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned by Open when no object is stored under the key.
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps file contents under keys chosen by the caller, such as
+// customers/12/6f1c.... Put takes the size of body, or -1 when it is not
+// known before body is read to the end.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Object describes a stored file as returned by List.
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// Lister is implemented by backends that can enumerate the objects stored
+// under a key prefix, which retention needs to find expired files.
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// URLSigner is implemented by backends that can hand out expiring download
+// links of their own, so the file does not pass through the API.
+type URLSigner interface {
+	SignedURL(ctx context.Context, key string, fileName string, expiry time.Duration) (string, error)
+}
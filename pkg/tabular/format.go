@@ -0,0 +1,156 @@
+package tabular
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is a file format rows are imported from and exported to.
+type Format string
+
+const (
+	FormatXLSX   Format = "xlsx"
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+var contentTypes = map[Format]string{
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatCSV:    "text/csv",
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// ContentTypes lists the media types of the formats, xlsx first.
+func ContentTypes() []string {
+	return []string{
+		contentTypes[FormatXLSX],
+		contentTypes[FormatCSV],
+		contentTypes[FormatJSON],
+		contentTypes[FormatNDJSON],
+	}
+}
+
+// FormatOf returns the format of a media type, xlsx when it is none of
+// ContentTypes.
+func FormatOf(contentType string) Format {
+	for format, formatType := range contentTypes {
+		if formatType == contentType {
+			return format
+		}
+	}
+	return FormatXLSX
+}
+
+// Encodings are the character encodings of CSV files, by name. Files are
+// UTF-8 by default; utf-8-bom starts them with a byte order mark, which
+// Excel needs to open UTF-8 files correctly.
+var Encodings = map[string]encoding.Encoding{
+	"utf-8":        unicode.UTF8,
+	"utf-8-bom":    unicode.UTF8BOM,
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"windows-1252": charmap.Windows1252,
+	"iso-8859-1":   charmap.ISO8859_1,
+}
+
+var delimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+	"pipe":      '|',
+}
+
+// Options say how rows are written to or read from a file. Delimiter and
+// Encoding only apply to CSV; the fields after them only to exports and
+// are set by ParseLayout.
+type Options struct {
+	Format Format
+	// Delimiter separates CSV fields. It is a comma when writing and, when
+	// zero, guessed from the header line when reading.
+	Delimiter rune
+	// Encoding names one of Encodings; empty means UTF-8.
+	Encoding string
+	// Columns lists the keys of the exported columns in order; empty
+	// exports all of them.
+	Columns []string
+	// Sheet names the worksheet of workbooks instead of the schema.
+	Sheet string
+	// Location is the time zone timestamps are written in, UTC when nil.
+	Location *time.Location
+	// DateFormat lays out dates written as text, such as dd/mm/yyyy, and
+	// is the number format of the real dates workbooks store; empty uses
+	// the locale's.
+	DateFormat string
+	// Locale picks the default DateFormat and the decimal separator of
+	// text; empty writes yyyy-mm-dd and a dot, and lets workbooks show
+	// dates in the reader's regional format.
+	Locale string
+}
+
+// ParseOptions reads options from request parameters. format is a Format,
+// empty for xlsx. delimiter is a single character or one of comma,
+// semicolon, tab and pipe.
+func ParseOptions(format string, delimiter string, encodingName string) (Options, error) {
+	options := Options{Format: Format(strings.ToLower(format)), Encoding: strings.ToLower(encodingName)}
+	if options.Format == "" {
+		options.Format = FormatXLSX
+	}
+	if _, ok := contentTypes[options.Format]; !ok {
+		return Options{}, fmt.Errorf("format '%s' is not supported, use xlsx, csv, json or ndjson", format)
+	}
+
+	if delimiter != "" {
+		if named, ok := delimiters[strings.ToLower(delimiter)]; ok {
+			options.Delimiter = named
+		} else if utf8.RuneCountInString(delimiter) == 1 {
+			options.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+		}
+		if options.Delimiter == 0 || options.Delimiter == '"' || options.Delimiter == '\r' || options.Delimiter == '\n' || options.Delimiter == utf8.RuneError {
+			return Options{}, fmt.Errorf("delimiter '%s' is not valid", delimiter)
+		}
+	}
+
+	if _, ok := Encodings[options.Encoding]; options.Encoding != "" && !ok {
+		return Options{}, fmt.Errorf("encoding '%s' is not supported, use utf-8, utf-8-bom, utf-16le, windows-1252 or iso-8859-1", encodingName)
+	}
+
+	return options, nil
+}
+
+// ContentType is the media type of files written with the options.
+func (options Options) ContentType() string {
+	if options.Format != FormatCSV {
+		return contentTypes[options.Format]
+	}
+
+	charset := "utf-8"
+	switch options.Encoding {
+	case "", "utf-8-bom":
+	default:
+		charset = options.Encoding
+	}
+	return contentTypes[FormatCSV] + "; charset=" + charset
+}
+
+// Extension is the file name extension of the format, without the dot.
+func (options Options) Extension() string {
+	return string(options.Format)
+}
+
+// FileName names a file of the format <name>_<timestamp>.<extension>.
+func (options Options) FileName(name string) string {
+	return fmt.Sprintf("%s_%s.%s", name, time.Now().Format("2006-01-02_150405"), options.Extension())
+}
+
+func (options Options) encoding() encoding.Encoding {
+	if enc, ok := Encodings[options.Encoding]; ok {
+		return enc
+	}
+	return unicode.UTF8
+}
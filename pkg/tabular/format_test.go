@@ -0,0 +1,72 @@
+package tabular
+
+import "testing"
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		format    string
+		delimiter string
+		encoding  string
+		want      Options
+	}{
+		{"", "", "", Options{Format: FormatXLSX}},
+		{"CSV", "", "", Options{Format: FormatCSV}},
+		{"csv", "semicolon", "UTF-8-BOM", Options{Format: FormatCSV, Delimiter: ';', Encoding: "utf-8-bom"}},
+		{"csv", "Tab", "", Options{Format: FormatCSV, Delimiter: '\t'}},
+		{"csv", "#", "windows-1252", Options{Format: FormatCSV, Delimiter: '#', Encoding: "windows-1252"}},
+		{"ndjson", "", "", Options{Format: FormatNDJSON}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseOptions(test.format, test.delimiter, test.encoding)
+		if err != nil || got.Format != test.want.Format || got.Delimiter != test.want.Delimiter || got.Encoding != test.want.Encoding {
+			t.Errorf("ParseOptions(%q, %q, %q) = %+v, %v; want %+v", test.format, test.delimiter, test.encoding, got, err, test.want)
+		}
+	}
+}
+
+func TestParseOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		format    string
+		delimiter string
+		encoding  string
+	}{
+		{"xls", "", ""},
+		{"csv", "::", ""},
+		{"csv", `"`, ""},
+		{"csv", "\n", ""},
+		{"csv", "", "utf-32"},
+	}
+
+	for _, test := range tests {
+		if got, err := ParseOptions(test.format, test.delimiter, test.encoding); err == nil {
+			t.Errorf("ParseOptions(%q, %q, %q) = %+v, want an error", test.format, test.delimiter, test.encoding, got)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	formats := []Format{FormatXLSX, FormatCSV, FormatJSON, FormatNDJSON}
+	for i, contentType := range ContentTypes() {
+		if got := FormatOf(contentType); got != formats[i] {
+			t.Errorf("FormatOf(%s) = %s, want %s", contentType, got, formats[i])
+		}
+	}
+	if got := FormatOf("text/html"); got != FormatXLSX {
+		t.Errorf("FormatOf(text/html) = %s, want xlsx", got)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"":             "text/csv; charset=utf-8",
+		"utf-8-bom":    "text/csv; charset=utf-8",
+		"windows-1252": "text/csv; charset=windows-1252",
+	}
+
+	for encoding, want := range tests {
+		if got := (Options{Format: FormatCSV, Encoding: encoding}).ContentType(); got != want {
+			t.Errorf("ContentType of CSV in %q = %s, want %s", encoding, got, want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package tabular
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded so the timezone parameter works on hosts without a zoneinfo
+	// database.
+	_ "time/tzdata"
+)
+
+// Date is a calendar day. Unlike a time.Time, which Column.Format returns
+// for timestamps, it has no time of day and is not moved to the export's
+// time zone.
+type Date time.Time
+
+// ParseDate reads a yyyy-mm-dd date.
+func ParseDate(value string) (Date, bool) {
+	day, err := time.Parse(isoDate, value)
+	return Date(day), err == nil
+}
+
+const (
+	isoDate = "2006-01-02"
+	// DefaultDateFormat lays out dates when neither a format nor a locale
+	// is given.
+	DefaultDateFormat = "yyyy-mm-dd"
+	// maxSheetName is the longest worksheet name Excel accepts.
+	maxSheetName = 31
+)
+
+// localeFormat is how a locale writes dates and decimals in text.
+type localeFormat struct {
+	dateFormat string
+	decimal    byte
+}
+
+// locales are the locales exports are written in, by lowercase tag.
+var locales = map[string]localeFormat{
+	"en-us": {dateFormat: "mm/dd/yyyy", decimal: '.'},
+	"en-gb": {dateFormat: "dd/mm/yyyy", decimal: '.'},
+	"id-id": {dateFormat: "dd/mm/yyyy", decimal: ','},
+	"de-de": {dateFormat: "dd.mm.yyyy", decimal: ','},
+	"fr-fr": {dateFormat: "dd/mm/yyyy", decimal: ','},
+	"nl-nl": {dateFormat: "dd-mm-yyyy", decimal: ','},
+	"ja-jp": {dateFormat: "yyyy/mm/dd", decimal: '.'},
+}
+
+// dateTokens translate the parts of a date format to Go layout elements,
+// longest first.
+var dateTokens = []struct{ token, layout string }{
+	{"yyyy", "2006"},
+	{"yy", "06"},
+	{"mm", "01"},
+	{"m", "1"},
+	{"dd", "02"},
+	{"d", "2"},
+}
+
+// ParseLayout adds to options how an export lays out its rows, read from
+// request parameters. columns is a comma separated list of column keys,
+// checked against the schema by Schema.Select. timezone is an IANA name
+// such as Asia/Jakarta, empty for UTC. dateFormat is built from yyyy, yy,
+// mm, m, dd and d separated by '-', '/', '.' or spaces. localeName is
+// one of en-US, en-GB, id-ID, de-DE, fr-FR, nl-NL and ja-JP. Both apply to
+// CSV and to the dates of xlsx, whose numbers are shown with the reader's
+// separators; JSON keeps ISO dates and plain numbers.
+func (options Options) ParseLayout(columns string, sheet string, timezone string, dateFormat string, localeName string) (Options, error) {
+	for _, key := range strings.Split(columns, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			options.Columns = append(options.Columns, key)
+		}
+	}
+
+	options.Sheet = strings.TrimSpace(sheet)
+	if len([]rune(options.Sheet)) > maxSheetName || strings.ContainsAny(options.Sheet, `[]:*?/\`) || strings.HasPrefix(options.Sheet, "'") || strings.HasSuffix(options.Sheet, "'") {
+		return Options{}, fmt.Errorf("sheet '%s' is not valid: use at most %d characters, none of []:*?/\\ and no leading or trailing apostrophe", sheet, maxSheetName)
+	}
+
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil || timezone == "Local" {
+			return Options{}, fmt.Errorf("timezone '%s' is not a known time zone", timezone)
+		}
+		options.Location = location
+	}
+
+	if (options.Format == FormatJSON || options.Format == FormatNDJSON) && (dateFormat != "" || localeName != "") {
+		return Options{}, fmt.Errorf("date_format and locale only apply to csv and xlsx, not %s", options.Format)
+	}
+
+	options.Locale = strings.ToLower(strings.ReplaceAll(localeName, "_", "-"))
+	if _, ok := locales[options.Locale]; options.Locale != "" && !ok {
+		return Options{}, fmt.Errorf("locale '%s' is not supported, use en-US, en-GB, id-ID, de-DE, fr-FR, nl-NL or ja-JP", localeName)
+	}
+
+	options.DateFormat = strings.ToLower(dateFormat)
+	if _, err := dateLayout(options.DateFormat); dateFormat != "" && err != nil {
+		return Options{}, err
+	}
+
+	return options, nil
+}
+
+// dateLayout translates a date format to a Go time layout.
+func dateLayout(format string) (string, error) {
+	var layout strings.Builder
+	dated := false
+	for rest := format; rest != ""; {
+		matched := false
+		for _, element := range dateTokens {
+			if strings.HasPrefix(rest, element.token) {
+				layout.WriteString(element.layout)
+				rest = rest[len(element.token):]
+				matched, dated = true, true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if !strings.ContainsRune("-/. ", rune(rest[0])) {
+			return "", fmt.Errorf("date format '%s' is not valid, build it from yyyy, yy, mm, m, dd and d separated by '-', '/', '.' or spaces", format)
+		}
+		layout.WriteByte(rest[0])
+		rest = rest[1:]
+	}
+	if !dated {
+		return "", fmt.Errorf("date format '%s' has no year, month or day", format)
+	}
+	return layout.String(), nil
+}
+
+// location is the time zone timestamps are written in.
+func (options Options) location() *time.Location {
+	if options.Location == nil {
+		return time.UTC
+	}
+	return options.Location
+}
+
+// locale returns the locale of the options, which writes decimals with a
+// dot when none is set.
+func (options Options) locale() localeFormat {
+	if format, ok := locales[options.Locale]; ok {
+		return format
+	}
+	return localeFormat{dateFormat: DefaultDateFormat, decimal: '.'}
+}
+
+// dateLayout is the Go layout dates are written in as text.
+func (options Options) dateLayout() string {
+	format := options.DateFormat
+	if format == "" {
+		format = options.locale().dateFormat
+	}
+	layout, err := dateLayout(format)
+	if err != nil {
+		return isoDate
+	}
+	return layout
+}
+
+// xlsxDateFormats returns the number format codes of the dates and
+// timestamps of workbooks, empty when neither a date format nor a locale is
+// set. Separators are escaped, or spreadsheets would show the reader's date
+// separator in place of '/'.
+func (options Options) xlsxDateFormats() (date string, instant string) {
+	format := options.DateFormat
+	if format == "" && options.Locale != "" {
+		format = options.locale().dateFormat
+	}
+	if format == "" {
+		return "", ""
+	}
+
+	var code strings.Builder
+	for _, r := range format {
+		if strings.ContainsRune("-/. ", r) {
+			code.WriteByte('\\')
+		}
+		code.WriteRune(r)
+	}
+	return code.String(), code.String() + `\ hh:mm:ss`
+}
+
+// text writes an exported value as text the way people read it: dates in
+// the date format, timestamps in the time zone followed by the time, and
+// decimals with the locale's separator.
+func (options Options) text(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		number := strconv.FormatFloat(value, 'f', -1, 64)
+		if decimal := options.locale().decimal; decimal != '.' {
+			number = strings.Replace(number, ".", string(decimal), 1)
+		}
+		return number
+	case time.Time:
+		return value.In(options.location()).Format(options.dateLayout() + " 15:04:05")
+	case Date:
+		return time.Time(value).Format(options.dateLayout())
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// machineValue writes dates and timestamps of JSON files in ISO 8601, the
+// timestamps in the time zone; other values are kept as they are.
+func (options Options) machineValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		return value.In(options.location()).Format(time.RFC3339)
+	case Date:
+		return time.Time(value).Format(isoDate)
+	default:
+		return value
+	}
+}
+
+// wallClock returns the date and time shown for a timestamp in the time
+// zone, as a UTC time; workbooks store dates without a zone.
+func (options Options) wallClock(value time.Time) time.Time {
+	local := value.In(options.location())
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+}
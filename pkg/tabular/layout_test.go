@@ -0,0 +1,127 @@
+package tabular
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLayout(t *testing.T) {
+	options, err := Options{Format: FormatCSV}.ParseLayout(" name, ,email ", " Leads ", "Asia/Jakarta", "DD/MM/YYYY", "en_GB")
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+
+	if want := []string{"name", "email"}; !reflect.DeepEqual(options.Columns, want) {
+		t.Errorf("Columns = %q, want %q", options.Columns, want)
+	}
+	if options.Sheet != "Leads" {
+		t.Errorf("Sheet = %q, want Leads", options.Sheet)
+	}
+	if options.Location == nil || options.Location.String() != "Asia/Jakarta" {
+		t.Errorf("Location = %v, want Asia/Jakarta", options.Location)
+	}
+	if options.DateFormat != "dd/mm/yyyy" || options.Locale != "en-gb" {
+		t.Errorf("DateFormat, Locale = %q, %q; want dd/mm/yyyy, en-gb", options.DateFormat, options.Locale)
+	}
+}
+
+func TestParseLayoutDefaults(t *testing.T) {
+	options, err := Options{Format: FormatXLSX}.ParseLayout("", "", "", "", "")
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	if options.Columns != nil || options.Sheet != "" || options.location() != time.UTC {
+		t.Errorf("options = %+v, want no columns, no sheet and UTC", options)
+	}
+	if got := options.text(Date(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC))); got != "2024-03-09" {
+		t.Errorf("default date = %s, want 2024-03-09", got)
+	}
+}
+
+func TestParseLayoutInvalid(t *testing.T) {
+	tests := []struct {
+		format     Format
+		sheet      string
+		timezone   string
+		dateFormat string
+		locale     string
+		message    string
+	}{
+		{FormatCSV, strings.Repeat("a", 32), "", "", "", "sheet"},
+		{FormatCSV, "Q1/Q2", "", "", "", "sheet"},
+		{FormatCSV, "'Leads'", "", "", "", "sheet"},
+		{FormatCSV, "", "Mars/Olympus", "", "", "timezone"},
+		{FormatCSV, "", "Local", "", "", "timezone"},
+		{FormatCSV, "", "", "", "pt-BR", "locale"},
+		{FormatCSV, "", "", "dd_mm_yyyy", "", "date format"},
+		{FormatCSV, "", "", "//", "", "date format"},
+		{FormatXLSX, "", "", "", "pt-BR", "locale"},
+		{FormatXLSX, "", "", "dd_mm_yyyy", "", "date format"},
+		{FormatJSON, "", "", "dd/mm/yyyy", "", "only apply to csv and xlsx"},
+		{FormatNDJSON, "", "", "", "de-DE", "only apply to csv and xlsx"},
+	}
+
+	for _, test := range tests {
+		_, err := Options{Format: test.format}.ParseLayout("", test.sheet, test.timezone, test.dateFormat, test.locale)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("ParseLayout of %s with sheet %q, timezone %q, date format %q and locale %q returned %v, want an error about %s",
+				test.format, test.sheet, test.timezone, test.dateFormat, test.locale, err, test.message)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	day := Date(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC))
+	instant := time.Date(2024, 3, 9, 20, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		dateFormat string
+		locale     string
+		want       string
+	}{
+		{"", "", "2024-03-09|2024-03-10 03:15:00|1234.5"},
+		{"", "en-US", "03/09/2024|03/10/2024 03:15:00|1234.5"},
+		{"", "id-ID", "09/03/2024|10/03/2024 03:15:00|1234,5"},
+		{"d.m.yy", "ja-JP", "9.3.24|10.3.24 03:15:00|1234.5"},
+	}
+
+	for _, test := range tests {
+		options, err := Options{Format: FormatCSV}.ParseLayout("", "", "Asia/Jakarta", test.dateFormat, test.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := options.text(day) + "|" + options.text(instant) + "|" + options.text(1234.5)
+		if got != test.want {
+			t.Errorf("text with date format %q and locale %q = %s, want %s", test.dateFormat, test.locale, got, test.want)
+		}
+	}
+}
+
+func TestXLSXDateFormats(t *testing.T) {
+	tests := []struct {
+		dateFormat string
+		locale     string
+		date       string
+		instant    string
+	}{
+		{"", "", "", ""},
+		{"", "de-DE", `dd\.mm\.yyyy`, `dd\.mm\.yyyy\ hh:mm:ss`},
+		{"", "en-US", `mm\/dd\/yyyy`, `mm\/dd\/yyyy\ hh:mm:ss`},
+		{"d m yy", "en-US", `d\ m\ yy`, `d\ m\ yy\ hh:mm:ss`},
+	}
+
+	for _, test := range tests {
+		options, err := Options{Format: FormatXLSX}.ParseLayout("", "", "", test.dateFormat, test.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		date, instant := options.xlsxDateFormats()
+		if date != test.date || instant != test.instant {
+			t.Errorf("xlsx formats with date format %q and locale %q = %q, %q; want %q, %q", test.dateFormat, test.locale, date, instant, test.date, test.instant)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"strings"
+	"time"
+)
+
+// sniffLength is how much of a file is looked at to detect its format.
+const sniffLength = 3072
+
+// Table is a file read as text: a header row followed by the data rows.
+// Rows may be shorter than the header.
+type Table struct {
+	// Name is the name of the sheet a workbook was read from, empty for
+	// the other formats.
+	Name string
+	Rows [][]string
+}
+
+// Value returns the text of the cell at index of row, or "" when the row is
+// shorter than that.
+func Value(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
+// Read reads the rows of an uploaded file. The format is detected from the
+// content: an xlsx workbook, of which the first sheet is read, a JSON array
+// of objects or a stream of objects (NDJSON), or a CSV file. The header row
+// of JSON is the keys of the objects in the order they first appear.
+func Read(src io.ReaderAt, size int64, options Options) (Table, error) {
+	head := make([]byte, sniffLength)
+	n, err := src.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return Table{}, err
+	}
+	head = head[:n]
+
+	format, err := sniff(head, options)
+	if err != nil {
+		return Table{}, err
+	}
+
+	body := io.NewSectionReader(src, 0, size)
+	switch format {
+	case FormatXLSX:
+		return readXLSX(src, size)
+	case FormatJSON:
+		return readJSON(transform.NewReader(body, unicode.BOMOverride(transform.Nop)))
+	default:
+		return readCSV(transform.NewReader(body, unicode.BOMOverride(options.encoding().NewDecoder())), options.Delimiter)
+	}
+}
+
+// sniff detects the format of a file from its first bytes. Text that is not
+// JSON is read as CSV; other binary content is only accepted as CSV when an
+// encoding was given for it.
+func sniff(head []byte, options Options) (Format, error) {
+	detected := mimetype.Detect(head)
+	for mime := detected; mime != nil; mime = mime.Parent() {
+		switch {
+		case mime.Is(contentTypes[FormatXLSX]), mime.Is("application/zip"):
+			return FormatXLSX, nil
+		case mime.Is(contentTypes[FormatJSON]), mime.Is(contentTypes[FormatNDJSON]):
+			return FormatJSON, nil
+		case mime.Is("text/plain"):
+			return FormatCSV, nil
+		}
+	}
+
+	if options.Encoding != "" {
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("file type %s is not supported, upload xlsx, csv, json or ndjson", detected.String())
+}
+
+func readXLSX(src io.ReaderAt, size int64) (Table, error) {
+	file, err := xlsx.OpenReaderAt(src, size)
+	if err != nil {
+		return Table{}, err
+	}
+	if len(file.Sheets) == 0 {
+		return Table{}, errors.New("file has no sheet")
+	}
+
+	sheet := file.Sheets[0]
+	table := Table{Name: sheet.Name, Rows: make([][]string, 0, len(sheet.Rows))}
+	for _, row := range sheet.Rows {
+		values := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			values[i] = xlsxText(cell, file.Date1904)
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, nil
+}
+
+// xlsxText returns the text of a cell. Dates are written yyyy-mm-dd, with
+// the time after them when there is one, whatever format the cell shows
+// them in, so exported workbooks import again.
+func xlsxText(cell *xlsx.Cell, date1904 bool) string {
+	if cell.IsTime() {
+		if value, err := cell.GetTime(date1904); err == nil {
+			value = value.Round(time.Second)
+			if value.Equal(value.Truncate(24 * time.Hour)) {
+				return value.Format("2006-01-02")
+			}
+			return value.Format("2006-01-02 15:04:05")
+		}
+	}
+	return cell.String()
+}
+
+func readCSV(body io.Reader, delimiter rune) (Table, error) {
+	buffered := bufio.NewReader(body)
+	if delimiter == 0 {
+		delimiter = guessDelimiter(buffered)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	return Table{Rows: rows}, nil
+}
+
+// guessDelimiter picks the delimiter found most often in the header line,
+// a comma when there is none.
+func guessDelimiter(body *bufio.Reader) rune {
+	head, _ := body.Peek(sniffLength)
+	if end := bytes.IndexByte(head, '\n'); end >= 0 {
+		head = head[:end]
+	}
+
+	best, count := ',', 0
+	for _, delimiter := range []rune{',', ';', '\t', '|'} {
+		if n := strings.Count(string(head), string(delimiter)); n > count {
+			best, count = delimiter, n
+		}
+	}
+	return best
+}
+
+func readJSON(body io.Reader) (Table, error) {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return Table{}, nil
+	}
+	if err != nil {
+		return Table{}, err
+	}
+
+	table := Table{Rows: [][]string{nil}}
+	columns := make(map[string]int)
+	record := 0
+	add := func(opened bool) error {
+		record++
+		fields, err := readObject(decoder, opened)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", record, err)
+		}
+
+		row := make([]string, len(table.Rows[0]))
+		for _, field := range fields {
+			index, ok := columns[field.key]
+			if !ok {
+				index = len(table.Rows[0])
+				columns[field.key] = index
+				table.Rows[0] = append(table.Rows[0], field.key)
+				row = append(row, "")
+			}
+			row[index] = field.value
+		}
+		table.Rows = append(table.Rows, row)
+		return nil
+	}
+
+	// An array holds the records; otherwise the file is a stream of them,
+	// of which the first has been opened.
+	if token == json.Delim('[') {
+		for decoder.More() {
+			if err := add(false); err != nil {
+				return Table{}, err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return Table{}, err
+		}
+		return table, nil
+	}
+
+	if token != json.Delim('{') {
+		return Table{}, errors.New("file must hold a JSON array of objects or one object per line")
+	}
+	if err := add(true); err != nil {
+		return Table{}, err
+	}
+	for decoder.More() {
+		if err := add(false); err != nil {
+			return Table{}, err
+		}
+	}
+	return table, nil
+}
+
+type jsonField struct {
+	key   string
+	value string
+}
+
+// readObject reads the fields of the next object in order, its opening
+// brace already read when opened. Strings are unquoted, null is empty and
+// other values keep their JSON text.
+func readObject(decoder *json.Decoder, opened bool) ([]jsonField, error) {
+	if !opened {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != json.Delim('{') {
+			return nil, errors.New("record is not an object")
+		}
+	}
+
+	var fields []jsonField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		field := jsonField{key: key}
+		switch {
+		case string(raw) == "null":
+		case raw[0] == '"':
+			if err := json.Unmarshal(raw, &field.value); err != nil {
+				return nil, err
+			}
+		default:
+			compacted := bytes.Buffer{}
+			if err := json.Compact(&compacted, raw); err != nil {
+				return nil, err
+			}
+			field.value = compacted.String()
+		}
+		fields = append(fields, field)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
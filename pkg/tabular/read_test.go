@@ -0,0 +1,106 @@
+package tabular
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readString(t *testing.T, content string, options Options) Table {
+	t.Helper()
+	table, err := Read(strings.NewReader(content), int64(len(content)), options)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return table
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := map[string]string{
+		"comma":     "username,email\nann,ann@example.com\nbob\n",
+		"semicolon": "username;email\nann;ann@example.com\nbob\n",
+		"bom":       "\xef\xbb\xbfusername,email\r\nann,ann@example.com\r\nbob\r\n",
+	}
+	want := [][]string{{"username", "email"}, {"ann", "ann@example.com"}, {"bob"}}
+
+	for name, content := range tests {
+		if got := readString(t, content, Options{}).Rows; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: rows = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestReadCSVEncoding(t *testing.T) {
+	content := "username\nJos\xe9\n"
+	table := readString(t, content, Options{Encoding: "windows-1252"})
+	if got := Value(table.Rows[1], 0); got != "José" {
+		t.Errorf("value = %q, want José", got)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	// Rows only reach the columns known when they were read.
+	want := [][]string{
+		{"username", "age", "tags", "email"},
+		{"ann", "31", `["vip","b2b"]`},
+		{"bob", "", "", "bob@example.com"},
+	}
+
+	array := `[{"username":"ann","age":31,"tags":["vip", "b2b"]},{"username":"bob","age":null,"email":"bob@example.com"}]`
+	if got := readString(t, array, Options{}).Rows; !reflect.DeepEqual(got, want) {
+		t.Errorf("array rows = %q, want %q", got, want)
+	}
+
+	lines := "{\"username\":\"ann\",\"age\":31,\"tags\":[\"vip\",\"b2b\"]}\n{\"username\":\"bob\",\"email\":\"bob@example.com\"}\n"
+	if got := readString(t, lines, Options{}).Rows; !reflect.DeepEqual(got, want) {
+		t.Errorf("NDJSON rows = %q, want %q", got, want)
+	}
+}
+
+func TestReadJSONInvalid(t *testing.T) {
+	for _, content := range []string{`[1, 2]`, `["ann"]`, `[{"username": "ann"}, 3]`} {
+		if table, err := Read(strings.NewReader(content), int64(len(content)), Options{}); err == nil {
+			t.Errorf("Read(%s) = %q, want an error", content, table.Rows)
+		}
+	}
+}
+
+func TestReadUnsupported(t *testing.T) {
+	content := "%PDF-1.7\n\x00\x01\x02"
+	if _, err := Read(strings.NewReader(content), int64(len(content)), Options{}); err == nil {
+		t.Error("Read of a PDF succeeded")
+	}
+}
+
+func TestReadWrittenXLSX(t *testing.T) {
+	options, err := Options{Format: FormatXLSX}.ParseLayout("", "People", "Asia/Jakarta", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := writeRows(t, options)
+
+	table, err := Read(bytes.NewReader([]byte(content)), int64(len(content)), Options{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	want := [][]string{
+		{"Name", "Score", "Birthday", "Created At"},
+		{"Ann", "1.5", "1990-02-03", "2024-01-03 06:30:00"},
+		{"Bob, Jr.", "2", "", "2024-01-03 15:00:00"},
+	}
+	if table.Name != "People" {
+		t.Errorf("sheet = %s, want People", table.Name)
+	}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows = %q, want %q", table.Rows, want)
+	}
+}
+
+func TestValue(t *testing.T) {
+	row := []string{"a", "b"}
+	if Value(row, 1) != "b" || Value(row, 2) != "" || Value(row, -1) != "" {
+		t.Errorf("Value of %q out of range is not empty", row)
+	}
+}
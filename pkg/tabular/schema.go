@@ -0,0 +1,250 @@
+package tabular
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Column maps one field of T to a column of imported and exported files.
+type Column[T any] struct {
+	// Key names the column in validation errors and requests.
+	Key string
+	// Header is the column's title in the header row.
+	Header string
+	// Aliases are other headers partners use for the column.
+	Aliases []string
+	// Validate holds the go-playground/validator tags the imported text
+	// must pass, e.g. "required,email".
+	Validate string
+	// Unique columns may not repeat a value within a file or match a
+	// stored row.
+	Unique bool
+	// Optional columns may be left out of a file.
+	Optional bool
+	// Description explains the column in import templates.
+	Description string
+	// Example is the value of the import template's example row.
+	Example string
+	// Options lists the accepted values of an enumerated column; import
+	// templates offer them as a dropdown.
+	Options []string
+	// Parse stores the imported text in row. It is not called for empty
+	// text and columns without it are export only.
+	Parse func(ctx context.Context, value string, row *T) error
+	// Format returns the exported value: a string, int, float64, bool,
+	// time.Time for timestamps, Date or nil. Columns without it are import
+	// only.
+	Format func(row T) interface{}
+}
+
+// Schema lists the columns of a resource's files in order.
+type Schema[T any] struct {
+	// Sheet names the worksheet of exported workbooks.
+	Sheet   string
+	Columns []Column[T]
+}
+
+// With returns a copy of the schema with columns appended.
+func (schema Schema[T]) With(columns ...Column[T]) Schema[T] {
+	schema.Columns = append(append([]Column[T]{}, schema.Columns...), columns...)
+	return schema
+}
+
+// Imported returns the columns read on import.
+func (schema Schema[T]) Imported() []Column[T] {
+	var columns []Column[T]
+	for _, column := range schema.Columns {
+		if column.Parse != nil {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Exported returns the columns written on export.
+func (schema Schema[T]) Exported() []Column[T] {
+	var columns []Column[T]
+	for _, column := range schema.Columns {
+		if column.Format != nil {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Select returns the exported columns with keys, in the order of keys, or
+// all of Exported when keys is empty.
+func (schema Schema[T]) Select(keys []string) ([]Column[T], error) {
+	exported := schema.Exported()
+	if len(keys) == 0 {
+		return exported, nil
+	}
+
+	columns := make([]Column[T], 0, len(keys))
+	selected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if selected[key] {
+			return nil, fmt.Errorf("column '%s' is selected more than once", key)
+		}
+		selected[key] = true
+
+		found := false
+		for _, column := range exported {
+			if column.Key == key {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(exported))
+			for i, column := range exported {
+				available[i] = column.Key
+			}
+			return nil, fmt.Errorf("column '%s' is not exported, use %s", key, strings.Join(available, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// Required reports whether imported rows must have a value in the column.
+func (column Column[T]) Required() bool {
+	for _, tag := range strings.Split(column.Validate, ",") {
+		if tag == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules describes in words what the importer accepts in the column, for the
+// notes of import templates.
+func (column Column[T]) Rules() string {
+	var rules []string
+	if column.Description != "" {
+		rules = append(rules, column.Description)
+	}
+	if column.Required() {
+		rules = append(rules, "A value is required.")
+	} else {
+		rules = append(rules, "May be left empty.")
+	}
+	for _, tag := range strings.Split(column.Validate, ",") {
+		name, param, _ := strings.Cut(tag, "=")
+		switch name {
+		case "", "required":
+		case "email":
+			rules = append(rules, "Must be an email address.")
+		case "oneof":
+			rules = append(rules, fmt.Sprintf("Must be one of: %s.", strings.Join(strings.Fields(param), ", ")))
+		case "min":
+			rules = append(rules, fmt.Sprintf("Must be at least %s characters long.", param))
+		case "max":
+			rules = append(rules, fmt.Sprintf("Must be at most %s characters long.", param))
+		default:
+			rules = append(rules, fmt.Sprintf("Must pass the %s check.", tag))
+		}
+	}
+	if len(column.Options) > 0 {
+		rules = append(rules, fmt.Sprintf("Must be one of: %s.", strings.Join(column.Options, ", ")))
+	}
+	if column.Unique {
+		rules = append(rules, "Must not repeat within the file.")
+	}
+	if column.Optional {
+		rules = append(rules, "The column may be left out of the file.")
+	}
+	return strings.Join(rules, " ")
+}
+
+// Matches reports whether a header cell names the column by its header, key
+// or one of its aliases, compared with NormalizeHeader.
+func (column Column[T]) Matches(header string) bool {
+	header = NormalizeHeader(header)
+	if header == NormalizeHeader(column.Header) || header == NormalizeHeader(column.Key) {
+		return true
+	}
+	for _, alias := range column.Aliases {
+		if header == NormalizeHeader(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeHeader folds case and drops spaces and punctuation, so "E-mail",
+// "email" and "EMAIL " are the same header.
+func NormalizeHeader(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+}
+
+// CheckMapping reports a mapping that assigns a header to a column the
+// schema does not import. mapping goes from file header to column key; an
+// empty key ignores the header.
+func (schema Schema[T]) CheckMapping(mapping map[string]string) error {
+	keys := make(map[string]bool)
+	for _, column := range schema.Imported() {
+		keys[column.Key] = true
+	}
+
+	for header, key := range mapping {
+		if key != "" && !keys[key] {
+			return fmt.Errorf("mapping of '%s' names unknown column '%s'", header, key)
+		}
+	}
+	return nil
+}
+
+// Locate finds the import columns in a header row and returns the cell
+// index of each column of Imported, -1 when the file does not have it.
+// Headers in mapping are assigned to their column first; the others are
+// matched against the columns' headers, keys and aliases. A required column
+// the file lacks is an error.
+func (schema Schema[T]) Locate(headers []string, mapping map[string]string) ([]int, error) {
+	if err := schema.CheckMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	mapped := make(map[string]string, len(mapping))
+	for header, key := range mapping {
+		mapped[NormalizeHeader(header)] = key
+	}
+
+	columns := schema.Imported()
+	positions := make([]int, len(columns))
+	for i := range positions {
+		positions[i] = -1
+	}
+
+	for index, header := range headers {
+		key, isMapped := mapped[NormalizeHeader(header)]
+		for i, column := range columns {
+			if positions[i] >= 0 {
+				continue
+			}
+			if (isMapped && column.Key == key) || (!isMapped && column.Matches(header)) {
+				positions[i] = index
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for i, column := range columns {
+		if !column.Optional && positions[i] < 0 {
+			missing = append(missing, column.Header)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("file is missing the columns %s", strings.Join(missing, ", "))
+	}
+
+	return positions, nil
+}
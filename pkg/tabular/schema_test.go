@@ -0,0 +1,152 @@
+package tabular
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type contact struct {
+	Name  string
+	Email string
+	Tier  string
+}
+
+var contactSchema = Schema[contact]{
+	Sheet: "Contacts",
+	Columns: []Column[contact]{
+		{
+			Key: "id", Header: "ID",
+			Format: func(row contact) interface{} { return 1 },
+		},
+		{
+			Key: "name", Header: "Name", Validate: "required,max=125",
+			Parse:  func(ctx context.Context, value string, row *contact) error { row.Name = value; return nil },
+			Format: func(row contact) interface{} { return row.Name },
+		},
+		{
+			Key: "email", Header: "Email", Aliases: []string{"E-mail Address"}, Validate: "required,email", Unique: true,
+			Parse:  func(ctx context.Context, value string, row *contact) error { row.Email = value; return nil },
+			Format: func(row contact) interface{} { return row.Email },
+		},
+		{
+			Key: "tier", Header: "Tier", Optional: true, Options: []string{"gold", "silver"},
+			Parse: func(ctx context.Context, value string, row *contact) error { row.Tier = value; return nil },
+		},
+	},
+}
+
+func keys(columns []Column[contact]) []string {
+	var result []string
+	for _, column := range columns {
+		result = append(result, column.Key)
+	}
+	return result
+}
+
+func TestSchemaColumns(t *testing.T) {
+	if got, want := keys(contactSchema.Imported()), []string{"name", "email", "tier"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Imported = %q, want %q", got, want)
+	}
+	if got, want := keys(contactSchema.Exported()), []string{"id", "name", "email"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Exported = %q, want %q", got, want)
+	}
+
+	extended := contactSchema.With(Column[contact]{Key: "note", Format: func(row contact) interface{} { return "" }})
+	if len(extended.Columns) != 5 || len(contactSchema.Columns) != 4 {
+		t.Errorf("With changed the original schema or dropped the column")
+	}
+}
+
+func TestSchemaSelect(t *testing.T) {
+	columns, err := contactSchema.Select([]string{"email", "id"})
+	if err != nil || !reflect.DeepEqual(keys(columns), []string{"email", "id"}) {
+		t.Errorf("Select = %q, %v; want email, id", keys(columns), err)
+	}
+
+	columns, err = contactSchema.Select(nil)
+	if err != nil || len(columns) != 3 {
+		t.Errorf("Select of nothing = %q, %v; want every exported column", keys(columns), err)
+	}
+
+	for _, selection := range [][]string{{"name", "name"}, {"tier"}, {"password"}} {
+		if _, err := contactSchema.Select(selection); err == nil {
+			t.Errorf("Select(%q) did not fail", selection)
+		}
+	}
+}
+
+func TestColumnRules(t *testing.T) {
+	tests := map[string][]string{
+		"name":  {"A value is required.", "Must be at most 125 characters long."},
+		"email": {"A value is required.", "Must be an email address.", "Must not repeat within the file."},
+		"tier":  {"May be left empty.", "Must be one of: gold, silver.", "The column may be left out of the file."},
+	}
+
+	for _, column := range contactSchema.Imported() {
+		rules := column.Rules()
+		for _, rule := range tests[column.Key] {
+			if !strings.Contains(rules, rule) {
+				t.Errorf("rules of %s = %q, missing %q", column.Key, rules, rule)
+			}
+		}
+	}
+}
+
+func TestSchemaLocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		mapping map[string]string
+		want    []int
+	}{
+		{"headers", []string{"Name", "Email", "Tier"}, nil, []int{0, 1, 2}},
+		{"keys, aliases and noise", []string{"e-mail address", "Notes", " NAME "}, nil, []int{2, 0, -1}},
+		{"mapped headers", []string{"Full name", "Contact", "Email"}, map[string]string{"full name": "name", "CONTACT": "email", "Email": ""}, []int{0, 1, -1}},
+		{"first match wins", []string{"Email", "Name", "E-mail"}, nil, []int{1, 0, -1}},
+	}
+
+	for _, test := range tests {
+		positions, err := contactSchema.Locate(test.headers, test.mapping)
+		if err != nil || !reflect.DeepEqual(positions, test.want) {
+			t.Errorf("%s: Locate = %v, %v; want %v", test.name, positions, err, test.want)
+		}
+	}
+}
+
+func TestSchemaLocateRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		mapping map[string]string
+		message string
+	}{
+		{"missing column", []string{"Name", "Tier"}, nil, "missing the columns Email"},
+		{"ignored required column", []string{"Name", "Email"}, map[string]string{"Email": ""}, "missing the columns Email"},
+		{"unknown key", []string{"Name", "Email"}, map[string]string{"Name": "nickname"}, "unknown column 'nickname'"},
+		{"export only key", []string{"Name", "Email"}, map[string]string{"Name": "id"}, "unknown column 'id'"},
+	}
+
+	for _, test := range tests {
+		_, err := contactSchema.Locate(test.headers, test.mapping)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: Locate returned %v, want %q", test.name, err, test.message)
+		}
+	}
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	tests := map[string]string{
+		"E-mail":           "email",
+		" Phone Number ":   "phonenumber",
+		"custom_field (1)": "customfield1",
+		"Straße":           "straße",
+	}
+
+	for header, want := range tests {
+		if got := NormalizeHeader(header); got != want {
+			t.Errorf("NormalizeHeader(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/tealeg/xlsx"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Kinds of the cells of a streamed worksheet.
+const (
+	xlsxNumber = "n"
+	xlsxBool   = "b"
+	xlsxString = "inlineStr"
+)
+
+// Styles of the cells of a streamed worksheet, the indexes of their xf in
+// the style sheet.
+const (
+	xlsxHeaderStyle = iota + 1
+	xlsxTextStyle
+	xlsxNumberStyle
+	xlsxDateStyle
+	xlsxInstantStyle
+)
+
+// Number formats of dates and timestamps: the built-in short ones, which
+// spreadsheets show in the reader's regional settings, and the first ids
+// left for custom formats.
+const (
+	builtinDateFormat    = 14
+	builtinInstantFormat = 22
+	customDateFormat     = 164
+	customInstantFormat  = 165
+)
+
+// xlsxCell is a cell of a streamed row: its value as stored in the sheet,
+// its kind and its style.
+type xlsxCell struct {
+	value string
+	kind  string
+	style int
+}
+
+// xlsxStream writes a workbook of a single worksheet straight to w. The
+// package parts are written up front and the worksheet row by row, with its
+// strings inline, so only the row being written is held in memory. Unlike
+// the stream writer of tealeg/xlsx it can declare custom number formats.
+type xlsxStream struct {
+	zip    *zip.Writer
+	sheet  io.Writer
+	rows   int
+	buffer bytes.Buffer
+}
+
+// newXLSXStream starts a workbook whose worksheet, named sheetName, has
+// columns columns. dateFormat and instantFormat are the number format codes
+// of dates and timestamps, empty for the built-in short formats.
+func newXLSXStream(w io.Writer, sheetName string, columns int, dateFormat string, instantFormat string) (*xlsxStream, error) {
+	stream := &xlsxStream{zip: zip.NewWriter(w)}
+
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheetName))
+
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles(dateFormat, instantFormat)},
+	}
+	for _, part := range parts {
+		if err := stream.writePart(part.path, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := stream.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	stream.sheet = sheet
+
+	header := xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`
+	if columns > 0 {
+		header += fmt.Sprintf(`<cols><col min="1" max="%d" width="11" customWidth="1"/></cols>`, columns)
+	}
+	if _, err := io.WriteString(sheet, header+`<sheetData>`); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (stream *xlsxStream) writePart(path string, content string) error {
+	part, err := stream.zip.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// writeRow appends a row to the worksheet.
+func (stream *xlsxStream) writeRow(cells []xlsxCell) error {
+	stream.rows++
+	buffer := &stream.buffer
+	buffer.Reset()
+
+	buffer.WriteString(`<row r="` + strconv.Itoa(stream.rows) + `">`)
+	for i, cell := range cells {
+		ref := xlsx.GetCellIDStringFromCoords(i, stream.rows-1)
+		fmt.Fprintf(buffer, `<c r="%s" s="%d" t="%s">`, ref, cell.style, cell.kind)
+		if cell.kind == xlsxString {
+			buffer.WriteString(`<is><t xml:space="preserve">`)
+			xml.EscapeText(buffer, []byte(cell.value))
+			buffer.WriteString(`</t></is>`)
+		} else {
+			buffer.WriteString(`<v>` + cell.value + `</v>`)
+		}
+		buffer.WriteString(`</c>`)
+	}
+	buffer.WriteString(`</row>`)
+
+	_, err := stream.sheet.Write(buffer.Bytes())
+	return err
+}
+
+// flush pushes the compressed rows written so far out to w.
+func (stream *xlsxStream) flush() error {
+	return stream.zip.Flush()
+}
+
+// close completes the worksheet and the archive.
+func (stream *xlsxStream) close() error {
+	if _, err := io.WriteString(stream.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return stream.zip.Close()
+}
+
+// xlsxStyles is the style sheet of a streamed workbook. The header is set
+// in 12pt Calibri on yellow, like HeaderStyle, and the other cells in 12pt
+// Calibri.
+func xlsxStyles(dateFormat string, instantFormat string) string {
+	dateId, instantId := builtinDateFormat, builtinInstantFormat
+	var numFmts []string
+	if dateFormat != "" {
+		dateId = customDateFormat
+		numFmts = append(numFmts, xlsxNumFmt(dateId, dateFormat))
+	}
+	if instantFormat != "" {
+		instantId = customInstantFormat
+		numFmts = append(numFmts, xlsxNumFmt(instantId, instantFormat))
+	}
+
+	styles := xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`
+	if len(numFmts) > 0 {
+		styles += fmt.Sprintf(`<numFmts count="%d">%s</numFmts>`, len(numFmts), strings.Join(numFmts, ""))
+	}
+	styles += `<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><sz val="12"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="00FFFF00"/><bgColor rgb="00FFFF00"/></patternFill></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="6">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="49" fontId="1" fillId="2" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1" applyFill="1"/>` +
+		`<xf numFmtId="49" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		fmt.Sprintf(`<xf numFmtId="%d" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>`, dateId) +
+		fmt.Sprintf(`<xf numFmtId="%d" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>`, instantId) +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+	return styles
+}
+
+func xlsxNumFmt(id int, code string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(code))
+	return fmt.Sprintf(`<numFmt numFmtId="%d" formatCode="%s"/>`, id, escaped.String())
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
@@ -0,0 +1,261 @@
+package tabular
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/transform"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer writes rows to a file, one value per column. Values are those
+// returned by Column.Format. Rows are written through to the underlying
+// writer as the format allows; Flush pushes out what is buffered and Close
+// completes the file.
+type Writer interface {
+	Write(values []interface{}) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter starts a file in the format of options and writes its header:
+// the headers for xlsx and CSV, which name the columns, and nothing for
+// JSON and NDJSON, whose objects are keyed by keys. sheet names the
+// worksheet of xlsx files unless options name another.
+func NewWriter(w io.Writer, options Options, sheet string, keys []string, headers []string) (Writer, error) {
+	switch options.Format {
+	case FormatCSV:
+		return newCSVWriter(w, options, headers)
+	case FormatJSON, FormatNDJSON:
+		return newJSONWriter(w, options, keys), nil
+	default:
+		if options.Sheet != "" {
+			sheet = options.Sheet
+		}
+		return newXLSXWriter(w, options, sheet, headers)
+	}
+}
+
+// flusher is implemented by writers that buffer, such as HTTP responses.
+type flusher interface {
+	Flush()
+}
+
+func flush(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
+
+// HeaderStyle returns the style of the header row of workbooks.
+func HeaderStyle() *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Font = *xlsx.NewFont(12, "Calibri")
+	style.Fill = *xlsx.NewFill("solid", "00FFFF00", "00FFFF00") // Yellow background
+	return style
+}
+
+// xlsxWriter streams the sheet into the zip archive row by row, so only the
+// row being written is held in memory.
+type xlsxWriter struct {
+	w       io.Writer
+	options Options
+	stream  *xlsxStream
+	cells   []xlsxCell
+}
+
+// Dates use the date format of the options, or of their locale, and the
+// built-in short date formats, shown in the reader's regional settings,
+// without either.
+func newXLSXWriter(w io.Writer, options Options, sheetName string, headers []string) (*xlsxWriter, error) {
+	dateFormat, instantFormat := options.xlsxDateFormats()
+	stream, err := newXLSXStream(w, sheetName, len(headers), dateFormat, instantFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]xlsxCell, len(headers))
+	for i, title := range headers {
+		cells[i] = xlsxCell{value: title, kind: xlsxString, style: xlsxHeaderStyle}
+	}
+	if err := stream.writeRow(cells); err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{w: w, options: options, stream: stream}, nil
+}
+
+func (writer *xlsxWriter) Write(values []interface{}) error {
+	writer.cells = writer.cells[:0]
+	for _, value := range values {
+		writer.cells = append(writer.cells, writer.cell(value))
+	}
+	return writer.stream.writeRow(writer.cells)
+}
+
+// cell stores an exported value with the matching cell type.
+func (writer *xlsxWriter) cell(value interface{}) xlsxCell {
+	switch value := value.(type) {
+	case int:
+		return xlsxCell{value: strconv.Itoa(value), kind: xlsxNumber, style: xlsxNumberStyle}
+	case float64:
+		return xlsxCell{value: strconv.FormatFloat(value, 'f', -1, 64), kind: xlsxNumber, style: xlsxNumberStyle}
+	case bool:
+		flag := "0"
+		if value {
+			flag = "1"
+		}
+		return xlsxCell{value: flag, kind: xlsxBool, style: xlsxTextStyle}
+	case time.Time:
+		return writer.serial(writer.options.wallClock(value), xlsxInstantStyle)
+	case Date:
+		return writer.serial(time.Time(value), xlsxDateStyle)
+	default:
+		return xlsxCell{value: writer.options.text(value), kind: xlsxString, style: xlsxTextStyle}
+	}
+}
+
+// serial stores a date as the number of days since the workbook's epoch,
+// which is what spreadsheets sort and filter by.
+func (writer *xlsxWriter) serial(value time.Time, style int) xlsxCell {
+	days := xlsx.TimeToExcelTime(value, false)
+	return xlsxCell{value: strconv.FormatFloat(days, 'f', -1, 64), kind: xlsxNumber, style: style}
+}
+
+func (writer *xlsxWriter) Flush() error {
+	if err := writer.stream.flush(); err != nil {
+		return err
+	}
+	flush(writer.w)
+	return nil
+}
+
+func (writer *xlsxWriter) Close() error {
+	if err := writer.stream.close(); err != nil {
+		return err
+	}
+	flush(writer.w)
+	return nil
+}
+
+type csvWriter struct {
+	w       io.Writer
+	options Options
+	encoded io.WriteCloser
+	csv     *csv.Writer
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, options Options, headers []string) (*csvWriter, error) {
+	encoded := transform.NewWriter(w, options.encoding().NewEncoder())
+	writer := csv.NewWriter(encoded)
+	if options.Delimiter != 0 {
+		writer.Comma = options.Delimiter
+	}
+
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: w, options: options, encoded: encoded, csv: writer}, nil
+}
+
+func (writer *csvWriter) Write(values []interface{}) error {
+	writer.record = writer.record[:0]
+	for _, value := range values {
+		writer.record = append(writer.record, writer.options.text(value))
+	}
+	return writer.csv.Write(writer.record)
+}
+
+func (writer *csvWriter) Flush() error {
+	writer.csv.Flush()
+	if err := writer.csv.Error(); err != nil {
+		return err
+	}
+	flush(writer.w)
+	return nil
+}
+
+func (writer *csvWriter) Close() error {
+	writer.csv.Flush()
+	if err := writer.csv.Error(); err != nil {
+		return err
+	}
+	if err := writer.encoded.Close(); err != nil {
+		return err
+	}
+	flush(writer.w)
+	return nil
+}
+
+type jsonWriter struct {
+	out     io.Writer
+	w       *bufio.Writer
+	options Options
+	lines   bool
+	keys    [][]byte
+	written bool
+}
+
+func newJSONWriter(w io.Writer, options Options, keys []string) *jsonWriter {
+	writer := &jsonWriter{out: w, w: bufio.NewWriter(w), options: options, lines: options.Format == FormatNDJSON}
+	for _, key := range keys {
+		encoded, _ := json.Marshal(key)
+		writer.keys = append(writer.keys, encoded)
+	}
+	return writer
+}
+
+// Write writes one object with the values in column order, which a map
+// would not keep.
+func (writer *jsonWriter) Write(values []interface{}) error {
+	switch {
+	case writer.lines:
+	case writer.written:
+		writer.w.WriteString(",\n")
+	default:
+		writer.w.WriteString("[\n")
+	}
+	writer.written = true
+
+	writer.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			writer.w.WriteByte(',')
+		}
+		encoded, err := json.Marshal(writer.options.machineValue(value))
+		if err != nil {
+			return err
+		}
+		writer.w.Write(writer.keys[i])
+		writer.w.WriteByte(':')
+		writer.w.Write(encoded)
+	}
+	writer.w.WriteByte('}')
+
+	if writer.lines {
+		writer.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (writer *jsonWriter) Flush() error {
+	if err := writer.w.Flush(); err != nil {
+		return err
+	}
+	flush(writer.out)
+	return nil
+}
+
+func (writer *jsonWriter) Close() error {
+	switch {
+	case writer.lines:
+	case writer.written:
+		writer.w.WriteString("\n]\n")
+	default:
+		writer.w.WriteString("[]\n")
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,170 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportRows are the values of a name, score, birthday and created_at
+// column, as Column.Format returns them.
+var exportRows = [][]interface{}{
+	{"Ann", 1.5, Date(time.Date(1990, 2, 3, 0, 0, 0, 0, time.UTC)), time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC)},
+	{"Bob, Jr.", 2, nil, time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC)},
+}
+
+func writeRows(t *testing.T, options Options) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, options, "Customers", []string{"name", "score", "birthday", "created_at"}, []string{"Name", "Score", "Birthday", "Created At"})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range exportRows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buffer.String()
+}
+
+func TestWriteCSV(t *testing.T) {
+	got := writeRows(t, Options{Format: FormatCSV})
+	want := "Name,Score,Birthday,Created At\n" +
+		"Ann,1.5,1990-02-03,2024-01-02 23:30:00\n" +
+		"\"Bob, Jr.\",2,,2024-01-03 08:00:00\n"
+	if got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCSVLayout(t *testing.T) {
+	options, err := Options{Format: FormatCSV, Delimiter: ';'}.ParseLayout("", "", "Asia/Jakarta", "", "de-DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := writeRows(t, options)
+	want := "Name;Score;Birthday;Created At\n" +
+		"Ann;1,5;03.02.1990;03.01.2024 06:30:00\n" +
+		"Bob, Jr.;2;;03.01.2024 15:00:00\n"
+	if got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCSVEncoding(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, Options{Format: FormatCSV, Encoding: "windows-1252"}, "", []string{"name"}, []string{"Name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write([]interface{}{"José"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buffer.Bytes(), []byte("Name\nJos\xe9\n"); !bytes.Equal(got, want) {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	options, err := Options{Format: FormatJSON}.ParseLayout("", "", "Asia/Jakarta", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := writeRows(t, options)
+	want := "[\n" +
+		`{"name":"Ann","score":1.5,"birthday":"1990-02-03","created_at":"2024-01-03T06:30:00+07:00"},` + "\n" +
+		`{"name":"Bob, Jr.","score":2,"birthday":null,"created_at":"2024-01-03T15:00:00+07:00"}` + "\n" +
+		"]\n"
+	if got != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	got := writeRows(t, Options{Format: FormatNDJSON})
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != len(exportRows) || !strings.HasPrefix(lines[0], `{"name":"Ann",`) {
+		t.Errorf("NDJSON =\n%s", got)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newJSONWriter(&buffer, Options{Format: FormatJSON}, nil)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buffer.String(); got != "[]\n" {
+		t.Errorf("empty JSON = %q, want \"[]\\n\"", got)
+	}
+}
+
+// zipPart returns the content of the part at path of an xlsx file.
+func zipPart(t *testing.T, content string, path string) string {
+	t.Helper()
+	archive, err := zip.NewReader(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("reading xlsx: %v", err)
+	}
+	part, err := archive.Open(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer part.Close()
+	data, _ := io.ReadAll(part)
+	return string(data)
+}
+
+func TestWriteXLSXDateFormats(t *testing.T) {
+	options, err := Options{Format: FormatXLSX}.ParseLayout("", "", "", "", "de-DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := writeRows(t, options)
+
+	styles := zipPart(t, content, "xl/styles.xml")
+	for _, numFmt := range []string{
+		`<numFmt numFmtId="164" formatCode="dd\.mm\.yyyy"/>`,
+		`<numFmt numFmtId="165" formatCode="dd\.mm\.yyyy\ hh:mm:ss"/>`,
+		`<xf numFmtId="164" `,
+		`<xf numFmtId="165" `,
+	} {
+		if !strings.Contains(styles, numFmt) {
+			t.Errorf("styles have no %s", numFmt)
+		}
+	}
+
+	sheet := zipPart(t, content, "xl/worksheets/sheet1.xml")
+	if !strings.Contains(sheet, `<c r="C2" s="4" t="n"><v>32907</v></c>`) {
+		t.Errorf("birthday is not a date serial in the date style: %s", sheet)
+	}
+
+	// The dates still read back as dates.
+	table, err := Read(strings.NewReader(content), int64(len(content)), Options{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := table.Rows[1][2] + "|" + table.Rows[1][3]; got != "1990-02-03|2024-01-02 23:30:00" {
+		t.Errorf("read dates %s, want them in ISO form", got)
+	}
+}
+
+func TestWriteXLSXBuiltinDateFormats(t *testing.T) {
+	styles := zipPart(t, writeRows(t, Options{Format: FormatXLSX}), "xl/styles.xml")
+	if strings.Contains(styles, "<numFmts") || !strings.Contains(styles, `<xf numFmtId="14" `) || !strings.Contains(styles, `<xf numFmtId="22" `) {
+		t.Errorf("styles without a date format or locale %s, want the built-in short dates", styles)
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type actorKey struct{}
+
+// WithActor copies the authenticated user set by JwtMiddleware onto a
+// service context so audit records can name who made a change.
+func WithActor(parent context.Context, ctx *gin.Context) context.Context {
+	return context.WithValue(parent, actorKey{}, ctx.GetString("currentUser"))
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -1,8 +1,18 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
 	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/tabular"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func ResponseInterceptor(ctx *gin.Context, resp *entity.Response) {
@@ -13,3 +23,110 @@ func ResponseInterceptor(ctx *gin.Context, resp *entity.Response) {
 	}
 	resp.TraceID = traceId
 }
+
+func SetETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", fmt.Sprintf("\"%d\"", version))
+}
+
+// ParseIfMatch reads the version carried by the If-Match header. Weak tags
+// (W/"3") are accepted since the version is the only thing compared.
+func ParseIfMatch(ctx *gin.Context) int {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		panic(exception.NewPreconditionRequiredHandler("If-Match header is required"))
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), "\"")
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		panic(exception.NewPreconditionFailedHandler("If-Match header is not a valid entity tag"))
+	}
+	return version
+}
+
+// ExportOptions reads how an export is written: in the format query
+// parameter when set, otherwise in the format the Accept header prefers,
+// xlsx when it names none of them, and with the columns, time zone and
+// date layout of the request.
+func ExportOptions(ctx *gin.Context, request entity.ExportRequest) tabular.Options {
+	format := request.Format
+	if format == "" {
+		format = string(tabular.FormatOf(ctx.NegotiateFormat(tabular.ContentTypes()...)))
+	}
+
+	options, err := tabular.ParseOptions(format, request.Delimiter, request.Encoding)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	options, err = options.ParseLayout(request.Columns, request.Sheet, request.Timezone, request.DateFormat, request.Locale)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	return options
+}
+
+// StreamExport sends a file named <name>_<timestamp> in the format of
+// options, written by export straight to the response. Without a length the
+// response uses chunked transfer encoding. An error or panic before the
+// first byte is sent is handled as usual; once the body has started, the
+// connection is closed instead so the client sees an incomplete transfer
+// rather than a truncated file.
+func StreamExport(ctx *gin.Context, options tabular.Options, name string, export func(w io.Writer) error) {
+	ctx.Header("Content-Type", options.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", options.FileName(name)))
+
+	defer func() {
+		if r := recover(); r != nil {
+			if !ctx.Writer.Written() {
+				ctx.Writer.Header().Del("Content-Type")
+				ctx.Writer.Header().Del("Content-Disposition")
+				panic(r)
+			}
+			log.Printf("export: %s stopped: %v", name, r)
+			abortStream(ctx)
+		}
+	}()
+
+	if err := export(ctx.Writer); err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+var requestTimeout = config.DefaultRequestTimeout
+
+// RequestTimeout is how long a handler lets the services work on a request.
+func RequestTimeout() time.Duration {
+	return requestTimeout
+}
+
+// SetRequestTimeout changes RequestTimeout; zero keeps the default.
+func SetRequestTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		requestTimeout = timeout
+	}
+}
+
+// LiftDeadlines removes the server's read and write timeouts from the
+// current request, so files of any size can be uploaded or downloaded.
+func LiftDeadlines(ctx *gin.Context) {
+	controller := http.NewResponseController(ctx.Writer)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+}
+
+// abortStream drops the connection of a response whose body has started.
+func abortStream(ctx *gin.Context) {
+	ctx.Abort()
+	// gin's writer panics when the connection cannot be hijacked, as with
+	// HTTP/2; the response controller returns an error instead.
+	writer := http.ResponseWriter(ctx.Writer)
+	if unwrapper, ok := writer.(interface{ Unwrap() http.ResponseWriter }); ok {
+		writer = unwrapper.Unwrap()
+	}
+	conn, _, err := http.NewResponseController(writer).Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scylla/pkg/exception"
+	"scylla/pkg/tabular"
+	"strings"
+	"testing"
+)
+
+func ifMatchContext(header string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/customers/1", nil)
+	if header != "" {
+		ctx.Request.Header.Set("If-Match", header)
+	}
+	return ctx
+}
+
+// parseIfMatch returns the version ParseIfMatch read, or the exception it
+// raised.
+func parseIfMatch(header string) (version int, raised interface{}) {
+	defer func() {
+		raised = recover()
+	}()
+	return ParseIfMatch(ifMatchContext(header)), nil
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := map[string]int{
+		`"3"`:     3,
+		`W/"3"`:   3,
+		`  "12" `: 12,
+		`7`:       7,
+	}
+
+	for header, want := range tests {
+		got, raised := parseIfMatch(header)
+		if raised != nil || got != want {
+			t.Errorf("ParseIfMatch(%s) = %d, %v; want %d", header, got, raised, want)
+		}
+	}
+}
+
+func TestParseIfMatchMissing(t *testing.T) {
+	_, raised := parseIfMatch("")
+	if _, ok := raised.(*exception.PreconditionRequiredErrorStruct); !ok {
+		t.Errorf("ParseIfMatch without a header raised %#v, want a precondition required error", raised)
+	}
+}
+
+func TestParseIfMatchInvalid(t *testing.T) {
+	for _, header := range []string{`"abc"`, `"0"`, `"-2"`, `*`, `"1", "2"`} {
+		_, raised := parseIfMatch(header)
+		if _, ok := raised.(*exception.PreconditionFailedErrorStruct); !ok {
+			t.Errorf("ParseIfMatch(%s) raised %#v, want a precondition failed error", header, raised)
+		}
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	ctx := ifMatchContext("")
+	SetETag(ctx, 4)
+
+	header := ctx.Writer.Header().Get("ETag")
+	if header != `"4"` {
+		t.Fatalf("ETag = %s, want \"4\"", header)
+	}
+	if got, raised := parseIfMatch(header); raised != nil || got != 4 {
+		t.Errorf("ParseIfMatch of the ETag = %d, %v; want 4", got, raised)
+	}
+}
+
+func streamExport(ctx *gin.Context, export func(w io.Writer) error) (raised interface{}) {
+	defer func() {
+		raised = recover()
+	}()
+	StreamExport(ctx, tabular.Options{Format: tabular.FormatCSV}, "customers", export)
+	return nil
+}
+
+func TestStreamExport(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	raised := streamExport(ctx, func(w io.Writer) error {
+		_, err := io.WriteString(w, "ID\n1\n")
+		return err
+	})
+	if raised != nil {
+		t.Fatalf("StreamExport raised %#v", raised)
+	}
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %s, want text/csv", got)
+	}
+	if got := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename=customers_") || !strings.HasSuffix(got, ".csv") {
+		t.Errorf("Content-Disposition = %s", got)
+	}
+	if recorder.Body.String() != "ID\n1\n" {
+		t.Errorf("body %q", recorder.Body.String())
+	}
+}
+
+func TestStreamExportFailsBeforeBody(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	raised := streamExport(ctx, func(w io.Writer) error {
+		return errors.New("query failed")
+	})
+	if _, ok := raised.(*exception.InternalServerErrorStruct); !ok {
+		t.Fatalf("StreamExport raised %#v, want an internal server error", raised)
+	}
+	if header := ctx.Writer.Header(); header.Get("Content-Type") != "" || header.Get("Content-Disposition") != "" {
+		t.Errorf("file headers %v left on the error response", header)
+	}
+}
+
+func TestStreamExportFailsAfterBody(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	raised := streamExport(ctx, func(w io.Writer) error {
+		io.WriteString(w, "ID\n1\n")
+		return errors.New("cursor closed")
+	})
+	if raised != nil {
+		t.Errorf("StreamExport raised %#v after the body started", raised)
+	}
+	if !ctx.IsAborted() {
+		t.Error("StreamExport did not abort the started response")
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"reflect"
@@ -11,6 +12,16 @@ import (
 
 var db *gorm.DB
 
+type skipUniqueKey struct{}
+
+// SkipUnique marks a validation context so the unique tag always passes, for
+// writes that resolve existing rows themselves such as upserts.
+func SkipUnique(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipUniqueKey{}, true)
+}
+
+var fieldKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
 func InitializeValidator(db *gorm.DB) *validator.Validate {
 	validate := validator.New()
 
@@ -66,10 +77,34 @@ func InitializeValidator(db *gorm.DB) *validator.Validate {
 		return true
 	})
 
-	_ = validate.RegisterValidation("unique", func(fl validator.FieldLevel) bool {
+	_ = validate.RegisterValidationCtx("unique", func(ctx context.Context, fl validator.FieldLevel) bool {
+		if skip, _ := ctx.Value(skipUniqueKey{}).(bool); skip {
+			return true
+		}
 		return helper.ValidateUnique(db, fl)
 	})
 
+	// phone accepts numbers that normalize to E.164, reading national
+	// numbers as belonging to helper.PhoneRegion; phone=GB names another
+	// region.
+	_ = validate.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		region := fl.Param()
+		if region == "" {
+			region = helper.PhoneRegion()
+		}
+		_, err := helper.NormalizePhone(fl.Field().String(), region)
+		return err == nil
+	})
+
+	_ = validate.RegisterValidation("fieldKey", func(fl validator.FieldLevel) bool {
+		return fieldKeyRegex.MatchString(fl.Field().String())
+	})
+
+	_ = validate.RegisterValidation("customerFilter", func(fl validator.FieldLevel) bool {
+		_, err := helper.ParseCustomerFilter(fl.Field().String())
+		return err == nil
+	})
+
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "" {
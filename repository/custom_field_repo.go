@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+)
+
+type CustomFieldRepo interface {
+	Insert(ctx context.Context, data model.CustomFieldDefinition) (model.CustomFieldDefinition, error)
+	Update(ctx context.Context, data model.CustomFieldDefinition) error
+	Delete(ctx context.Context, Id int) error
+	FindById(ctx context.Context, Id int) (data model.CustomFieldDefinition, err error)
+	FindAll(ctx context.Context) (domain []model.CustomFieldDefinition, err error)
+}
+
+type CustomFieldRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldRepoImpl(db *gorm.DB) CustomFieldRepo {
+	return &CustomFieldRepoImpl{db: db}
+}
+
+func (repo *CustomFieldRepoImpl) Insert(ctx context.Context, data model.CustomFieldDefinition) (model.CustomFieldDefinition, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *CustomFieldRepoImpl) Update(ctx context.Context, data model.CustomFieldDefinition) error {
+	result := repo.db.WithContext(ctx).
+		Select("label", "required", "options", "updated_at").
+		Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+// Delete removes the definition and strips its key from every customer so
+// no orphaned values are left behind.
+func (repo *CustomFieldRepoImpl) Delete(ctx context.Context, Id int) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var data model.CustomFieldDefinition
+		if err := tx.First(&data, Id).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec("UPDATE customers SET custom_fields = custom_fields - ?::text WHERE jsonb_exists(custom_fields, ?)", data.Key, data.Key).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&data).Error
+	})
+}
+
+func (repo *CustomFieldRepoImpl) FindById(ctx context.Context, Id int) (data model.CustomFieldDefinition, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *CustomFieldRepoImpl) FindAll(ctx context.Context) (domain []model.CustomFieldDefinition, err error) {
+	result := repo.db.WithContext(ctx).Order("id ASC").Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/entity"
+	"scylla/model"
+	"time"
+)
+
+// TimelineRef points at one timeline entry; Kind tells which table ID is from.
+type TimelineRef struct {
+	Kind       string
+	ID         int
+	OccurredAt time.Time
+}
+
+type CustomerActivityRepo interface {
+	Insert(ctx context.Context, data model.CustomerActivity) (model.CustomerActivity, error)
+	Update(ctx context.Context, data model.CustomerActivity) error
+	Delete(ctx context.Context, customerId int, Id int) error
+	FindById(ctx context.Context, customerId int, Id int) (data model.CustomerActivity, err error)
+	FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerActivity, err error)
+	FindByCustomerId(ctx context.Context, customerId int, dataFilter entity.CustomerActivityQueryFilter) (domain []model.CustomerActivity, total int64, err error)
+	FindAllByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerActivity, err error)
+	FindTimeline(ctx context.Context, customerId int, dataFilter entity.CustomerTimelineQueryFilter) (refs []TimelineRef, total int64, err error)
+}
+
+type CustomerActivityRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomerActivityRepoImpl(db *gorm.DB) CustomerActivityRepo {
+	return &CustomerActivityRepoImpl{db: db}
+}
+
+func (repo *CustomerActivityRepoImpl) Insert(ctx context.Context, data model.CustomerActivity) (model.CustomerActivity, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *CustomerActivityRepoImpl) Update(ctx context.Context, data model.CustomerActivity) error {
+	// Select all columns so the subject and due date can be cleared.
+	result := repo.db.WithContext(ctx).Model(&data).
+		Where("customer_id = ?", data.CustomerID).
+		Select("type", "subject", "body", "due_at", "updated_at").
+		Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *CustomerActivityRepoImpl) Delete(ctx context.Context, customerId int, Id int) error {
+	var data model.CustomerActivity
+	result := repo.db.WithContext(ctx).Where("id = ? AND customer_id = ?", Id, customerId).Delete(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *CustomerActivityRepoImpl) FindById(ctx context.Context, customerId int, Id int) (data model.CustomerActivity, err error) {
+	result := repo.db.WithContext(ctx).Where("customer_id = ?", customerId).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *CustomerActivityRepoImpl) FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerActivity, err error) {
+	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+func (repo *CustomerActivityRepoImpl) FindByCustomerId(ctx context.Context, customerId int, dataFilter entity.CustomerActivityQueryFilter) (domain []model.CustomerActivity, total int64, err error) {
+	db := repo.db.WithContext(ctx).Model(&model.CustomerActivity{}).Where("customer_id = ?", customerId)
+
+	if dataFilter.Type != "" {
+		db = db.Where("type = ?", dataFilter.Type)
+	}
+
+	order := "created_at DESC, id DESC"
+	if dataFilter.DueBefore != "" {
+		db = db.Where("due_at < ?::date", dataFilter.DueBefore)
+		order = "due_at ASC, id ASC"
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := db.Order(order).
+		Scopes(entity.Scopes(dataFilter.Page, dataFilter.Limit)).
+		Find(&domain)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return domain, total, nil
+}
+
+func (repo *CustomerActivityRepoImpl) FindAllByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerActivity, err error) {
+	result := repo.db.WithContext(ctx).
+		Where("customer_id = ?", customerId).
+		Order("created_at ASC, id ASC").
+		Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+// FindTimeline pages through the customer's activities and revisions
+// together, newest first. Only references are returned; the caller loads the
+// rows themselves.
+func (repo *CustomerActivityRepoImpl) FindTimeline(ctx context.Context, customerId int, dataFilter entity.CustomerTimelineQueryFilter) (refs []TimelineRef, total int64, err error) {
+	timeline := `
+		SELECT ?::text AS kind, id, created_at AS occurred_at FROM customer_activities WHERE customer_id = ?
+		UNION ALL
+		SELECT ?::text AS kind, id, created_at AS occurred_at FROM customer_revisions WHERE customer_id = ?
+	`
+	args := []interface{}{entity.TimelineActivity, customerId, entity.TimelineChange, customerId}
+
+	err = repo.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+timeline+") timeline", args...).Scan(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (dataFilter.Page - 1) * dataFilter.Limit
+	err = repo.db.WithContext(ctx).
+		Raw("SELECT kind, id, occurred_at FROM ("+timeline+") timeline ORDER BY occurred_at DESC, kind, id DESC LIMIT ? OFFSET ?", append(args, dataFilter.Limit, offset)...).
+		Scan(&refs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return refs, total, nil
+}
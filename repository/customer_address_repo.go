@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+)
+
+type CustomerAddressRepo interface {
+	Insert(ctx context.Context, data model.CustomerAddress) (model.CustomerAddress, error)
+	Update(ctx context.Context, data model.CustomerAddress) error
+	Delete(ctx context.Context, customerId int, Id int) error
+	FindById(ctx context.Context, customerId int, Id int) (data model.CustomerAddress, err error)
+	FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerAddress, err error)
+}
+
+type CustomerAddressRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomerAddressRepoImpl(db *gorm.DB) CustomerAddressRepo {
+	return &CustomerAddressRepoImpl{db: db}
+}
+
+func (repo *CustomerAddressRepoImpl) Insert(ctx context.Context, data model.CustomerAddress) (model.CustomerAddress, error) {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if data.IsDefault {
+			if err := clearDefaultAddress(tx, data); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&data).Error
+	})
+	if err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+func (repo *CustomerAddressRepoImpl) Update(ctx context.Context, data model.CustomerAddress) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if data.IsDefault {
+			if err := clearDefaultAddress(tx, data); err != nil {
+				return err
+			}
+		}
+
+		// Select all columns so optional fields and is_default can be cleared.
+		result := tx.Model(&data).
+			Where("customer_id = ?", data.CustomerID).
+			Select("type", "line1", "line2", "city", "region", "postal_code", "country", "is_default", "updated_at").
+			Updates(&data)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("record not found")
+		}
+		return nil
+	})
+}
+
+func (repo *CustomerAddressRepoImpl) Delete(ctx context.Context, customerId int, Id int) error {
+	var data model.CustomerAddress
+	result := repo.db.WithContext(ctx).Where("id = ? AND customer_id = ?", Id, customerId).Delete(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *CustomerAddressRepoImpl) FindById(ctx context.Context, customerId int, Id int) (data model.CustomerAddress, err error) {
+	result := repo.db.WithContext(ctx).Where("customer_id = ?", customerId).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *CustomerAddressRepoImpl) FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerAddress, err error) {
+	result := repo.db.WithContext(ctx).
+		Where("customer_id = ?", customerId).
+		Order("is_default DESC, id ASC").
+		Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+// clearDefaultAddress drops the default flag from the customer's other
+// addresses of the same type so the partial unique index is not violated.
+func clearDefaultAddress(tx *gorm.DB, data model.CustomerAddress) error {
+	return tx.Model(&model.CustomerAddress{}).
+		Where("customer_id = ? AND type = ? AND id <> ? AND is_default", data.CustomerID, data.Type, data.ID).
+		Update("is_default", false).Error
+}
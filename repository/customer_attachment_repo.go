@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+)
+
+type CustomerAttachmentRepo interface {
+	Insert(ctx context.Context, data model.CustomerAttachment) (model.CustomerAttachment, error)
+	Delete(ctx context.Context, customerId int, Id int) error
+	FindById(ctx context.Context, Id int) (data model.CustomerAttachment, err error)
+	FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerAttachment, err error)
+}
+
+type CustomerAttachmentRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomerAttachmentRepoImpl(db *gorm.DB) CustomerAttachmentRepo {
+	return &CustomerAttachmentRepoImpl{db: db}
+}
+
+func (repo *CustomerAttachmentRepoImpl) Insert(ctx context.Context, data model.CustomerAttachment) (model.CustomerAttachment, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *CustomerAttachmentRepoImpl) Delete(ctx context.Context, customerId int, Id int) error {
+	var data model.CustomerAttachment
+	result := repo.db.WithContext(ctx).Where("id = ? AND customer_id = ?", Id, customerId).Delete(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *CustomerAttachmentRepoImpl) FindById(ctx context.Context, Id int) (data model.CustomerAttachment, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *CustomerAttachmentRepoImpl) FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerAttachment, err error) {
+	result := repo.db.WithContext(ctx).
+		Where("customer_id = ?", customerId).
+		Order("created_at DESC, id DESC").
+		Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
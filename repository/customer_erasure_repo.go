@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+)
+
+type CustomerErasureRepo interface {
+	Erase(ctx context.Context, customer model.Customer, receipt model.CustomerErasure) (model.CustomerErasure, error)
+	FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerErasure, err error)
+}
+
+type CustomerErasureRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomerErasureRepoImpl(db *gorm.DB) CustomerErasureRepo {
+	return &CustomerErasureRepoImpl{db: db}
+}
+
+// Erase overwrites the customer's personal data with the anonymized values in
+// customer, blanks its addresses, redacts the values in its history and
+// stores the receipt, all in one transaction. Ids, address countries, tags
+// and revision actions and timestamps are kept for statistics.
+func (repo *CustomerErasureRepoImpl) Erase(ctx context.Context, customer model.Customer, receipt model.CustomerErasure) (model.CustomerErasure, error) {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			UPDATE customers
+			SET username = ?, email = ?, phone = '', address = '', custom_fields = '{}'::jsonb,
+				version = version + 1, updated_at = now()
+			WHERE id = ?
+		`, customer.Username, customer.Email, customer.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("record not found")
+		}
+
+		err := tx.Exec(`
+			UPDATE customer_addresses
+			SET line1 = '', line2 = '', city = '', region = '', postal_code = '', updated_at = now()
+			WHERE customer_id = ?
+		`, customer.ID).Error
+		if err != nil {
+			return err
+		}
+
+		// Snapshots are dropped so an erased state cannot be reverted; the
+		// changed field names stay, their values do not.
+		err = tx.Exec(`
+			UPDATE customer_revisions
+			SET snapshot = NULL,
+				changes = COALESCE((
+					SELECT jsonb_object_agg(field, jsonb_build_object('old', '[erased]', 'new', '[erased]'))
+					FROM jsonb_object_keys(changes) AS field
+				), '{}'::jsonb)
+			WHERE customer_id = ? AND jsonb_typeof(changes) = 'object'
+		`, customer.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE customer_revisions SET snapshot = NULL WHERE customer_id = ?", customer.ID).Error
+		if err != nil {
+			return err
+		}
+
+		// Attachments such as ID scans are removed outright; their files are
+		// deleted by the caller once this commits.
+		err = tx.Exec("DELETE FROM customer_attachments WHERE customer_id = ?", customer.ID).Error
+		if err != nil {
+			return err
+		}
+
+		// Activities stay on the timeline with their type and dates only.
+		err = tx.Exec(`
+			UPDATE customer_activities
+			SET subject = '', body = '[erased]', updated_at = now()
+			WHERE customer_id = ?
+		`, customer.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&receipt).Error
+	})
+	if err != nil {
+		return receipt, err
+	}
+
+	return receipt, nil
+}
+
+func (repo *CustomerErasureRepoImpl) FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerErasure, err error) {
+	result := repo.db.WithContext(ctx).
+		Where("customer_id = ?", customerId).
+		Order("created_at ASC, id ASC").
+		Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
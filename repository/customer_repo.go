@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"scylla/entity"
 	"scylla/model"
@@ -11,16 +13,72 @@ import (
 	"strings"
 )
 
+// ErrVersionConflict is returned when a row was changed by someone else
+// between the read and the conditional write.
+var ErrVersionConflict = errors.New("record has been modified by another request")
+
+// ErrEmailTaken is returned when a write would give two customers the same
+// email, which the unique index on lower(email) rejects.
+var ErrEmailTaken = errors.New("email already taken")
+
+var errBatchRolledBack = errors.New("batch rolled back")
+
+// MergeResult lists the rows Merge moved from the duplicates to the
+// survivor.
+type MergeResult struct {
+	ActivityIDs   []int
+	AttachmentIDs []int
+}
+
+// legacyAddressType is the type of the structured address a new customer's
+// free-text address is seeded into, as the migration that created
+// customer_addresses carried the existing ones over.
+const legacyAddressType = "billing"
+
+// What Upsert did with each row.
+const (
+	UpsertInserted = "inserted"
+	UpsertUpdated  = "updated"
+	UpsertSkipped  = "skipped"
+)
+
+// tagFilter keeps customers carrying every one of the given tag names.
+const tagFilter = `id IN (
+	SELECT ct.customer_id
+	FROM customer_tags ct
+	JOIN tags t ON t.id = ct.tag_id
+	WHERE t.name IN (?)
+	GROUP BY ct.customer_id
+	HAVING COUNT(DISTINCT t.id) = ?
+)`
+
+// findAllBatchSize is how many rows FindAll collects per batch when reading
+// with FindAllInBatches.
+const findAllBatchSize = 1000
+
 type CustomerRepo interface {
-	Insert(ctx context.Context, data model.Customer) error
+	Insert(ctx context.Context, data model.Customer) (model.Customer, error)
 	InsertBatch(ctx context.Context, data []model.Customer, batchSize int) error
+	Upsert(ctx context.Context, data []model.Customer, onConflict string, batchSize int) ([]string, error)
 	Update(ctx context.Context, data model.Customer) error
-	DeleteBatch(ctx context.Context, Ids []int) error
+	UpdateBatch(ctx context.Context, data []model.Customer, atomic bool) ([]error, error)
+	Delete(ctx context.Context, Id int, version int) ([]string, error)
+	DeleteBatch(ctx context.Context, Ids []int) ([]string, error)
 	FindById(ctx context.Context, Id int) (data model.Customer, err error)
+	FindByIds(ctx context.Context, Ids []int) (domain []model.Customer, err error)
+	FindByEmails(ctx context.Context, emails []string) (domain []model.Customer, err error)
 	FindByColumns(ctx context.Context, columns []string, queries []any) (model.Customer, error)
 	FindAll(ctx context.Context, dataFilter entity.CustomerQueryFilter) (domain []entity.CustomerResponse, err error)
+	FindAllInBatches(ctx context.Context, dataFilter entity.CustomerQueryFilter, batchSize int, fn func(batch []entity.CustomerResponse) error) error
+	Count(ctx context.Context, dataFilter entity.CustomerQueryFilter) (total int64, err error)
 	FindAllPaging(ctx context.Context, dataFilter entity.CustomerQueryFilter) (domain []entity.CustomerResponse)
 	CheckColumnExists(ctx context.Context, column string, value interface{}) bool
+	FindDuplicates(ctx context.Context, dataFilter entity.CustomerDuplicateQueryFilter) (domain []entity.CustomerDuplicateResponse, total int64, err error)
+	Merge(ctx context.Context, survivor model.Customer, duplicateIds []int) (MergeResult, error)
+	Transition(ctx context.Context, Id int, from string, to string, version int) error
+	AssignOwner(ctx context.Context, Id int, ownerId *int, version int) error
+	ReassignOwner(ctx context.Context, fromOwnerId int, toOwnerId *int) ([]int, error)
+	WithTx(tx *gorm.DB) CustomerRepo
 }
 
 type CustomerRepoImpl struct {
@@ -31,58 +89,266 @@ func NewCustomerRepoImpl(db *gorm.DB) CustomerRepo {
 	return &CustomerRepoImpl{db: db}
 }
 
-func (repo *CustomerRepoImpl) Insert(ctx context.Context, data model.Customer) error {
-	result := repo.db.WithContext(ctx).Create(&data)
-	if result.Error != nil {
-		return result.Error
+// WithTx returns the repository working in tx, a transaction started by a
+// Transactor.
+func (repo *CustomerRepoImpl) WithTx(tx *gorm.DB) CustomerRepo {
+	return &CustomerRepoImpl{db: tx}
+}
+
+func (repo *CustomerRepoImpl) Insert(ctx context.Context, data model.Customer) (model.Customer, error) {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&data).Error; err != nil {
+			return emailConflict(err)
+		}
+		return seedDefaultAddress(tx, data.ID, data.Address)
+	})
+	if err != nil {
+		return data, err
 	}
-	return nil
+	return data, nil
 }
 
 func (repo *CustomerRepoImpl) InsertBatch(ctx context.Context, data []model.Customer, batchSize int) error {
-	tx := repo.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return tx.Error
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&data, batchSize).Error; err != nil {
+			return err
+		}
+		for _, customer := range data {
+			if err := seedDefaultAddress(tx, customer.ID, customer.Address); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Upsert inserts the customers with INSERT ... ON CONFLICT (email), batchSize
+// rows per statement, in one transaction. An existing email fails the batch
+// with ErrEmailTaken under entity.OnConflictError, keeps the stored row with OnConflictSkip and
+// overwrites it with OnConflictUpdate, merging custom fields. IDs and
+// versions are filled into data and the action taken on each row is returned
+// in input order. Emails must be unique within data.
+func (repo *CustomerRepoImpl) Upsert(ctx context.Context, data []model.Customer, onConflict string, batchSize int) ([]string, error) {
+	actions := make([]string, len(data))
+	if len(data) == 0 {
+		return actions, nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(data)
 	}
 
-	if err := tx.CreateInBatches(&data, batchSize).Error; err != nil {
-		tx.Rollback()
-		return err
+	var conflict string
+	switch onConflict {
+	case entity.OnConflictSkip:
+		conflict = "ON CONFLICT (email) DO NOTHING"
+	case entity.OnConflictUpdate:
+		conflict = `ON CONFLICT (email) DO UPDATE SET
+			username = EXCLUDED.username,
+			phone = EXCLUDED.phone,
+			address = EXCLUDED.address,
+			custom_fields = COALESCE(customers.custom_fields, '{}'::jsonb) || EXCLUDED.custom_fields,
+			owner_id = COALESCE(EXCLUDED.owner_id, customers.owner_id),
+			version = customers.version + 1,
+			updated_at = now()`
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return err
+	type returned struct {
+		ID       int
+		Email    string
+		Version  int
+		Inserted bool
 	}
 
-	return nil
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(data); start += batchSize {
+			end := start + batchSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			var values []string
+			var args []interface{}
+			positions := make(map[string]int, end-start)
+			for i := start; i < end; i++ {
+				customFields := "{}"
+				if data[i].CustomFields != nil {
+					customFields = helper.StructToJson(data[i].CustomFields)
+				}
+				values = append(values, "(?, ?, ?, ?, ?::jsonb, ?, ?, jsonb_build_object(?::text, now()), now())")
+				args = append(args, data[i].Username, data[i].Email, data[i].Phone, data[i].Address, customFields, data[i].OwnerID, data[i].Status, data[i].Status)
+				positions[data[i].Email] = i
+			}
+
+			// xmax is only zero on rows this statement inserted.
+			query := fmt.Sprintf(`
+				INSERT INTO customers (username, email, phone, address, custom_fields, owner_id, status, status_timestamps, updated_at)
+				VALUES %s
+				%s
+				RETURNING id, email, version, (xmax = 0) AS inserted
+			`, strings.Join(values, ", "), conflict)
+
+			var rows []returned
+			if err := tx.Raw(query, args...).Scan(&rows).Error; err != nil {
+				return emailConflict(err)
+			}
+
+			for _, row := range rows {
+				i := positions[row.Email]
+				data[i].ID = row.ID
+				data[i].Version = row.Version
+				actions[i] = UpsertUpdated
+				if row.Inserted {
+					actions[i] = UpsertInserted
+				}
+				delete(positions, row.Email)
+
+				if !row.Inserted {
+					continue
+				}
+				if err := seedDefaultAddress(tx, row.ID, data[i].Address); err != nil {
+					return err
+				}
+			}
+
+			// Whatever was not returned hit an existing email and was skipped.
+			if len(positions) > 0 {
+				var emails []string
+				for email := range positions {
+					emails = append(emails, email)
+				}
+
+				var existing []returned
+				err := tx.Raw("SELECT id, email, version FROM customers WHERE email IN (?)", emails).Scan(&existing).Error
+				if err != nil {
+					return err
+				}
+				for _, row := range existing {
+					i := positions[row.Email]
+					data[i].ID = row.ID
+					data[i].Version = row.Version
+					actions[i] = UpsertSkipped
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return actions, nil
 }
 
 func (repo *CustomerRepoImpl) Update(ctx context.Context, data model.Customer) error {
-	result := repo.db.WithContext(ctx).Updates(&data)
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateVersioned(tx, data)
+	})
+}
 
-	if result.RowsAffected == 0 {
-		return errors.New("record not found")
-	}
+// UpdateBatch writes the customers in one transaction and returns one error
+// per row, nil when the row was written. With atomic set the first failing
+// row rolls back the transaction; otherwise the failed row is undone through
+// a savepoint and the others commit. The second return value is only set
+// for failures not tied to a row.
+func (repo *CustomerRepoImpl) UpdateBatch(ctx context.Context, data []model.Customer, atomic bool) ([]error, error) {
+	errs := make([]error, len(data))
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range data {
+			savepoint := fmt.Sprintf("customer_%d", i)
+			if !atomic {
+				if err := tx.SavePoint(savepoint).Error; err != nil {
+					return err
+				}
+			}
 
-	if result.Error != nil {
-		return result.Error
+			errs[i] = updateVersioned(tx, data[i])
+			if errs[i] == nil {
+				continue
+			}
+			if atomic {
+				return errBatchRolledBack
+			}
+			if err := tx.RollbackTo(savepoint).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchRolledBack) {
+		return errs, nil
 	}
-	return nil
+
+	return errs, err
 }
 
-func (repo *CustomerRepoImpl) DeleteBatch(ctx context.Context, Ids []int) error {
-	var data model.Customer
-	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Delete(&data)
+// Delete removes the customer while it is still at version. Its
+// attachment rows go with it; the storage keys of their files are returned
+// so the caller can delete the files once the transaction has committed.
+func (repo *CustomerRepoImpl) Delete(ctx context.Context, Id int, version int) ([]string, error) {
+	var storageKeys []string
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		storageKeys, err = attachmentStorageKeys(tx, []int{Id})
+		if err != nil {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return errors.New("record not found")
+		var data model.Customer
+		result := tx.Where("id = ? AND version = ?", Id, version).Delete(&data)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if result.Error != nil {
-		helper.ErrorPanic(result.Error)
+	return storageKeys, nil
+}
+
+// DeleteBatch removes the customers and returns the storage keys of their
+// attachment files, like Delete.
+func (repo *CustomerRepoImpl) DeleteBatch(ctx context.Context, Ids []int) ([]string, error) {
+	var storageKeys []string
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		storageKeys, err = attachmentStorageKeys(tx, Ids)
+		if err != nil {
+			return err
+		}
+
+		var data model.Customer
+		result := tx.Where("id IN (?)", Ids).Delete(&data)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("record not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return storageKeys, nil
+}
+
+// attachmentStorageKeys returns the storage keys of the customers'
+// attachments, which are deleted with them by the foreign key cascade.
+func attachmentStorageKeys(tx *gorm.DB, customerIds []int) ([]string, error) {
+	var storageKeys []string
+	err := tx.Model(&model.CustomerAttachment{}).
+		Where("customer_id IN (?)", customerIds).
+		Pluck("storage_key", &storageKeys).Error
+	return storageKeys, err
 }
 
 func (repo *CustomerRepoImpl) FindById(ctx context.Context, Id int) (data model.Customer, err error) {
@@ -94,6 +360,28 @@ func (repo *CustomerRepoImpl) FindById(ctx context.Context, Id int) (data model.
 	return data, nil
 }
 
+func (repo *CustomerRepoImpl) FindByIds(ctx context.Context, Ids []int) (domain []model.Customer, err error) {
+	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+func (repo *CustomerRepoImpl) FindByEmails(ctx context.Context, emails []string) (domain []model.Customer, err error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	result := repo.db.WithContext(ctx).Where("email IN (?)", emails).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
 func (repo *CustomerRepoImpl) FindByColumns(ctx context.Context, columns []string, queries []any) (model.Customer, error) {
 	if len(columns) != len(queries) {
 		return model.Customer{}, errors.New("columns and queries length mismatch")
@@ -118,46 +406,123 @@ func (repo *CustomerRepoImpl) FindByColumns(ctx context.Context, columns []strin
 }
 
 func (repo *CustomerRepoImpl) FindAll(ctx context.Context, dataFilter entity.CustomerQueryFilter) (domain []entity.CustomerResponse, err error) {
-	query := "SELECT id, username, email, phone, address, created_at FROM customers"
-	args := []interface{}{}
-
-	if dataFilter.Username != "" {
-		query += " WHERE username = ?"
-		args = append(args, dataFilter.Username)
+	err = repo.FindAllInBatches(ctx, dataFilter, findAllBatchSize, func(batch []entity.CustomerResponse) error {
+		domain = append(domain, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return domain, nil
+}
 
-	if dataFilter.Email != "" {
-		query += " WHERE email = ?"
-		args = append(args, dataFilter.Email)
+// FindAllInBatches reads the customers FindAll returns from a cursor, in id
+// order, and calls fn with every batchSize of them, so callers can process
+// any number of customers without holding them all. An error from fn stops
+// the read and is returned.
+func (repo *CustomerRepoImpl) FindAllInBatches(ctx context.Context, dataFilter entity.CustomerQueryFilter, batchSize int, fn func(batch []entity.CustomerResponse) error) error {
+	if batchSize <= 0 {
+		batchSize = findAllBatchSize
 	}
 
-	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
-		query += " WHERE created_at BETWEEN ? AND ?"
-		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
-	}
+	where, args := customerFilters(dataFilter)
+	query := "SELECT id, username, email, phone, address, custom_fields, owner_id, status, status_timestamps, version, created_at FROM customers" + where + " ORDER BY id"
 
 	rows, err := repo.db.WithContext(ctx).Raw(query, args...).Rows()
 	if err != nil {
-		return nil, rows.Err()
+		return err
 	}
 	defer rows.Close()
 
+	batch := make([]entity.CustomerResponse, 0, batchSize)
 	for rows.Next() {
 		var customer entity.CustomerResponse
-		err := rows.Scan(&customer.ID, &customer.Username, &customer.Email, &customer.Phone, &customer.Address, &customer.CreatedAt)
+		var customFields, statusTimestamps []byte
+		err := rows.Scan(&customer.ID, &customer.Username, &customer.Email, &customer.Phone, &customer.Address, &customFields, &customer.OwnerID, &customer.Status, &statusTimestamps, &customer.Version, &customer.CreatedAt)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		json.Unmarshal(customFields, &customer.CustomFields)
+		json.Unmarshal(statusTimestamps, &customer.StatusTimestamps)
+		batch = append(batch, customer)
+
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]entity.CustomerResponse, 0, batchSize)
 		}
-		domain = append(domain, customer)
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return domain, nil
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// Count returns how many customers FindAll returns.
+func (repo *CustomerRepoImpl) Count(ctx context.Context, dataFilter entity.CustomerQueryFilter) (total int64, err error) {
+	where, args := customerFilters(dataFilter)
+	result := repo.db.WithContext(ctx).Raw("SELECT count(*) FROM customers"+where, args...).Scan(&total)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// customerFilters builds the WHERE clause of FindAll, which matches usernames
+// and emails exactly.
+func customerFilters(dataFilter entity.CustomerQueryFilter) (string, []interface{}) {
+	var filters []string
+	var args []interface{}
+
+	if dataFilter.Username != "" {
+		filters = append(filters, "username = ?")
+		args = append(args, dataFilter.Username)
+	}
+
+	if dataFilter.Email != "" {
+		filters = append(filters, "lower(email) = ?")
+		args = append(args, helper.NormalizeEmail(dataFilter.Email))
+	}
+
+	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
+		filters = append(filters, "created_at BETWEEN ? AND ?")
+		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
+	}
+
+	if tags := helper.SplitList(dataFilter.Tag); len(tags) > 0 {
+		filters = append(filters, tagFilter)
+		args = append(args, tags, len(tags))
+	}
+
+	if statuses := helper.SplitList(dataFilter.Status); len(statuses) > 0 {
+		filters = append(filters, "status IN (?)")
+		args = append(args, statuses)
+	}
+	if dataFilter.OwnerID != 0 {
+		filters = append(filters, "owner_id = ?")
+		args = append(args, dataFilter.OwnerID)
+	}
+
+	for key, value := range dataFilter.CustomFields {
+		filters = append(filters, "custom_fields ->> ? = ?")
+		args = append(args, key, value)
+	}
+
+	if len(filters) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(filters, " AND "), args
 }
 
 func (repo *CustomerRepoImpl) FindAllPaging(ctx context.Context, dataFilter entity.CustomerQueryFilter) (domain []entity.CustomerResponse) {
 	rawQuery := `
 		SELECT 
-			id, username, email, phone, address, created_at
+			id, username, email, phone, address, custom_fields, owner_id, status, status_timestamps, version, created_at
 		FROM 
 			customers
 	`
@@ -169,30 +534,39 @@ func (repo *CustomerRepoImpl) FindAllPaging(ctx context.Context, dataFilter enti
 		args = append(args, "%"+dataFilter.Username+"%")
 	}
 	if dataFilter.Email != "" {
-		filters = append(filters, "email LIKE ?")
-		args = append(args, "%"+dataFilter.Email+"%")
+		filters = append(filters, "lower(email) LIKE ?")
+		args = append(args, "%"+helper.NormalizeEmail(dataFilter.Email)+"%")
 	}
 	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
 		filters = append(filters, "created_at BETWEEN ? AND ?")
 		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
 	}
+	if tags := helper.SplitList(dataFilter.Tag); len(tags) > 0 {
+		filters = append(filters, tagFilter)
+		args = append(args, tags, len(tags))
+	}
+	if statuses := helper.SplitList(dataFilter.Status); len(statuses) > 0 {
+		filters = append(filters, "status IN (?)")
+		args = append(args, statuses)
+	}
+	if dataFilter.OwnerID != 0 {
+		filters = append(filters, "owner_id = ?")
+		args = append(args, dataFilter.OwnerID)
+	}
+	for key, value := range dataFilter.CustomFields {
+		filters = append(filters, "custom_fields ->> ? = ?")
+		args = append(args, key, value)
+	}
 
 	if len(filters) > 0 {
 		rawQuery += " WHERE " + strings.Join(filters, " AND ")
 	}
 
 	sortBy := "id DESC"
-	if dataFilter.Sort != "" {
-		var sortClauses []string
-		for _, row := range strings.Split(dataFilter.Sort, ",") {
-			colSort := strings.Split(row, ":")
-			if len(colSort) > 1 {
-				sortClauses = append(sortClauses, fmt.Sprintf("%s %s", colSort[0], colSort[1]))
-			}
-		}
-		if len(sortClauses) > 0 {
-			sortBy = strings.Join(sortClauses, ", ")
-		}
+	sortClauses, err := helper.ParseCustomerSort(dataFilter.Sort)
+	helper.ErrorPanic(err)
+	if len(sortClauses) > 0 {
+		sortBy = strings.Join(sortClauses, ", ")
 	}
 	rawQuery += " ORDER BY " + sortBy
 
@@ -216,3 +590,221 @@ func (repo *CustomerRepoImpl) CheckColumnExists(ctx context.Context, column stri
 	}
 	return exists
 }
+
+func (repo *CustomerRepoImpl) FindDuplicates(ctx context.Context, dataFilter entity.CustomerDuplicateQueryFilter) (domain []entity.CustomerDuplicateResponse, total int64, err error) {
+	// Each customer looks up its candidates through the lateral join, one
+	// branch per criterion so every branch can use its expression index
+	// from the trigram migration.
+	rawQuery := `
+		SELECT
+			*,
+			ROUND((CASE WHEN email_match THEN 0.5 ELSE 0 END
+				+ CASE WHEN phone_match THEN 0.3 ELSE 0 END
+				+ 0.2 * name_similarity)::numeric, 3)::float8 AS score
+		FROM (
+			SELECT
+				a.id AS customer_id, a.username AS customer_username, a.email AS customer_email,
+				b.id AS candidate_id, b.username AS candidate_username, b.email AS candidate_email,
+				lower(trim(a.email)) = lower(trim(b.email)) AS email_match,
+				(regexp_replace(a.phone, '\D', '', 'g') <> ''
+					AND regexp_replace(a.phone, '\D', '', 'g') = regexp_replace(b.phone, '\D', '', 'g')) AS phone_match,
+				similarity(lower(a.username), lower(b.username)) AS name_similarity
+			FROM
+				customers a
+				CROSS JOIN LATERAL (
+					SELECT c.id FROM customers c
+					WHERE lower(trim(c.email)) = lower(trim(a.email)) AND c.id > a.id
+					UNION
+					SELECT c.id FROM customers c
+					WHERE regexp_replace(c.phone, '\D', '', 'g') = regexp_replace(a.phone, '\D', '', 'g')
+						AND regexp_replace(a.phone, '\D', '', 'g') <> '' AND c.id > a.id
+					UNION
+					SELECT c.id FROM customers c
+					WHERE lower(c.username) % lower(a.username) AND c.id > a.id
+				) candidates
+				JOIN customers b ON b.id = candidates.id
+		) pairs
+	`
+	var args []interface{}
+
+	if dataFilter.MinScore > 0 {
+		rawQuery = "SELECT * FROM (" + rawQuery + ") scored WHERE score >= ?"
+		args = append(args, dataFilter.MinScore)
+	}
+
+	result := repo.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+rawQuery+") counted", args...).Scan(&total)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	rawQuery += " ORDER BY score DESC, customer_id, candidate_id"
+
+	if dataFilter.Limit > 0 && dataFilter.Page > 0 {
+		offset := (dataFilter.Page - 1) * dataFilter.Limit
+		rawQuery += fmt.Sprintf(" LIMIT %d OFFSET %d", dataFilter.Limit, offset)
+	}
+
+	result = repo.db.WithContext(ctx).Raw(rawQuery, args...).Scan(&domain)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return domain, total, nil
+}
+
+// Merge folds the duplicates into the survivor in one transaction: their
+// addresses, tags, activities and attachments move to the survivor, the
+// duplicates are deleted and the survivor is saved with its merged fields.
+// survivor.Version must hold the version read by the caller. The moved
+// activities and attachments are returned.
+func (repo *CustomerRepoImpl) Merge(ctx context.Context, survivor model.Customer, duplicateIds []int) (moved MergeResult, err error) {
+	err = repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Moved addresses never replace the survivor's defaults.
+		err := tx.Exec("UPDATE customer_addresses SET customer_id = ?, is_default = FALSE, updated_at = now() WHERE customer_id IN (?)", survivor.ID, duplicateIds).Error
+		if err != nil {
+			return err
+		}
+
+		// Activities and attachments would otherwise be deleted with the
+		// duplicates by the foreign key cascade.
+		err = tx.Raw("UPDATE customer_activities SET customer_id = ? WHERE customer_id IN (?) RETURNING id", survivor.ID, duplicateIds).Scan(&moved.ActivityIDs).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw("UPDATE customer_attachments SET customer_id = ? WHERE customer_id IN (?) RETURNING id", survivor.ID, duplicateIds).Scan(&moved.AttachmentIDs).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			INSERT INTO customer_tags (customer_id, tag_id)
+			SELECT DISTINCT ?::int, tag_id FROM customer_tags WHERE customer_id IN (?)
+			ON CONFLICT DO NOTHING
+		`, survivor.ID, duplicateIds).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("id IN (?)", duplicateIds).Delete(&model.Customer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if int(result.RowsAffected) != len(duplicateIds) {
+			return errors.New("record not found")
+		}
+
+		return updateVersioned(tx, survivor)
+	})
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	return moved, nil
+}
+
+// updateVersioned saves data only while the row is still at data.Version,
+// the version the caller read, and bumps it. The structured addresses are
+// left alone, they are only changed through their own endpoints.
+func updateVersioned(db *gorm.DB, data model.Customer) error {
+	version := data.Version
+	data.Version = version + 1
+
+	result := db.Where("version = ?", version).Updates(&data)
+	if result.Error != nil {
+		return emailConflict(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// emailConflict turns a unique violation on the customer's email into
+// ErrEmailTaken; other errors are returned as they are.
+func emailConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && (pgErr.ConstraintName == "unique_email" || pgErr.ConstraintName == "unique_customers_email_lower") {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// seedDefaultAddress gives a new customer a default billing address with
+// the free-text address as line1. From then on the two are kept apart:
+// customers.address belongs to the customer record and customer_addresses
+// to the address endpoints. An empty address seeds nothing.
+func seedDefaultAddress(tx *gorm.DB, customerId int, address string) error {
+	if address == "" {
+		return nil
+	}
+
+	return tx.Create(&model.CustomerAddress{
+		CustomerID: customerId,
+		Type:       legacyAddressType,
+		Line1:      address,
+		IsDefault:  true,
+	}).Error
+}
+
+// Transition moves the customer from one status to another and stamps the
+// time it entered the new one. It fails with ErrVersionConflict when the
+// customer is no longer in from at version.
+func (repo *CustomerRepoImpl) Transition(ctx context.Context, Id int, from string, to string, version int) error {
+	result := repo.db.WithContext(ctx).Exec(`
+		UPDATE customers
+		SET status = ?,
+			status_timestamps = COALESCE(status_timestamps, '{}'::jsonb) || jsonb_build_object(?::text, now()),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ? AND status = ? AND version = ?
+	`, to, to, Id, from, version)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// AssignOwner hands the customer to ownerId, or unassigns it when ownerId is
+// nil. It fails with ErrVersionConflict when the customer is no longer at
+// version.
+func (repo *CustomerRepoImpl) AssignOwner(ctx context.Context, Id int, ownerId *int, version int) error {
+	result := repo.db.WithContext(ctx).Exec(`
+		UPDATE customers
+		SET owner_id = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ? AND version = ?
+	`, ownerId, Id, version)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// ReassignOwner moves every customer of fromOwnerId to toOwnerId in one
+// statement and returns the ids of the customers it moved.
+func (repo *CustomerRepoImpl) ReassignOwner(ctx context.Context, fromOwnerId int, toOwnerId *int) ([]int, error) {
+	var ids []int
+	err := repo.db.WithContext(ctx).Raw(`
+		UPDATE customers
+		SET owner_id = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE owner_id = ?
+		RETURNING id
+	`, toOwnerId, fromOwnerId).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
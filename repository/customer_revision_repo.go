@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/entity"
+	"scylla/model"
+)
+
+type CustomerRevisionRepo interface {
+	Insert(ctx context.Context, data model.CustomerRevision) error
+	InsertBatch(ctx context.Context, data []model.CustomerRevision, batchSize int) error
+	FindById(ctx context.Context, Id int) (data model.CustomerRevision, err error)
+	FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerRevision, err error)
+	FindByCustomerId(ctx context.Context, customerId int, dataFilter entity.CustomerHistoryQueryFilter) (domain []model.CustomerRevision, total int64, err error)
+	FindAllByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerRevision, err error)
+	WithTx(tx *gorm.DB) CustomerRevisionRepo
+}
+
+type CustomerRevisionRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCustomerRevisionRepoImpl(db *gorm.DB) CustomerRevisionRepo {
+	return &CustomerRevisionRepoImpl{db: db}
+}
+
+// WithTx returns the repository working in tx, a transaction started by a
+// Transactor.
+func (repo *CustomerRevisionRepoImpl) WithTx(tx *gorm.DB) CustomerRevisionRepo {
+	return &CustomerRevisionRepoImpl{db: tx}
+}
+
+func (repo *CustomerRevisionRepoImpl) Insert(ctx context.Context, data model.CustomerRevision) error {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *CustomerRevisionRepoImpl) InsertBatch(ctx context.Context, data []model.CustomerRevision, batchSize int) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	result := repo.db.WithContext(ctx).CreateInBatches(&data, batchSize)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *CustomerRevisionRepoImpl) FindById(ctx context.Context, Id int) (data model.CustomerRevision, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+
+	if result.RowsAffected == 0 {
+		return data, errors.New("record not found")
+	}
+
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *CustomerRevisionRepoImpl) FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerRevision, err error) {
+	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+func (repo *CustomerRevisionRepoImpl) FindByCustomerId(ctx context.Context, customerId int, dataFilter entity.CustomerHistoryQueryFilter) (domain []model.CustomerRevision, total int64, err error) {
+	db := repo.db.WithContext(ctx).Model(&model.CustomerRevision{}).Where("customer_id = ?", customerId)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := db.Order("created_at DESC, id DESC").
+		Scopes(entity.Scopes(dataFilter.Page, dataFilter.Limit)).
+		Find(&domain)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return domain, total, nil
+}
+
+func (repo *CustomerRevisionRepoImpl) FindAllByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerRevision, err error) {
+	result := repo.db.WithContext(ctx).
+		Where("customer_id = ?", customerId).
+		Order("created_at ASC, id ASC").
+		Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/entity"
+	"scylla/model"
+)
+
+type ImportProfileRepo interface {
+	Insert(ctx context.Context, data model.ImportProfile) (model.ImportProfile, error)
+	Update(ctx context.Context, data model.ImportProfile) error
+	Delete(ctx context.Context, Id int) error
+	FindById(ctx context.Context, Id int) (data model.ImportProfile, err error)
+	FindByName(ctx context.Context, resource string, name string) (data model.ImportProfile, err error)
+	FindAll(ctx context.Context, dataFilter entity.ImportProfileQueryFilter) (domain []model.ImportProfile, err error)
+}
+
+type ImportProfileRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewImportProfileRepoImpl(db *gorm.DB) ImportProfileRepo {
+	return &ImportProfileRepoImpl{db: db}
+}
+
+func (repo *ImportProfileRepoImpl) Insert(ctx context.Context, data model.ImportProfile) (model.ImportProfile, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *ImportProfileRepoImpl) Update(ctx context.Context, data model.ImportProfile) error {
+	result := repo.db.WithContext(ctx).
+		Select("name", "mapping", "updated_at").
+		Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *ImportProfileRepoImpl) Delete(ctx context.Context, Id int) error {
+	result := repo.db.WithContext(ctx).Delete(&model.ImportProfile{}, Id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *ImportProfileRepoImpl) FindById(ctx context.Context, Id int) (data model.ImportProfile, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *ImportProfileRepoImpl) FindByName(ctx context.Context, resource string, name string) (data model.ImportProfile, err error) {
+	result := repo.db.WithContext(ctx).Where("resource = ? AND name = ?", resource, name).First(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *ImportProfileRepoImpl) FindAll(ctx context.Context, dataFilter entity.ImportProfileQueryFilter) (domain []model.ImportProfile, err error) {
+	query := repo.db.WithContext(ctx).Order("resource ASC, name ASC")
+	if dataFilter.Resource != "" {
+		query = query.Where("resource = ?", dataFilter.Resource)
+	}
+
+	result := query.Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+	"time"
+)
+
+type JobRepo interface {
+	Insert(ctx context.Context, data model.Job) (model.Job, error)
+	Update(ctx context.Context, data model.Job) error
+	UpdateProgress(ctx context.Context, Id string, progress int) error
+	FindById(ctx context.Context, Id string) (data model.Job, err error)
+	RequestCancel(ctx context.Context, Id string) error
+	Heartbeat(ctx context.Context, owner string) (cancelled []string, err error)
+	FailAbandoned(ctx context.Context, before time.Time, jobError json.RawMessage) (int64, error)
+}
+
+type JobRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewJobRepoImpl(db *gorm.DB) JobRepo {
+	return &JobRepoImpl{db: db}
+}
+
+func (repo *JobRepoImpl) Insert(ctx context.Context, data model.Job) (model.Job, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *JobRepoImpl) Update(ctx context.Context, data model.Job) error {
+	result := repo.db.WithContext(ctx).
+		Select("state", "progress", "result", "error", "file_key", "started_at", "finished_at").
+		Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+// UpdateProgress raises the progress of a running job. Reports that arrive
+// out of order never lower it.
+func (repo *JobRepoImpl) UpdateProgress(ctx context.Context, Id string, progress int) error {
+	result := repo.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND state = ? AND progress < ?", Id, model.JobRunning, progress).
+		Update("progress", progress)
+	return result.Error
+}
+
+func (repo *JobRepoImpl) FindById(ctx context.Context, Id string) (data model.Job, err error) {
+	result := repo.db.WithContext(ctx).Where("id = ?", Id).First(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+// RequestCancel asks the server running an unfinished job to cancel it.
+func (repo *JobRepoImpl) RequestCancel(ctx context.Context, Id string) error {
+	result := repo.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND state IN ?", Id, []string{model.JobQueued, model.JobRunning}).
+		Update("cancel_requested", true)
+	return result.Error
+}
+
+// Heartbeat marks the unfinished jobs of owner as alive and returns those
+// asked to be cancelled.
+func (repo *JobRepoImpl) Heartbeat(ctx context.Context, owner string) (cancelled []string, err error) {
+	unfinished := repo.db.WithContext(ctx).Model(&model.Job{}).
+		Where("owner = ? AND state IN ?", owner, []string{model.JobQueued, model.JobRunning}).
+		Session(&gorm.Session{})
+
+	result := unfinished.Update("heartbeat_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = unfinished.Where("cancel_requested").Pluck("id", &cancelled)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return cancelled, nil
+}
+
+// FailAbandoned fails with jobError the queued or running jobs whose last
+// heartbeat is older than before, or missing, and returns how many there
+// were.
+func (repo *JobRepoImpl) FailAbandoned(ctx context.Context, before time.Time, jobError json.RawMessage) (int64, error) {
+	result := repo.db.WithContext(ctx).Model(&model.Job{}).
+		Where("state IN ?", []string{model.JobQueued, model.JobRunning}).
+		Where("heartbeat_at IS NULL OR heartbeat_at < ?", before).
+		Updates(map[string]interface{}{
+			"state":       model.JobFailed,
+			"error":       jobError,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"scylla/entity"
+	"scylla/model"
+	"strings"
+	"time"
+)
+
+type RetentionRepo interface {
+	CountExpired(ctx context.Context, table string, column string, condition string, before time.Time) (due int64, oldest *time.Time, err error)
+	PurgeExpired(ctx context.Context, table string, column string, condition string, files []string, before time.Time, batchSize int) (int64, []string, error)
+	InsertLog(ctx context.Context, data model.RetentionLog) error
+	FindLogs(ctx context.Context, dataFilter entity.RetentionLogQueryFilter) (domain []model.RetentionLog, total int64, err error)
+}
+
+type RetentionRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepoImpl(db *gorm.DB) RetentionRepo {
+	return &RetentionRepoImpl{db: db}
+}
+
+// Table, column and condition come from the declared policies, never from a
+// request, so they are written into the query as is.
+func expiredFilter(column string, condition string) string {
+	filter := fmt.Sprintf("%s < ?", column)
+	if condition != "" {
+		filter += " AND (" + condition + ")"
+	}
+	return filter
+}
+
+func (repo *RetentionRepoImpl) CountExpired(ctx context.Context, table string, column string, condition string, before time.Time) (due int64, oldest *time.Time, err error) {
+	query := fmt.Sprintf("SELECT COUNT(*), MIN(%s) FROM %s WHERE %s", column, table, expiredFilter(column, condition))
+
+	var min sql.NullTime
+	err = repo.db.WithContext(ctx).Raw(query, before).Row().Scan(&due, &min)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if min.Valid {
+		oldest = &min.Time
+	}
+	return due, oldest, nil
+}
+
+// PurgeExpired deletes in batches so a large backlog does not hold one long
+// lock on the table. It returns the non-empty values of the files columns of
+// the deleted rows, including those of the batches deleted before an error.
+func (repo *RetentionRepoImpl) PurgeExpired(ctx context.Context, table string, column string, condition string, files []string, before time.Time, batchSize int) (int64, []string, error) {
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)",
+		table, table, expiredFilter(column, condition), batchSize,
+	)
+	if len(files) > 0 {
+		query += " RETURNING " + strings.Join(files, ", ")
+	}
+
+	var total int64
+	var keys []string
+	for {
+		affected, batchKeys, err := repo.purgeBatch(ctx, query, len(files), before)
+		total += affected
+		keys = append(keys, batchKeys...)
+		if err != nil {
+			return total, keys, err
+		}
+
+		if affected < int64(batchSize) {
+			return total, keys, nil
+		}
+	}
+}
+
+func (repo *RetentionRepoImpl) purgeBatch(ctx context.Context, query string, files int, before time.Time) (int64, []string, error) {
+	if files == 0 {
+		result := repo.db.WithContext(ctx).Exec(query, before)
+		return result.RowsAffected, nil, result.Error
+	}
+
+	rows, err := repo.db.WithContext(ctx).Raw(query, before).Rows()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var affected int64
+	var keys []string
+	values := make([]sql.NullString, files)
+	targets := make([]interface{}, files)
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return affected, keys, err
+		}
+		affected++
+		for _, value := range values {
+			if value.String != "" {
+				keys = append(keys, value.String)
+			}
+		}
+	}
+	return affected, keys, rows.Err()
+}
+
+func (repo *RetentionRepoImpl) InsertLog(ctx context.Context, data model.RetentionLog) error {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *RetentionRepoImpl) FindLogs(ctx context.Context, dataFilter entity.RetentionLogQueryFilter) (domain []model.RetentionLog, total int64, err error) {
+	db := repo.db.WithContext(ctx).Model(&model.RetentionLog{})
+	if dataFilter.Policy != "" {
+		db = db.Where("policy = ?", dataFilter.Policy)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := db.Order("started_at DESC, id DESC").
+		Scopes(entity.Scopes(dataFilter.Page, dataFilter.Limit)).
+		Find(&domain)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return domain, total, nil
+}
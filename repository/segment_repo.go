@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"scylla/model"
+)
+
+type SegmentRepo interface {
+	Insert(ctx context.Context, data model.Segment) (model.Segment, error)
+	Update(ctx context.Context, data model.Segment) error
+	Delete(ctx context.Context, Id int) error
+	FindById(ctx context.Context, Id int) (data model.Segment, err error)
+	FindAll(ctx context.Context) (domain []model.Segment, err error)
+}
+
+type SegmentRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewSegmentRepoImpl(db *gorm.DB) SegmentRepo {
+	return &SegmentRepoImpl{db: db}
+}
+
+func (repo *SegmentRepoImpl) Insert(ctx context.Context, data model.Segment) (model.Segment, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *SegmentRepoImpl) Update(ctx context.Context, data model.Segment) error {
+	result := repo.db.WithContext(ctx).
+		Select("name", "description", "filter", "updated_at").
+		Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *SegmentRepoImpl) Delete(ctx context.Context, Id int) error {
+	var data model.Segment
+	result := repo.db.WithContext(ctx).Delete(&data, Id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *SegmentRepoImpl) FindById(ctx context.Context, Id int) (data model.Segment, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *SegmentRepoImpl) FindAll(ctx context.Context) (domain []model.Segment, err error) {
+	result := repo.db.WithContext(ctx).Order("name ASC").Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"scylla/model"
+)
+
+type TagRepo interface {
+	Insert(ctx context.Context, data model.Tag) (model.Tag, error)
+	Update(ctx context.Context, data model.Tag) error
+	Delete(ctx context.Context, Id int) error
+	FindById(ctx context.Context, Id int) (data model.Tag, err error)
+	FindByIds(ctx context.Context, Ids []int) (domain []model.Tag, err error)
+	FindAll(ctx context.Context) (domain []model.Tag, err error)
+	Attach(ctx context.Context, customerIds []int, tagIds []int) error
+	Detach(ctx context.Context, customerIds []int, tagIds []int) error
+	FindNamesByCustomerIds(ctx context.Context, customerIds []int) (map[int][]string, error)
+}
+
+type TagRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewTagRepoImpl(db *gorm.DB) TagRepo {
+	return &TagRepoImpl{db: db}
+}
+
+func (repo *TagRepoImpl) Insert(ctx context.Context, data model.Tag) (model.Tag, error) {
+	result := repo.db.WithContext(ctx).Create(&data)
+	if result.Error != nil {
+		return data, result.Error
+	}
+	return data, nil
+}
+
+func (repo *TagRepoImpl) Update(ctx context.Context, data model.Tag) error {
+	result := repo.db.WithContext(ctx).Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *TagRepoImpl) Delete(ctx context.Context, Id int) error {
+	var data model.Tag
+	result := repo.db.WithContext(ctx).Delete(&data, Id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+func (repo *TagRepoImpl) FindById(ctx context.Context, Id int) (data model.Tag, err error) {
+	result := repo.db.WithContext(ctx).First(&data, Id)
+	if result.Error != nil {
+		return data, result.Error
+	}
+
+	return data, nil
+}
+
+func (repo *TagRepoImpl) FindByIds(ctx context.Context, Ids []int) (domain []model.Tag, err error) {
+	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+func (repo *TagRepoImpl) FindAll(ctx context.Context) (domain []model.Tag, err error) {
+	result := repo.db.WithContext(ctx).Order("name ASC").Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
+func (repo *TagRepoImpl) Attach(ctx context.Context, customerIds []int, tagIds []int) error {
+	var data []model.CustomerTag
+	for _, customerId := range customerIds {
+		for _, tagId := range tagIds {
+			data = append(data, model.CustomerTag{CustomerID: customerId, TagID: tagId})
+		}
+	}
+
+	// Tagging a customer twice is a no-op rather than an error.
+	result := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&data, 500)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *TagRepoImpl) Detach(ctx context.Context, customerIds []int, tagIds []int) error {
+	var data model.CustomerTag
+	result := repo.db.WithContext(ctx).Where("customer_id IN (?) AND tag_id IN (?)", customerIds, tagIds).Delete(&data)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (repo *TagRepoImpl) FindNamesByCustomerIds(ctx context.Context, customerIds []int) (map[int][]string, error) {
+	names := make(map[int][]string)
+	if len(customerIds) == 0 {
+		return names, nil
+	}
+
+	rows, err := repo.db.WithContext(ctx).Raw(`
+		SELECT 
+			ct.customer_id, t.name
+		FROM 
+			customer_tags ct
+			JOIN tags t ON t.id = ct.tag_id
+		WHERE 
+			ct.customer_id IN (?)
+		ORDER BY t.name
+	`, customerIds).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var customerId int
+		var name string
+		if err := rows.Scan(&customerId, &name); err != nil {
+			return nil, err
+		}
+		names[customerId] = append(names[customerId], name)
+	}
+
+	return names, rows.Err()
+}
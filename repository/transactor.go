@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// Transactor runs work in one database transaction. Repositories bound to
+// the transaction with WithTx take part in it, so a change and the rows that
+// record it are committed or rolled back together.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+}
+
+type TransactorImpl struct {
+	db *gorm.DB
+}
+
+func NewTransactorImpl(db *gorm.DB) Transactor {
+	return &TransactorImpl{db: db}
+}
+
+// Transaction commits when fn returns nil and rolls back when it returns an
+// error or panics; the panic is passed on after the rollback.
+func (transactor *TransactorImpl) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return transactor.db.WithContext(ctx).Transaction(fn)
+}
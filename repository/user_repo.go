@@ -8,15 +8,20 @@ import (
 	"scylla/entity"
 	"scylla/model"
 	"scylla/pkg/helper"
+	"strings"
 )
 
 type UserRepo interface {
 	Insert(ctx context.Context, data model.User) error
 	InsertBatch(ctx context.Context, data []model.User, batchSize int) error
 	Update(ctx context.Context, data model.User) error
+	Delete(ctx context.Context, Id int, version int) error
 	DeleteBatch(ctx context.Context, Ids []int) error
 	FindAll(ctx context.Context, dataFilter entity.UserQueryFilter) (domain []model.User, err error)
+	FindAllInBatches(ctx context.Context, dataFilter entity.UserQueryFilter, batchSize int, fn func(batch []model.User) error) error
+	Count(ctx context.Context, dataFilter entity.UserQueryFilter) (total int64, err error)
 	FindById(ctx context.Context, Id int) (data model.User, err error)
+	FindByIds(ctx context.Context, Ids []int) (domain []model.User, err error)
 	FindByColumns(ctx context.Context, columns []string, queries []any) (model.User, error)
 	CheckColumnExists(ctx context.Context, column string, value interface{}) bool
 }
@@ -56,15 +61,32 @@ func (repo *UserRepoImpl) InsertBatch(ctx context.Context, data []model.User, ba
 }
 
 func (repo *UserRepoImpl) Update(ctx context.Context, data model.User) error {
-	result := repo.db.WithContext(ctx).Updates(&data)
+	// data.Version holds the version the caller read; the row is only
+	// written when it is still at that version.
+	version := data.Version
+	data.Version = version + 1
+
+	result := repo.db.WithContext(ctx).Where("version = ?", version).Updates(&data)
+	if result.Error != nil {
+		return result.Error
+	}
 
 	if result.RowsAffected == 0 {
-		return errors.New("record not found")
+		return ErrVersionConflict
 	}
+	return nil
+}
 
+func (repo *UserRepoImpl) Delete(ctx context.Context, Id int, version int) error {
+	var data model.User
+	result := repo.db.WithContext(ctx).Where("id = ? AND version = ?", Id, version).Delete(&data)
 	if result.Error != nil {
 		return result.Error
 	}
+
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
 	return nil
 }
 
@@ -84,40 +106,93 @@ func (repo *UserRepoImpl) DeleteBatch(ctx context.Context, Ids []int) error {
 }
 
 func (repo *UserRepoImpl) FindAll(ctx context.Context, dataFilter entity.UserQueryFilter) (domain []model.User, err error) {
-	query := "SELECT * FROM users"
-	args := []interface{}{}
-
-	if dataFilter.Username != "" {
-		query += " WHERE username = ?"
-		args = append(args, dataFilter.Username)
+	err = repo.FindAllInBatches(ctx, dataFilter, findAllBatchSize, func(batch []model.User) error {
+		domain = append(domain, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return domain, nil
+}
 
-	if dataFilter.Email != "" {
-		query += " WHERE email = ?"
-		args = append(args, dataFilter.Email)
+// FindAllInBatches reads the users FindAll returns from a cursor, in id
+// order, and calls fn with every batchSize of them. An error from fn stops
+// the read and is returned.
+func (repo *UserRepoImpl) FindAllInBatches(ctx context.Context, dataFilter entity.UserQueryFilter, batchSize int, fn func(batch []model.User) error) error {
+	if batchSize <= 0 {
+		batchSize = findAllBatchSize
 	}
 
-	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
-		query += " WHERE created_at BETWEEN ? AND ?"
-		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
-	}
+	where, args := userFilters(dataFilter)
+	query := "SELECT id, username, email, password, version, created_at, updated_at FROM users" + where + " ORDER BY id"
 
 	rows, err := repo.db.WithContext(ctx).Raw(query, args...).Rows()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
+	batch := make([]model.User, 0, batchSize)
 	for rows.Next() {
 		var user model.User
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Version, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		domain = append(domain, user)
+		batch = append(batch, user)
+
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]model.User, 0, batchSize)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return domain, nil
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// Count returns how many users FindAll returns.
+func (repo *UserRepoImpl) Count(ctx context.Context, dataFilter entity.UserQueryFilter) (total int64, err error) {
+	where, args := userFilters(dataFilter)
+	result := repo.db.WithContext(ctx).Raw("SELECT count(*) FROM users"+where, args...).Scan(&total)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// userFilters builds the WHERE clause of FindAll.
+func userFilters(dataFilter entity.UserQueryFilter) (string, []interface{}) {
+	var filters []string
+	var args []interface{}
+
+	if dataFilter.Username != "" {
+		filters = append(filters, "username = ?")
+		args = append(args, dataFilter.Username)
+	}
+
+	if dataFilter.Email != "" {
+		filters = append(filters, "email = ?")
+		args = append(args, dataFilter.Email)
+	}
+
+	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
+		filters = append(filters, "created_at BETWEEN ? AND ?")
+		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
+	}
+
+	if len(filters) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(filters, " AND "), args
 }
 
 func (repo *UserRepoImpl) FindById(ctx context.Context, Id int) (data model.User, err error) {
@@ -134,6 +209,15 @@ func (repo *UserRepoImpl) FindById(ctx context.Context, Id int) (data model.User
 	return data, nil
 }
 
+func (repo *UserRepoImpl) FindByIds(ctx context.Context, Ids []int) (domain []model.User, err error) {
+	result := repo.db.WithContext(ctx).Where("id IN (?)", Ids).Find(&domain)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return domain, nil
+}
+
 func (repo *UserRepoImpl) FindByColumns(ctx context.Context, columns []string, queries []any) (model.User, error) {
 	if len(columns) != len(queries) {
 		return model.User{}, errors.New("columns and queries length mismatch")
@@ -12,6 +12,17 @@ import (
 func NewRoutesV1(
 	authController *controller.AuthController,
 	customerController *controller.CustomerController,
+	customerAddressController *controller.CustomerAddressController,
+	customerPrivacyController *controller.CustomerPrivacyController,
+	customerActivityController *controller.CustomerActivityController,
+	customerAttachmentController *controller.CustomerAttachmentController,
+	tagController *controller.TagController,
+	segmentController *controller.SegmentController,
+	customFieldController *controller.CustomFieldController,
+	importProfileController *controller.ImportProfileController,
+	importErrorController *controller.ImportErrorController,
+	retentionController *controller.RetentionController,
+	jobController *controller.JobController,
 	userController *controller.UserController,
 ) *gin.Engine {
 
@@ -39,6 +50,10 @@ func NewRoutesV1(
 	authRouter.PATCH("/reset-password", authController.ResetPassword)
 	authRouter.POST("/logout", middleware.JwtMiddleware(), authController.Logout)
 
+	//signed attachment and import error downloads carry their own authorization
+	router.GET("/attachments/:attachmentId/download", customerAttachmentController.Download)
+	router.GET("/imports/errors/:errorFileId", importErrorController.Download)
+
 	//middleware jwt
 	router.Use(middleware.JwtMiddleware())
 	//customer
@@ -47,20 +62,103 @@ func NewRoutesV1(
 	customerRouter.GET("/:customerId", customerController.FindById)
 	customerRouter.POST("", customerController.Create)
 	customerRouter.POST("/batch", customerController.CreateBatch)
+	customerRouter.PATCH("/batch", customerController.UpdateBatch)
 	customerRouter.PATCH("/:customerId", customerController.Update)
+	customerRouter.DELETE("/:customerId", customerController.Delete)
+	customerRouter.GET("/:customerId/history", customerController.FindHistory)
+	customerRouter.POST("/:customerId/history/:revisionId/revert", customerController.Revert)
+	customerRouter.POST("/:customerId/transition", customerController.Transition)
+	customerRouter.PUT("/:customerId/owner", customerController.AssignOwner)
+
+	//customer privacy
+	customerRouter.GET("/:customerId/personal-data", customerPrivacyController.PersonalData)
+	customerRouter.POST("/:customerId/erase", customerPrivacyController.Erase)
+
+	//customer address
+	addressRouter := customerRouter.Group("/:customerId/addresses")
+	addressRouter.GET("", customerAddressController.FindAll)
+	addressRouter.GET("/:addressId", customerAddressController.FindById)
+	addressRouter.POST("", customerAddressController.Create)
+	addressRouter.PATCH("/:addressId", customerAddressController.Update)
+	addressRouter.DELETE("/:addressId", customerAddressController.Delete)
+
+	//customer activity
+	activityRouter := customerRouter.Group("/:customerId/activities")
+	activityRouter.GET("", customerActivityController.FindAll)
+	activityRouter.GET("/:activityId", customerActivityController.FindById)
+	activityRouter.POST("", customerActivityController.Create)
+	activityRouter.PATCH("/:activityId", customerActivityController.Update)
+	activityRouter.DELETE("/:activityId", customerActivityController.Delete)
+	customerRouter.GET("/:customerId/timeline", customerActivityController.FindTimeline)
+
+	//customer attachment
+	attachmentRouter := customerRouter.Group("/:customerId/attachments")
+	attachmentRouter.GET("", customerAttachmentController.FindAll)
+	attachmentRouter.POST("", customerAttachmentController.Upload)
+	attachmentRouter.GET("/:attachmentId/url", customerAttachmentController.SignedURL)
+	attachmentRouter.DELETE("/:attachmentId", customerAttachmentController.Delete)
 	customerRouter.DELETE("/batch", customerController.DeleteBatch)
 	customerRouter.GET("/export", customerController.Export)
 	customerRouter.POST("/import", customerController.Import)
+	customerRouter.GET("/import/template", customerController.ImportTemplate)
+	customerRouter.POST("/tags", tagController.Tag)
+	customerRouter.DELETE("/tags", tagController.Untag)
+	customerRouter.GET("/duplicates", customerController.FindDuplicates)
+	customerRouter.POST("/merge", customerController.Merge)
+	customerRouter.POST("/reassign", customerController.Reassign)
+
+	//tag
+	tagRouter := router.Group("/tags")
+	tagRouter.GET("", tagController.FindAll)
+	tagRouter.POST("", tagController.Create)
+	tagRouter.PATCH("/:tagId", tagController.Update)
+	tagRouter.DELETE("/:tagId", tagController.Delete)
+
+	//segment
+	segmentRouter := router.Group("/segments")
+	segmentRouter.GET("", segmentController.FindAll)
+	segmentRouter.GET("/:segmentId", segmentController.FindById)
+	segmentRouter.GET("/:segmentId/customers", segmentController.FindCustomers)
+	segmentRouter.POST("", segmentController.Create)
+	segmentRouter.PATCH("/:segmentId", segmentController.Update)
+	segmentRouter.DELETE("/:segmentId", segmentController.Delete)
+
+	//custom field
+	customFieldRouter := router.Group("/custom-fields")
+	customFieldRouter.GET("", customFieldController.FindAll)
+	customFieldRouter.POST("", customFieldController.Create)
+	customFieldRouter.PATCH("/:customFieldId", customFieldController.Update)
+	customFieldRouter.DELETE("/:customFieldId", customFieldController.Delete)
+
+	//import profile
+	importProfileRouter := router.Group("/import-profiles")
+	importProfileRouter.GET("", importProfileController.FindAll)
+	importProfileRouter.POST("", importProfileController.Create)
+	importProfileRouter.PATCH("/:importProfileId", importProfileController.Update)
+	importProfileRouter.DELETE("/:importProfileId", importProfileController.Delete)
+
+	//retention
+	retentionRouter := router.Group("/retention")
+	retentionRouter.GET("/report", retentionController.Report)
+	retentionRouter.GET("/logs", retentionController.FindLogs)
+
+	//job
+	jobRouter := router.Group("/jobs")
+	jobRouter.GET("/:jobId", jobController.FindById)
+	jobRouter.GET("/:jobId/result", jobController.Result)
+	jobRouter.POST("/:jobId/cancel", jobController.Cancel)
 
 	//user
 	userRouter := router.Group("/users")
 	userRouter.POST("", userController.Create)
 	userRouter.PATCH("/:userId", userController.Update)
 	userRouter.GET("/:userId", userController.FindById)
+	userRouter.DELETE("/:userId", userController.Delete)
 	userRouter.GET("", userController.FindAll)
 	userRouter.POST("/batch", userController.DeleteBatch)
 	userRouter.GET("/export", userController.Export)
 	userRouter.POST("/import", userController.Import)
+	userRouter.GET("/import/template", userController.ImportTemplate)
 
 	return app
 }
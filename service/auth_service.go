@@ -170,10 +170,11 @@ func (service *AuthServiceImpl) ResetPassword(ctx context.Context, request entit
 	hashedPassword, err := utils.HashPassword(request.Password)
 	helper.ErrorPanic(err)
 
-	dataset := model.User{
-		Email:    data.Email,
-		Password: hashedPassword,
+	dataset, err := service.userRepo.FindByColumns(ctx, []string{"email"}, []any{data.Email})
+	if err != nil {
+		return "", exception.NewNotFoundHandler(err.Error())
 	}
+	dataset.Password = hashedPassword
 
 	err = service.userRepo.Update(ctx, dataset)
 	if err != nil {
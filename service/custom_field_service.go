@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/repository"
+)
+
+type CustomFieldService interface {
+	Create(ctx context.Context, request entity.CreateCustomFieldRequest) (response entity.CustomFieldResponse)
+	Update(ctx context.Context, request entity.UpdateCustomFieldRequest)
+	Delete(ctx context.Context, params entity.CustomFieldParams)
+	FindAll(ctx context.Context) (response []entity.CustomFieldResponse)
+}
+
+type CustomFieldServiceImpl struct {
+	customFieldRepo repository.CustomFieldRepo
+	validate        *validator.Validate
+}
+
+func NewCustomFieldServiceImpl(customFieldRepo repository.CustomFieldRepo, validate *validator.Validate) CustomFieldService {
+	return &CustomFieldServiceImpl{
+		customFieldRepo: customFieldRepo,
+		validate:        validate,
+	}
+}
+
+func (service *CustomFieldServiceImpl) Create(ctx context.Context, request entity.CreateCustomFieldRequest) (response entity.CustomFieldResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	if request.Type != model.CustomFieldEnum {
+		request.Options = nil
+	}
+
+	dataset := model.CustomFieldDefinition{
+		Key:      request.Key,
+		Label:    request.Label,
+		Type:     request.Type,
+		Required: request.Required,
+		Options:  request.Options,
+	}
+
+	dataset, err = service.customFieldRepo.Insert(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	helper.Automapper(dataset, &response)
+	return response
+}
+
+func (service *CustomFieldServiceImpl) Update(ctx context.Context, request entity.UpdateCustomFieldRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.customFieldRepo.FindById(ctx, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if dataset.Type == model.CustomFieldEnum && len(request.Options) == 0 {
+		panic(exception.NewBadRequestHandler("options is required for enum fields"))
+	}
+
+	dataset.Label = request.Label
+	dataset.Required = request.Required
+	if dataset.Type == model.CustomFieldEnum {
+		dataset.Options = request.Options
+	}
+
+	err = service.customFieldRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *CustomFieldServiceImpl) Delete(ctx context.Context, params entity.CustomFieldParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.customFieldRepo.Delete(ctx, params.CustomFieldId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *CustomFieldServiceImpl) FindAll(ctx context.Context) (response []entity.CustomFieldResponse) {
+	result, err := service.customFieldRepo.FindAll(ctx)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		var res entity.CustomFieldResponse
+		helper.Automapper(row, &res)
+		response = append(response, res)
+	}
+	return response
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"math"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"time"
+)
+
+type CustomerActivityService interface {
+	Create(ctx context.Context, request entity.CreateCustomerActivityRequest) (response entity.CustomerActivityResponse)
+	Update(ctx context.Context, request entity.UpdateCustomerActivityRequest)
+	Delete(ctx context.Context, params entity.CustomerActivityParams)
+	FindById(ctx context.Context, params entity.CustomerActivityParams) (response entity.CustomerActivityResponse)
+	FindAll(ctx context.Context, params entity.CustomerParams, dataFilter entity.CustomerActivityQueryFilter) (response []entity.CustomerActivityResponse, paging entity.Meta)
+	FindTimeline(ctx context.Context, params entity.CustomerParams, dataFilter entity.CustomerTimelineQueryFilter) (response []entity.CustomerTimelineResponse, paging entity.Meta)
+}
+
+type CustomerActivityServiceImpl struct {
+	activityRepo repository.CustomerActivityRepo
+	customerRepo repository.CustomerRepo
+	revisionRepo repository.CustomerRevisionRepo
+	validate     *validator.Validate
+}
+
+func NewCustomerActivityServiceImpl(activityRepo repository.CustomerActivityRepo, customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo, validate *validator.Validate) CustomerActivityService {
+	return &CustomerActivityServiceImpl{
+		activityRepo: activityRepo,
+		customerRepo: customerRepo,
+		revisionRepo: revisionRepo,
+		validate:     validate,
+	}
+}
+
+func (service *CustomerActivityServiceImpl) Create(ctx context.Context, request entity.CreateCustomerActivityRequest) (response entity.CustomerActivityResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	_, err = service.customerRepo.FindById(ctx, request.CustomerID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	dataset := model.CustomerActivity{
+		CustomerID: request.CustomerID,
+		Type:       request.Type,
+		Subject:    request.Subject,
+		Body:       request.Body,
+		Author:     utils.ActorFromContext(ctx),
+		DueAt:      parseDueAt(request.DueAt),
+	}
+
+	dataset, err = service.activityRepo.Insert(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	return activityResponse(dataset)
+}
+
+func (service *CustomerActivityServiceImpl) Update(ctx context.Context, request entity.UpdateCustomerActivityRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.activityRepo.FindById(ctx, request.CustomerID, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	// The author stays the user who logged the activity.
+	dataset.Type = request.Type
+	dataset.Subject = request.Subject
+	dataset.Body = request.Body
+	dataset.DueAt = parseDueAt(request.DueAt)
+
+	err = service.activityRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *CustomerActivityServiceImpl) Delete(ctx context.Context, params entity.CustomerActivityParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.activityRepo.Delete(ctx, params.CustomerId, params.ActivityId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *CustomerActivityServiceImpl) FindById(ctx context.Context, params entity.CustomerActivityParams) (response entity.CustomerActivityResponse) {
+	result, err := service.activityRepo.FindById(ctx, params.CustomerId, params.ActivityId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	return activityResponse(result)
+}
+
+func (service *CustomerActivityServiceImpl) FindAll(ctx context.Context, params entity.CustomerParams, dataFilter entity.CustomerActivityQueryFilter) (response []entity.CustomerActivityResponse, paging entity.Meta) {
+	err := service.validate.Struct(dataFilter)
+	helper.ErrorPanic(err)
+
+	_, err = service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
+
+	if dataFilter.Page == 0 {
+		dataFilter.Page = 1
+	}
+
+	result, total, err := service.activityRepo.FindByCustomerId(ctx, params.CustomerId, dataFilter)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, value := range result {
+		response = append(response, activityResponse(value))
+	}
+
+	paging.Page = dataFilter.Page
+	paging.Limit = dataFilter.Limit
+	paging.TotalData = int(total)
+	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+
+	return response, paging
+}
+
+func (service *CustomerActivityServiceImpl) FindTimeline(ctx context.Context, params entity.CustomerParams, dataFilter entity.CustomerTimelineQueryFilter) (response []entity.CustomerTimelineResponse, paging entity.Meta) {
+	_, err := service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
+
+	if dataFilter.Page == 0 {
+		dataFilter.Page = 1
+	}
+
+	refs, total, err := service.activityRepo.FindTimeline(ctx, params.CustomerId, dataFilter)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	var activityIds, revisionIds []int
+	for _, ref := range refs {
+		if ref.Kind == entity.TimelineActivity {
+			activityIds = append(activityIds, ref.ID)
+		} else {
+			revisionIds = append(revisionIds, ref.ID)
+		}
+	}
+
+	activities := make(map[int]entity.CustomerActivityResponse)
+	if len(activityIds) > 0 {
+		rows, err := service.activityRepo.FindByIds(ctx, activityIds)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		for _, row := range rows {
+			activities[row.ID] = activityResponse(row)
+		}
+	}
+
+	changes := make(map[int]entity.CustomerRevisionResponse)
+	if len(revisionIds) > 0 {
+		rows, err := service.revisionRepo.FindByIds(ctx, revisionIds)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		for _, row := range rows {
+			changes[row.ID] = revisionResponse(row)
+		}
+	}
+
+	for _, ref := range refs {
+		entry := entity.CustomerTimelineResponse{
+			Kind:       ref.Kind,
+			OccurredAt: ref.OccurredAt.Format(time.RFC3339),
+		}
+
+		if ref.Kind == entity.TimelineActivity {
+			activity, ok := activities[ref.ID]
+			if !ok {
+				continue
+			}
+			entry.Activity = &activity
+		} else {
+			change, ok := changes[ref.ID]
+			if !ok {
+				continue
+			}
+			entry.Change = &change
+		}
+		response = append(response, entry)
+	}
+
+	paging.Page = dataFilter.Page
+	paging.Limit = dataFilter.Limit
+	paging.TotalData = int(total)
+	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+
+	return response, paging
+}
+
+// parseDueAt reads a due date already checked by the datetime validator.
+func parseDueAt(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	dueAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	return &dueAt
+}
+
+func activityResponse(value model.CustomerActivity) entity.CustomerActivityResponse {
+	response := entity.CustomerActivityResponse{
+		ID:         value.ID,
+		CustomerID: value.CustomerID,
+		Type:       value.Type,
+		Subject:    value.Subject,
+		Body:       value.Body,
+		Author:     value.Author,
+		CreatedAt:  value.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  value.UpdatedAt.Format(time.RFC3339),
+	}
+	if value.DueAt != nil {
+		response.DueAt = value.DueAt.Format(time.RFC3339)
+	}
+	return response
+}
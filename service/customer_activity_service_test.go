@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"testing"
+	"time"
+)
+
+func TestCreateCustomerActivity(t *testing.T) {
+	activities := &fakeActivityRepo{}
+	service := NewCustomerActivityServiceImpl(activities, storedCustomers(), &fakeRevisionRepo{}, utils.InitializeValidator(nil))
+
+	response := service.Create(actorContext("mia@example.com"), entity.CreateCustomerActivityRequest{
+		CustomerID: 1,
+		Type:       "call",
+		Body:       "Asked for a quote",
+		DueAt:      "2026-10-20T09:00:00+07:00",
+	})
+
+	if response.ID != 1 || response.Author != "mia@example.com" || response.DueAt != "2026-10-20T09:00:00+07:00" {
+		t.Errorf("created %+v", response)
+	}
+
+	service.Update(actorContext("noah@example.com"), entity.UpdateCustomerActivityRequest{ID: 1, CustomerID: 1, Type: "note", Body: "Quote sent"})
+
+	stored := activities.activities[0]
+	if stored.Author != "mia@example.com" || stored.Body != "Quote sent" || stored.DueAt != nil {
+		t.Errorf("updated to %+v: want the body and due date replaced and the author kept", stored)
+	}
+}
+
+func TestCreateCustomerActivityRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.CreateCustomerActivityRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "unknown customer",
+			request: entity.CreateCustomerActivityRequest{CustomerID: 9, Type: "note", Body: "Hello"},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:    "due date without a zone",
+			request: entity.CreateCustomerActivityRequest{CustomerID: 1, Type: "note", Body: "Hello", DueAt: "2026-10-20 09:00"},
+			check:   func(value interface{}) bool { _, ok := value.(validator.ValidationErrors); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		activities := &fakeActivityRepo{}
+		service := NewCustomerActivityServiceImpl(activities, storedCustomers(), &fakeRevisionRepo{}, utils.InitializeValidator(nil))
+
+		value := raised(func() { service.Create(actorContext("mia@example.com"), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if len(activities.activities) != 0 {
+			t.Errorf("%s: activity was stored", test.name)
+		}
+	}
+}
+
+func TestFindCustomerTimeline(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	activities := &fakeActivityRepo{
+		activities: []model.CustomerActivity{{ID: 1, CustomerID: 1, Type: "call", Body: "Asked for a quote"}},
+		timeline: []repository.TimelineRef{
+			{Kind: entity.TimelineActivity, ID: 1, OccurredAt: now},
+			{Kind: entity.TimelineChange, ID: 1, OccurredAt: now.Add(-time.Hour)},
+			// Deleted between the two queries; left out.
+			{Kind: entity.TimelineActivity, ID: 2, OccurredAt: now.Add(-2 * time.Hour)},
+		},
+	}
+	revisions := &fakeRevisionRepo{revisions: []model.CustomerRevision{{ID: 1, CustomerID: 1, Action: model.RevisionUpdate}}}
+	service := NewCustomerActivityServiceImpl(activities, storedCustomers(), revisions, utils.InitializeValidator(nil))
+
+	response, paging := service.FindTimeline(context.Background(), entity.CustomerParams{CustomerId: 1}, entity.CustomerTimelineQueryFilter{})
+
+	if len(response) != 2 {
+		t.Fatalf("timeline has %d entries, want 2", len(response))
+	}
+	if response[0].Activity == nil || response[0].Activity.Body != "Asked for a quote" || response[0].OccurredAt != "2026-10-18T09:00:00Z" {
+		t.Errorf("first entry %+v, want the call", response[0])
+	}
+	if response[1].Change == nil || response[1].Change.Action != model.RevisionUpdate {
+		t.Errorf("second entry %+v, want the update", response[1])
+	}
+	if paging.Page != 1 || paging.Limit != 10 {
+		t.Errorf("paging %+v, want the first page of 10", paging)
+	}
+}
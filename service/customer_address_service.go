@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/repository"
+	"strings"
+)
+
+type CustomerAddressService interface {
+	Create(ctx context.Context, request entity.CreateCustomerAddressRequest) (response entity.CustomerAddressResponse)
+	Update(ctx context.Context, request entity.UpdateCustomerAddressRequest)
+	Delete(ctx context.Context, params entity.CustomerAddressParams)
+	FindById(ctx context.Context, params entity.CustomerAddressParams) (response entity.CustomerAddressResponse)
+	FindAll(ctx context.Context, params entity.CustomerParams) (response []entity.CustomerAddressResponse)
+}
+
+type CustomerAddressServiceImpl struct {
+	addressRepo  repository.CustomerAddressRepo
+	customerRepo repository.CustomerRepo
+	validate     *validator.Validate
+}
+
+func NewCustomerAddressServiceImpl(addressRepo repository.CustomerAddressRepo, customerRepo repository.CustomerRepo, validate *validator.Validate) CustomerAddressService {
+	return &CustomerAddressServiceImpl{
+		addressRepo:  addressRepo,
+		customerRepo: customerRepo,
+		validate:     validate,
+	}
+}
+
+func (service *CustomerAddressServiceImpl) Create(ctx context.Context, request entity.CreateCustomerAddressRequest) (response entity.CustomerAddressResponse) {
+	request.Country = strings.ToUpper(strings.TrimSpace(request.Country))
+	request.PostalCode = strings.TrimSpace(request.PostalCode)
+
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	_, err = service.customerRepo.FindById(ctx, request.CustomerID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	dataset := model.CustomerAddress{
+		CustomerID: request.CustomerID,
+		Type:       request.Type,
+		Line1:      request.Line1,
+		Line2:      request.Line2,
+		City:       request.City,
+		Region:     request.Region,
+		PostalCode: request.PostalCode,
+		Country:    request.Country,
+		IsDefault:  request.IsDefault,
+	}
+
+	dataset, err = service.addressRepo.Insert(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	helper.Automapper(dataset, &response)
+	return response
+}
+
+func (service *CustomerAddressServiceImpl) Update(ctx context.Context, request entity.UpdateCustomerAddressRequest) {
+	request.Country = strings.ToUpper(strings.TrimSpace(request.Country))
+	request.PostalCode = strings.TrimSpace(request.PostalCode)
+
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.addressRepo.FindById(ctx, request.CustomerID, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	dataset.Type = request.Type
+	dataset.Line1 = request.Line1
+	dataset.Line2 = request.Line2
+	dataset.City = request.City
+	dataset.Region = request.Region
+	dataset.PostalCode = request.PostalCode
+	dataset.Country = request.Country
+	dataset.IsDefault = request.IsDefault
+
+	err = service.addressRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *CustomerAddressServiceImpl) Delete(ctx context.Context, params entity.CustomerAddressParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.addressRepo.Delete(ctx, params.CustomerId, params.AddressId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *CustomerAddressServiceImpl) FindById(ctx context.Context, params entity.CustomerAddressParams) (response entity.CustomerAddressResponse) {
+	result, err := service.addressRepo.FindById(ctx, params.CustomerId, params.AddressId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	helper.Automapper(result, &response)
+	return response
+}
+
+func (service *CustomerAddressServiceImpl) FindAll(ctx context.Context, params entity.CustomerParams) (response []entity.CustomerAddressResponse) {
+	_, err := service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	result, err := service.addressRepo.FindByCustomerId(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		var res entity.CustomerAddressResponse
+		helper.Automapper(row, &res)
+		response = append(response, res)
+	}
+	return response
+}
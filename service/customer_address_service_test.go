@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"testing"
+)
+
+func addressRequest(customerId int, postalCode string, country string) entity.CreateCustomerAddressRequest {
+	return entity.CreateCustomerAddressRequest{
+		CustomerID: customerId,
+		Type:       "shipping",
+		Line1:      "Jl. Sudirman 1",
+		City:       "Jakarta",
+		PostalCode: postalCode,
+		Country:    country,
+	}
+}
+
+func TestCreateCustomerAddress(t *testing.T) {
+	addresses := &fakeCustomerAddressRepo{}
+	service := NewCustomerAddressServiceImpl(addresses, storedCustomers(), utils.InitializeValidator(nil))
+
+	response := service.Create(context.Background(), addressRequest(1, " 10220 ", " id "))
+
+	if response.ID != 1 || response.Country != "ID" || response.PostalCode != "10220" {
+		t.Errorf("created %+v, want id 1 in ID with postal code 10220", response)
+	}
+	if len(addresses.addresses) != 1 || addresses.addresses[0].CustomerID != 1 {
+		t.Errorf("stored %+v, want one address of customer 1", addresses.addresses)
+	}
+}
+
+func TestCreateCustomerAddressRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.CreateCustomerAddressRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "postal code of another country",
+			request: addressRequest(1, "SW1A 1AA", "ID"),
+			check:   func(value interface{}) bool { _, ok := value.(validator.ValidationErrors); return ok },
+		},
+		{
+			name:    "unknown country",
+			request: addressRequest(1, "", "XX"),
+			check:   func(value interface{}) bool { _, ok := value.(validator.ValidationErrors); return ok },
+		},
+		{
+			name:    "unknown customer",
+			request: addressRequest(9, "10220", "ID"),
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		addresses := &fakeCustomerAddressRepo{}
+		service := NewCustomerAddressServiceImpl(addresses, storedCustomers(), utils.InitializeValidator(nil))
+
+		value := raised(func() { service.Create(context.Background(), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if len(addresses.addresses) != 0 {
+			t.Errorf("%s: address was stored", test.name)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sniffLength is how much of an upload is read to detect its content type,
+// enough for the entries that tell office documents from other zip files.
+const sniffLength = 3072
+
+type CustomerAttachmentService interface {
+	Upload(ctx context.Context, params entity.CustomerParams, file *multipart.FileHeader) (response entity.CustomerAttachmentResponse)
+	FindAll(ctx context.Context, params entity.CustomerParams) (response []entity.CustomerAttachmentResponse)
+	SignedURL(ctx context.Context, params entity.CustomerAttachmentParams) (response entity.AttachmentURLResponse)
+	Download(ctx context.Context, request entity.DownloadAttachmentRequest) (response entity.CustomerAttachmentResponse, body io.ReadCloser)
+	Delete(ctx context.Context, params entity.CustomerAttachmentParams)
+}
+
+type CustomerAttachmentServiceImpl struct {
+	attachmentRepo repository.CustomerAttachmentRepo
+	customerRepo   repository.CustomerRepo
+	fileStorage    storage.Storage
+	options        config.AttachmentOptions
+	validate       *validator.Validate
+}
+
+func NewCustomerAttachmentServiceImpl(attachmentRepo repository.CustomerAttachmentRepo, customerRepo repository.CustomerRepo, fileStorage storage.Storage, options config.AttachmentOptions, validate *validator.Validate) CustomerAttachmentService {
+	if options.MaxSize <= 0 {
+		options.MaxSize = config.DefaultAttachmentMaxSize
+	}
+	if options.URLTTL <= 0 {
+		options.URLTTL = config.DefaultSignedURLTTL
+	}
+
+	return &CustomerAttachmentServiceImpl{
+		attachmentRepo: attachmentRepo,
+		customerRepo:   customerRepo,
+		fileStorage:    fileStorage,
+		options:        options,
+		validate:       validate,
+	}
+}
+
+func (service *CustomerAttachmentServiceImpl) Upload(ctx context.Context, params entity.CustomerParams, file *multipart.FileHeader) (response entity.CustomerAttachmentResponse) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	_, err = service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if file.Size == 0 {
+		panic(exception.NewBadRequestHandler("file is empty"))
+	}
+	if file.Size > service.options.MaxSize {
+		panic(exception.NewBadRequestHandler(fmt.Sprintf("file is larger than the limit of %d bytes", service.options.MaxSize)))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	defer src.Close()
+
+	// The type is taken from the content, not from the name or the header
+	// the client sent.
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	head = head[:n]
+
+	contentType := mimetype.Detect(head).String()
+	if !config.AttachmentContentTypes[contentType] {
+		panic(exception.NewBadRequestHandler(fmt.Sprintf("file type '%s' is not allowed", contentType)))
+	}
+
+	dataset := model.CustomerAttachment{
+		CustomerID:  params.CustomerId,
+		FileName:    attachmentFileName(file.Filename),
+		ContentType: contentType,
+		Size:        file.Size,
+		StorageKey:  fmt.Sprintf("customers/%d/%s", params.CustomerId, uuid.New().String()),
+		UploadedBy:  utils.ActorFromContext(ctx),
+	}
+
+	err = service.fileStorage.Put(ctx, dataset.StorageKey, io.MultiReader(bytes.NewReader(head), src), file.Size, contentType)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	dataset, err = service.attachmentRepo.Insert(ctx, dataset)
+	if err != nil {
+		service.removeFile(dataset.StorageKey)
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	return attachmentResponse(dataset)
+}
+
+func (service *CustomerAttachmentServiceImpl) FindAll(ctx context.Context, params entity.CustomerParams) (response []entity.CustomerAttachmentResponse) {
+	_, err := service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	result, err := service.attachmentRepo.FindByCustomerId(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		response = append(response, attachmentResponse(row))
+	}
+	return response
+}
+
+// SignedURL hands out a short-lived download link. Backends that can sign
+// links themselves, such as S3, serve the file directly; otherwise the link
+// points at the API's own download endpoint.
+func (service *CustomerAttachmentServiceImpl) SignedURL(ctx context.Context, params entity.CustomerAttachmentParams) (response entity.AttachmentURLResponse) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	attachment := service.findAttachment(ctx, params)
+	expiresAt := time.Now().Add(service.options.URLTTL)
+	response.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+
+	if signer, ok := service.fileStorage.(storage.URLSigner); ok {
+		response.URL, err = signer.SignedURL(ctx, attachment.StorageKey, attachment.FileName, service.options.URLTTL)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		return response
+	}
+
+	expires := expiresAt.Unix()
+	response.URL = fmt.Sprintf("%s/api/v1/attachments/%d/download?expires=%d&signature=%s",
+		strings.TrimSuffix(service.options.PublicURL, "/"), attachment.ID, expires, service.signature(attachment.ID, expires))
+	return response
+}
+
+func (service *CustomerAttachmentServiceImpl) Download(ctx context.Context, request entity.DownloadAttachmentRequest) (response entity.CustomerAttachmentResponse, body io.ReadCloser) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	expected := service.signature(request.AttachmentId, request.Expires)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(request.Signature))) {
+		panic(exception.NewUnauthorizedHandler("invalid download signature"))
+	}
+	if time.Now().Unix() > request.Expires {
+		panic(exception.NewUnauthorizedHandler("download link has expired"))
+	}
+
+	attachment, err := service.attachmentRepo.FindById(ctx, request.AttachmentId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	body, err = service.fileStorage.Open(ctx, attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		panic(exception.NewNotFoundHandler("attachment content not found"))
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	return attachmentResponse(attachment), body
+}
+
+func (service *CustomerAttachmentServiceImpl) Delete(ctx context.Context, params entity.CustomerAttachmentParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	attachment := service.findAttachment(ctx, params)
+
+	err = service.attachmentRepo.Delete(ctx, attachment.CustomerID, attachment.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	service.removeFile(attachment.StorageKey)
+}
+
+// findAttachment loads the attachment, treating one of another customer as
+// missing.
+func (service *CustomerAttachmentServiceImpl) findAttachment(ctx context.Context, params entity.CustomerAttachmentParams) model.CustomerAttachment {
+	attachment, err := service.attachmentRepo.FindById(ctx, params.AttachmentId)
+	if err != nil || attachment.CustomerID != params.CustomerId {
+		panic(exception.NewNotFoundHandler("attachment not found"))
+	}
+	return attachment
+}
+
+// removeFile deletes stored content whose row is gone. A failure only leaves
+// an unreferenced file behind, so it is logged rather than returned.
+func (service *CustomerAttachmentServiceImpl) removeFile(key string) {
+	if err := service.fileStorage.Delete(context.Background(), key); err != nil {
+		log.Printf("attachment: removing %s: %v", key, err)
+	}
+}
+
+func (service *CustomerAttachmentServiceImpl) signature(attachmentId int, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(service.options.SigningKey))
+	mac.Write([]byte(strconv.Itoa(attachmentId) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// attachmentFileName keeps the base name of an upload, without any path the
+// client sent along.
+func attachmentFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+func attachmentResponse(value model.CustomerAttachment) entity.CustomerAttachmentResponse {
+	return entity.CustomerAttachmentResponse{
+		ID:          value.ID,
+		CustomerID:  value.CustomerID,
+		FileName:    value.FileName,
+		ContentType: value.ContentType,
+		Size:        value.Size,
+		UploadedBy:  value.UploadedBy,
+		CreatedAt:   value.CreatedAt.Format(time.RFC3339),
+	}
+}
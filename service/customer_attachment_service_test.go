@@ -0,0 +1,146 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"testing"
+	"time"
+)
+
+func newTestAttachmentService(t *testing.T) (*CustomerAttachmentServiceImpl, *fakeAttachmentRepo) {
+	attachments := &fakeAttachmentRepo{}
+	service := NewCustomerAttachmentServiceImpl(attachments, storedCustomers(), storage.NewLocalStorage(t.TempDir()), config.AttachmentOptions{
+		MaxSize:    1 << 20,
+		SigningKey: "secret",
+		PublicURL:  "https://crm.example.com/",
+	}, utils.InitializeValidator(nil))
+	return service.(*CustomerAttachmentServiceImpl), attachments
+}
+
+// uploaded turns content into the file header of a multipart upload.
+func uploaded(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(10 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return form.File["file"][0]
+}
+
+// zipped builds a zip archive holding the named, empty entries.
+func zipped(t *testing.T, names ...string) []byte {
+	var body bytes.Buffer
+	writer := zip.NewWriter(&body)
+	for _, name := range names {
+		if _, err := writer.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.Bytes()
+}
+
+func TestUploadAttachment(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+	}{
+		{"contract.pdf", []byte("%PDF-1.7\n1 0 obj\n"), "application/pdf"},
+		{"contract.docx", zipped(t, "[Content_Types].xml", "_rels/.rels", "word/document.xml"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"prices.xlsx", zipped(t, "[Content_Types].xml", "_rels/.rels", "xl/workbook.xml"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	}
+
+	for _, test := range tests {
+		service, attachments := newTestAttachmentService(t)
+
+		response := service.Upload(actorContext("mia@example.com"), entity.CustomerParams{CustomerId: 1}, uploaded(t, "../"+test.name, test.content))
+
+		if response.ContentType != test.contentType || response.FileName != test.name || response.UploadedBy != "mia@example.com" {
+			t.Errorf("%s: uploaded %+v", test.name, response)
+		}
+
+		body, err := service.fileStorage.Open(context.Background(), attachments.attachments[0].StorageKey)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		stored, _ := io.ReadAll(body)
+		body.Close()
+		if !bytes.Equal(stored, test.content) {
+			t.Errorf("%s: stored %d bytes, want the %d uploaded", test.name, len(stored), len(test.content))
+		}
+	}
+}
+
+func TestUploadAttachmentRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"archive.zip", zipped(t, "notes.txt")},
+		{"contract.docx", []byte("plain text pretending to be a document")},
+		{"empty.pdf", nil},
+		{"huge.pdf", append([]byte("%PDF-1.7\n"), make([]byte, 1<<20)...)},
+	}
+
+	for _, test := range tests {
+		service, attachments := newTestAttachmentService(t)
+
+		value := raised(func() {
+			service.Upload(actorContext("mia@example.com"), entity.CustomerParams{CustomerId: 1}, uploaded(t, test.name, test.content))
+		})
+		if _, ok := value.(*exception.BadRequestErrorStruct); !ok {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if len(attachments.attachments) != 0 {
+			t.Errorf("%s: attachment was stored", test.name)
+		}
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	service, _ := newTestAttachmentService(t)
+	attachment := service.Upload(actorContext("mia@example.com"), entity.CustomerParams{CustomerId: 1}, uploaded(t, "contract.pdf", []byte("%PDF-1.7\n")))
+
+	expires := time.Now().Add(time.Minute).Unix()
+	response, body := service.Download(context.Background(), entity.DownloadAttachmentRequest{
+		AttachmentId: attachment.ID,
+		Expires:      expires,
+		Signature:    service.signature(attachment.ID, expires),
+	})
+	body.Close()
+	if response.ID != attachment.ID {
+		t.Errorf("downloaded %+v", response)
+	}
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	tests := map[string]entity.DownloadAttachmentRequest{
+		"expired link":    {AttachmentId: attachment.ID, Expires: expired, Signature: service.signature(attachment.ID, expired)},
+		"extended link":   {AttachmentId: attachment.ID, Expires: expires + 3600, Signature: service.signature(attachment.ID, expires)},
+		"other file link": {AttachmentId: attachment.ID + 1, Expires: expires, Signature: service.signature(attachment.ID, expires)},
+	}
+	for name, request := range tests {
+		value := raised(func() { service.Download(context.Background(), request) })
+		if _, ok := value.(*exception.UnauthorizedErrorStruct); !ok {
+			t.Errorf("%s: raised %#v", name, value)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"testing"
+)
+
+func changeUsername(id int, version int, username string) entity.UpdateBatchCustomerItem {
+	return entity.UpdateBatchCustomerItem{ID: id, Version: version, Changes: entity.CustomerChanges{Username: &username}}
+}
+
+func changeEmail(id int, email string) entity.UpdateBatchCustomerItem {
+	return entity.UpdateBatchCustomerItem{ID: id, Changes: entity.CustomerChanges{Email: &email}}
+}
+
+func TestUpdateBatchCustomers(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   entity.UpdateBatchCustomerRequest
+		statuses  []string
+		usernames [2]string
+	}{
+		{
+			name: "all applied",
+			request: entity.UpdateBatchCustomerRequest{Items: []entity.UpdateBatchCustomerItem{
+				changeUsername(1, 3, "ann b"), changeUsername(2, 0, "bob b"),
+			}},
+			statuses:  []string{"updated", "updated"},
+			usernames: [2]string{"ann b", "bob b"},
+		},
+		{
+			name: "stale and unknown items are skipped",
+			request: entity.UpdateBatchCustomerRequest{Items: []entity.UpdateBatchCustomerItem{
+				changeUsername(1, 3, "ann b"), changeUsername(2, 9, "bob b"), changeUsername(9, 0, "eve"),
+			}},
+			statuses:  []string{"updated", "failed", "failed"},
+			usernames: [2]string{"ann b", "bob"},
+		},
+		{
+			name: "atomic batch with a failed item",
+			request: entity.UpdateBatchCustomerRequest{Atomic: true, Items: []entity.UpdateBatchCustomerItem{
+				changeUsername(1, 3, "ann b"), changeUsername(9, 0, "eve"),
+			}},
+			statuses:  []string{"rolled_back", "failed"},
+			usernames: [2]string{"ann", "bob"},
+		},
+		{
+			name: "customer listed twice",
+			request: entity.UpdateBatchCustomerRequest{Items: []entity.UpdateBatchCustomerItem{
+				changeUsername(1, 3, "ann b"), changeUsername(1, 3, "ann c"),
+			}},
+			statuses:  []string{"updated", "failed"},
+			usernames: [2]string{"ann b", "bob"},
+		},
+		{
+			name: "email given to two customers",
+			request: entity.UpdateBatchCustomerRequest{Items: []entity.UpdateBatchCustomerItem{
+				changeEmail(1, "Team@Example.com"), changeEmail(2, "team@example.com"),
+			}},
+			statuses:  []string{"updated", "failed"},
+			usernames: [2]string{"ann", "bob"},
+		},
+	}
+
+	for _, test := range tests {
+		customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+		service := newTestCustomerService(customers, revisions)
+
+		response := service.UpdateBatch(actorContext("admin@example.com"), test.request)
+
+		var updated int
+		for i, result := range response.Results {
+			if result.Status != test.statuses[i] {
+				t.Errorf("%s: item %d is %s (%s), want %s", test.name, i, result.Status, result.Error, test.statuses[i])
+			}
+			if result.Status == "updated" {
+				updated++
+			}
+		}
+		if response.Updated != updated || response.Applied != (updated > 0) || len(revisions.revisions) != updated {
+			t.Errorf("%s: updated %d, applied %v with %d revisions, want %d", test.name, response.Updated, response.Applied, len(revisions.revisions), updated)
+		}
+		if got := [2]string{customers.customers[1].Username, customers.customers[2].Username}; got != test.usernames {
+			t.Errorf("%s: usernames %v, want %v", test.name, got, test.usernames)
+		}
+	}
+}
+
+func TestUpdateBatchCustomersRejected(t *testing.T) {
+	email := "team@example.com"
+	tests := map[string]entity.UpdateBatchCustomerRequest{
+		"neither items nor filter": {},
+		"both items and filter":    {Items: []entity.UpdateBatchCustomerItem{changeUsername(1, 3, "ann b")}, Filter: "tag=vip"},
+		"email through a filter":   {Filter: "tag=vip", Changes: &entity.CustomerChanges{Email: &email}},
+	}
+
+	for name, request := range tests {
+		customers := storedCustomers()
+		service := newTestCustomerService(customers, &fakeRevisionRepo{})
+
+		value := raised(func() { service.UpdateBatch(actorContext("admin@example.com"), request) })
+		if _, ok := value.(*exception.BadRequestErrorStruct); !ok {
+			t.Errorf("%s: raised %#v", name, value)
+		}
+	}
+}
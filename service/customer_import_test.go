@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"testing"
+)
+
+// validImportFile updates ann and adds cid; importFile also has a row
+// with a bad email.
+const (
+	validImportFile = "Username,Email,Phone,Address\n" +
+		"ann b,ANN@example.com,+6281234567899,Jl. Gatot Subroto 3\n" +
+		"cid,cid@example.com,+6281234567892,Jl. Kuningan 4\n"
+	importFile = validImportFile + "dee,not an email,+6281234567893,Jl. Casablanca 5\n"
+)
+
+func newTestImport(t *testing.T, customers *fakeCustomerRepo, revisions *fakeRevisionRepo, request entity.ImportCustomerRequest, file string) (*CustomerServiceImpl, importedSheet[entity.CustomerResponse]) {
+	t.Helper()
+	service := newTestCustomerService(customers, revisions)
+	service.fileStorage = storage.NewLocalStorage(t.TempDir())
+
+	imported := service.readImport(actorContext("admin@example.com"), bytes.NewReader([]byte(file)), request, nil, importOptions("", ""))
+	return service, imported
+}
+
+func TestPreviewImport(t *testing.T) {
+	tests := []struct {
+		onConflict string
+		want       []string
+	}{
+		{onConflict: entity.OnConflictUpdate, want: []string{entity.ImportActionUpdate, entity.ImportActionInsert, entity.ImportActionReject}},
+		{onConflict: entity.OnConflictSkip, want: []string{entity.ImportActionSkip, entity.ImportActionInsert, entity.ImportActionReject}},
+	}
+
+	for _, test := range tests {
+		customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+		request := entity.ImportCustomerRequest{Mode: entity.ImportModeDryRun, OnConflict: test.onConflict}
+		service, imported := newTestImport(t, customers, revisions, request, importFile)
+
+		response := service.previewImport(actorContext("admin@example.com"), imported, request)
+
+		var actions []string
+		for i, row := range response.Rows {
+			if row.Row != i+2 {
+				t.Errorf("%s: preview row %d is file row %d, want %d", test.onConflict, i, row.Row, i+2)
+			}
+			actions = append(actions, row.Action)
+		}
+		if !reflect.DeepEqual(actions, test.want) {
+			t.Errorf("%s: actions %v, want %v", test.onConflict, actions, test.want)
+		}
+		if response.Inserted != 1 || response.Rejected != 1 || response.Updated+response.Skipped != 1 {
+			t.Errorf("%s: counts %+v", test.onConflict, response)
+		}
+		if len(response.Rows[2].Errors) == 0 {
+			t.Errorf("%s: rejected row has no errors", test.onConflict)
+		}
+		if response.ErrorFile == "" {
+			t.Errorf("%s: no error file for the rejected row", test.onConflict)
+		}
+
+		if len(customers.customers) != 2 || customers.customers[1].Username != "ann" || len(revisions.revisions) != 0 {
+			t.Errorf("%s: a dry run wrote customers %+v and revisions %+v", test.onConflict, customers.customers, revisions.revisions)
+		}
+	}
+}
+
+func TestWriteImportAtomic(t *testing.T) {
+	customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+	request := entity.ImportCustomerRequest{Mode: entity.ImportModeAtomic, OnConflict: entity.OnConflictUpdate}
+	service, imported := newTestImport(t, customers, revisions, request, importFile)
+
+	response, err := service.writeImport(actorContext("admin@example.com"), imported, request)
+
+	var validation *exception.ExcelValidation
+	if !errors.As(err, &validation) {
+		t.Fatalf("writeImport returned %v, want the row errors", err)
+	}
+	if len(validation.Errors) == 0 || validation.ErrorFile == "" {
+		t.Errorf("validation %+v, want errors and an error file", validation)
+	}
+	if !reflect.DeepEqual(response, entity.UpsertCustomerResponse{}) {
+		t.Errorf("response %+v, want nothing written", response)
+	}
+	if len(customers.customers) != 2 || customers.customers[1].Username != "ann" || len(revisions.revisions) != 0 {
+		t.Errorf("a failed atomic import wrote customers %+v and revisions %+v", customers.customers, revisions.revisions)
+	}
+}
+
+func TestWriteImportAtomicValid(t *testing.T) {
+	customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+	request := entity.ImportCustomerRequest{Mode: entity.ImportModeAtomic, OnConflict: entity.OnConflictUpdate}
+	service, imported := newTestImport(t, customers, revisions, request, validImportFile)
+
+	response, err := service.writeImport(actorContext("admin@example.com"), imported, request)
+	if err != nil {
+		t.Fatalf("writeImport: %v", err)
+	}
+
+	want := entity.UpsertCustomerResponse{Inserted: 1, Updated: 1, InsertedIDs: []int{3}, UpdatedIDs: []int{1}}
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("response %+v, want %+v", response, want)
+	}
+	if len(customers.customers) != 3 || customers.customers[1].Username != "ann b" {
+		t.Errorf("customers %+v, want ann renamed and cid added", customers.customers)
+	}
+}
+
+func TestWriteImportPartial(t *testing.T) {
+	customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+	request := entity.ImportCustomerRequest{Mode: entity.ImportModePartial, OnConflict: entity.OnConflictUpdate}
+	service, imported := newTestImport(t, customers, revisions, request, importFile)
+
+	response, err := service.writeImport(actorContext("admin@example.com"), imported, request)
+
+	var validation *exception.ExcelValidation
+	if !errors.As(err, &validation) || validation.ErrorFile == "" {
+		t.Fatalf("writeImport returned %v, want the row errors with an error file", err)
+	}
+	if response.Inserted != 1 || response.Updated != 1 {
+		t.Errorf("response %+v, want the valid rows written", response)
+	}
+	if len(customers.customers) != 3 {
+		t.Errorf("customers %+v, want cid added", customers.customers)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"encoding/json"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"testing"
+)
+
+func mergeCustomers() *fakeCustomerRepo {
+	return newFakeCustomerRepo(
+		model.Customer{ID: 1, Username: "ann", Email: "ann@example.com", CustomFields: map[string]interface{}{"industry": "retail"}, Version: 2},
+		model.Customer{ID: 2, Username: "ann s", Email: "ann.s@example.com", Phone: "+6281234567890", Address: "Jl. Sudirman 1",
+			CustomFields: map[string]interface{}{"industry": "finance", "employees": 12.0}, Version: 1},
+		model.Customer{ID: 3, Username: "bob", Email: "bob@example.com", Version: 1},
+	)
+}
+
+func TestMergeCustomers(t *testing.T) {
+	customers, revisions := mergeCustomers(), &fakeRevisionRepo{}
+	service := newTestCustomerService(customers, revisions)
+
+	response := service.Merge(actorContext("admin@example.com"), entity.MergeCustomerRequest{
+		SurvivorID:   1,
+		DuplicateIDs: []int{2},
+		Fields:       map[string]int{"email": 2},
+		Version:      2,
+	})
+
+	survivor := customers.customers[1]
+	if survivor.Username != "ann" || survivor.Email != "ann.s@example.com" || survivor.Phone != "+6281234567890" || survivor.Address != "Jl. Sudirman 1" {
+		t.Errorf("survivor %+v: want its own username, the chosen email and the blanks filled in", survivor)
+	}
+	if survivor.CustomFields["industry"] != "retail" || survivor.CustomFields["employees"] != 12.0 {
+		t.Errorf("custom fields %v: want the union with the survivor's values winning", survivor.CustomFields)
+	}
+	if _, ok := customers.customers[2]; ok {
+		t.Errorf("duplicate was not removed")
+	}
+	if response.ID != 1 || response.Version != 3 {
+		t.Errorf("response %d at version %d, want 1 at 3", response.ID, response.Version)
+	}
+
+	if len(revisions.revisions) != 2 || revisions.revisions[0].CustomerID != 1 || revisions.revisions[1].CustomerID != 2 {
+		t.Fatalf("recorded revisions %+v, want one for the survivor and one for the duplicate", revisions.revisions)
+	}
+	var changes map[string]entity.FieldChange
+	if err := json.Unmarshal(revisions.revisions[0].Changes, &changes); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := changes["merged_from"]; !ok || changes["email"].New != "ann.s@example.com" {
+		t.Errorf("survivor changes %v", changes)
+	}
+}
+
+func TestMergeCustomersRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.MergeCustomerRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "survivor among the duplicates",
+			request: entity.MergeCustomerRequest{SurvivorID: 1, DuplicateIDs: []int{2, 1}, Version: 2},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.BadRequestErrorStruct); return ok },
+		},
+		{
+			name:    "unknown duplicate",
+			request: entity.MergeCustomerRequest{SurvivorID: 1, DuplicateIDs: []int{9}, Version: 2},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:    "field taken from outside the merge",
+			request: entity.MergeCustomerRequest{SurvivorID: 1, DuplicateIDs: []int{2}, Fields: map[string]int{"phone": 3}, Version: 2},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.BadRequestErrorStruct); return ok },
+		},
+		{
+			name:    "stale survivor",
+			request: entity.MergeCustomerRequest{SurvivorID: 1, DuplicateIDs: []int{2}, Version: 1},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.PreconditionFailedErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		customers, revisions := mergeCustomers(), &fakeRevisionRepo{}
+		service := newTestCustomerService(customers, revisions)
+
+		value := raised(func() { service.Merge(actorContext("admin@example.com"), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if len(customers.customers) != 3 || len(revisions.revisions) != 0 {
+			t.Errorf("%s: customers were merged", test.name)
+		}
+	}
+}
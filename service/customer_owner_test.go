@@ -0,0 +1,150 @@
+package service
+
+import (
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"testing"
+)
+
+func ownerId(id int) *int {
+	return &id
+}
+
+func ownedCustomers() *fakeCustomerRepo {
+	return newFakeCustomerRepo(
+		model.Customer{ID: 1, Username: "ann", Email: "ann@example.com", OwnerID: ownerId(7), Version: 3},
+		model.Customer{ID: 2, Username: "bob", Email: "bob@example.com", Version: 1},
+		model.Customer{ID: 3, Username: "cid", Email: "cid@example.com", OwnerID: ownerId(7), Version: 2},
+	)
+}
+
+func TestAssignCustomerOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.AssignCustomerOwnerRequest
+		owner   *int
+	}{
+		{"assign", entity.AssignCustomerOwnerRequest{CustomerID: 2, OwnerID: ownerId(8), Version: 1}, ownerId(8)},
+		{"unassign", entity.AssignCustomerOwnerRequest{CustomerID: 1, Version: 3}, nil},
+	}
+
+	for _, test := range tests {
+		customers, revisions := ownedCustomers(), &fakeRevisionRepo{}
+		service := newTestCustomerService(customers, revisions)
+
+		response := service.AssignOwner(actorContext("admin@example.com"), test.request)
+
+		stored := customers.customers[test.request.CustomerID]
+		if (stored.OwnerID == nil) != (test.owner == nil) || (test.owner != nil && *stored.OwnerID != *test.owner) {
+			t.Errorf("%s: owner %v, want %v", test.name, stored.OwnerID, test.owner)
+		}
+		if response.Version != test.request.Version+1 {
+			t.Errorf("%s: response at version %d, want %d", test.name, response.Version, test.request.Version+1)
+		}
+		if len(revisions.revisions) != 1 || revisions.revisions[0].Action != model.RevisionAssign {
+			t.Errorf("%s: recorded revisions %+v, want one assignment", test.name, revisions.revisions)
+		}
+	}
+}
+
+func TestAssignCustomerOwnerRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.AssignCustomerOwnerRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "owner is not a user",
+			request: entity.AssignCustomerOwnerRequest{CustomerID: 2, OwnerID: ownerId(9), Version: 1},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.BadRequestErrorStruct); return ok },
+		},
+		{
+			name:    "unknown customer",
+			request: entity.AssignCustomerOwnerRequest{CustomerID: 9, OwnerID: ownerId(8), Version: 1},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:    "stale version",
+			request: entity.AssignCustomerOwnerRequest{CustomerID: 1, OwnerID: ownerId(8), Version: 2},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.PreconditionFailedErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		customers, revisions := ownedCustomers(), &fakeRevisionRepo{}
+		service := newTestCustomerService(customers, revisions)
+
+		value := raised(func() { service.AssignOwner(actorContext("admin@example.com"), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if len(revisions.revisions) != 0 {
+			t.Errorf("%s: owner was assigned", test.name)
+		}
+	}
+}
+
+func TestReassignCustomers(t *testing.T) {
+	customers, revisions := ownedCustomers(), &fakeRevisionRepo{}
+	service := newTestCustomerService(customers, revisions)
+
+	response := service.Reassign(actorContext("admin@example.com"), entity.ReassignCustomersRequest{FromOwnerID: 7, ToOwnerID: ownerId(8)})
+
+	if response.Reassigned != 2 || len(response.CustomerIDs) != 2 || response.CustomerIDs[0] != 1 || response.CustomerIDs[1] != 3 {
+		t.Errorf("reassigned %+v, want customers 1 and 3", response)
+	}
+	for _, id := range []int{1, 3} {
+		if owner := customers.customers[id].OwnerID; owner == nil || *owner != 8 {
+			t.Errorf("customer %d is owned by %v, want 8", id, owner)
+		}
+	}
+	if customers.customers[2].OwnerID != nil {
+		t.Errorf("unassigned customer was reassigned")
+	}
+	if len(revisions.revisions) != 2 {
+		t.Errorf("recorded %d revisions, want 2", len(revisions.revisions))
+	}
+
+	response = service.Reassign(actorContext("admin@example.com"), entity.ReassignCustomersRequest{FromOwnerID: 7})
+	if response.Reassigned != 0 || response.CustomerIDs == nil {
+		t.Errorf("reassigned %+v from an owner with no customers left", response)
+	}
+}
+
+func TestReassignCustomersRejected(t *testing.T) {
+	tests := map[string]entity.ReassignCustomersRequest{
+		"same owner":           {FromOwnerID: 7, ToOwnerID: ownerId(7)},
+		"new owner not a user": {FromOwnerID: 7, ToOwnerID: ownerId(9)},
+	}
+
+	for name, request := range tests {
+		customers := ownedCustomers()
+		service := newTestCustomerService(customers, &fakeRevisionRepo{})
+
+		value := raised(func() { service.Reassign(actorContext("admin@example.com"), request) })
+		if _, ok := value.(*exception.BadRequestErrorStruct); !ok {
+			t.Errorf("%s: raised %#v", name, value)
+		}
+		if *customers.customers[1].OwnerID != 7 {
+			t.Errorf("%s: customers were reassigned", name)
+		}
+	}
+}
+
+func TestResolveMine(t *testing.T) {
+	service := newTestCustomerService(ownedCustomers(), &fakeRevisionRepo{})
+
+	dataFilter := entity.CustomerQueryFilter{Mine: true}
+	service.resolveMine(actorContext("mia@example.com"), &dataFilter)
+	if dataFilter.OwnerID != 7 {
+		t.Errorf("mine resolved to owner %d, want 7", dataFilter.OwnerID)
+	}
+
+	value := raised(func() {
+		service.resolveMine(actorContext("ghost@example.com"), &entity.CustomerQueryFilter{Mine: true})
+	})
+	if _, ok := value.(*exception.UnauthorizedErrorStruct); !ok {
+		t.Errorf("unknown user raised %#v", value)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"log"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"time"
+)
+
+// erasedFields lists what Erase anonymizes, as recorded on the receipt.
+var erasedFields = []string{
+	"username",
+	"email",
+	"phone",
+	"address",
+	"custom_fields",
+	"addresses",
+	"history",
+	"activities",
+	"attachments",
+}
+
+type CustomerPrivacyService interface {
+	PersonalData(ctx context.Context, params entity.CustomerParams) (response entity.PersonalDataResponse)
+	Erase(ctx context.Context, request entity.EraseCustomerRequest) (response entity.CustomerErasureResponse)
+}
+
+type CustomerPrivacyServiceImpl struct {
+	customerRepo   repository.CustomerRepo
+	addressRepo    repository.CustomerAddressRepo
+	revisionRepo   repository.CustomerRevisionRepo
+	tagRepo        repository.TagRepo
+	activityRepo   repository.CustomerActivityRepo
+	attachmentRepo repository.CustomerAttachmentRepo
+	erasureRepo    repository.CustomerErasureRepo
+	fileStorage    storage.Storage
+	validate       *validator.Validate
+}
+
+func NewCustomerPrivacyServiceImpl(customerRepo repository.CustomerRepo, addressRepo repository.CustomerAddressRepo, revisionRepo repository.CustomerRevisionRepo, tagRepo repository.TagRepo, activityRepo repository.CustomerActivityRepo, attachmentRepo repository.CustomerAttachmentRepo, erasureRepo repository.CustomerErasureRepo, fileStorage storage.Storage, validate *validator.Validate) CustomerPrivacyService {
+	return &CustomerPrivacyServiceImpl{
+		customerRepo:   customerRepo,
+		addressRepo:    addressRepo,
+		revisionRepo:   revisionRepo,
+		tagRepo:        tagRepo,
+		activityRepo:   activityRepo,
+		attachmentRepo: attachmentRepo,
+		erasureRepo:    erasureRepo,
+		fileStorage:    fileStorage,
+		validate:       validate,
+	}
+}
+
+func (service *CustomerPrivacyServiceImpl) PersonalData(ctx context.Context, params entity.CustomerParams) (response entity.PersonalDataResponse) {
+	customer, err := service.customerRepo.FindById(ctx, params.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	helper.Automapper(customer, &response.Customer)
+
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, []int{customer.ID})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Customer.Tags = tags[customer.ID]
+
+	addresses, err := service.addressRepo.FindByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Addresses = []entity.CustomerAddressResponse{}
+	for _, address := range addresses {
+		var res entity.CustomerAddressResponse
+		helper.Automapper(address, &res)
+		response.Addresses = append(response.Addresses, res)
+	}
+
+	revisions, err := service.revisionRepo.FindAllByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.History = []entity.CustomerRevisionResponse{}
+	for _, revision := range revisions {
+		response.History = append(response.History, revisionResponse(revision))
+	}
+
+	activities, err := service.activityRepo.FindAllByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Activities = []entity.CustomerActivityResponse{}
+	for _, activity := range activities {
+		response.Activities = append(response.Activities, activityResponse(activity))
+	}
+
+	attachments, err := service.attachmentRepo.FindByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Attachments = []entity.CustomerAttachmentResponse{}
+	for _, attachment := range attachments {
+		response.Attachments = append(response.Attachments, attachmentResponse(attachment))
+	}
+
+	erasures, err := service.erasureRepo.FindByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Erasures = []entity.CustomerErasureResponse{}
+	for _, erasure := range erasures {
+		response.Erasures = append(response.Erasures, erasureResponse(erasure))
+	}
+
+	response.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	return response
+}
+
+func (service *CustomerPrivacyServiceImpl) Erase(ctx context.Context, request entity.EraseCustomerRequest) (response entity.CustomerErasureResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	customer, err := service.customerRepo.FindById(ctx, request.CustomerID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	// The placeholders stay unique per customer so the email constraint and
+	// per-customer counts keep working.
+	customer.Username = fmt.Sprintf("erased-%d", customer.ID)
+	customer.Email = fmt.Sprintf("erased-%d@erased.invalid", customer.ID)
+
+	receipt := model.CustomerErasure{
+		CustomerID:   customer.ID,
+		Actor:        utils.ActorFromContext(ctx),
+		Reason:       request.Reason,
+		ErasedFields: erasedFields,
+	}
+
+	attachments, err := service.attachmentRepo.FindByCustomerId(ctx, customer.ID)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	receipt, err = service.erasureRepo.Erase(ctx, customer, receipt)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, attachment := range attachments {
+		if err := service.fileStorage.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("erasure %d: removing %s: %v", receipt.ID, attachment.StorageKey, err)
+		}
+	}
+
+	return erasureResponse(receipt)
+}
+
+func erasureResponse(value model.CustomerErasure) entity.CustomerErasureResponse {
+	return entity.CustomerErasureResponse{
+		ID:           value.ID,
+		CustomerID:   value.CustomerID,
+		Actor:        value.Actor,
+		Reason:       value.Reason,
+		ErasedFields: value.ErasedFields,
+		CreatedAt:    value.CreatedAt.Format(time.RFC3339),
+	}
+}
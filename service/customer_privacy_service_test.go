@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"strings"
+	"testing"
+)
+
+// newTestPrivacyService stores one attachment file for each of customers 1
+// and 2.
+func newTestPrivacyService(t *testing.T, erasureRepo *fakeErasureRepo) (*CustomerPrivacyServiceImpl, storage.Storage) {
+	files := storage.NewLocalStorage(t.TempDir())
+	attachments := &fakeAttachmentRepo{attachments: []model.CustomerAttachment{
+		{ID: 1, CustomerID: 1, StorageKey: "attachments/1/contract.pdf"},
+		{ID: 2, CustomerID: 2, StorageKey: "attachments/2/contract.pdf"},
+	}}
+	for _, attachment := range attachments.attachments {
+		if err := files.Put(context.Background(), attachment.StorageKey, strings.NewReader("%PDF"), 4, "application/pdf"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := NewCustomerPrivacyServiceImpl(storedCustomers(), nil, nil, nil, nil, attachments, erasureRepo, files, utils.InitializeValidator(nil))
+	return service.(*CustomerPrivacyServiceImpl), files
+}
+
+func stored(files storage.Storage, key string) bool {
+	body, err := files.Open(context.Background(), key)
+	if err != nil {
+		return false
+	}
+	body.Close()
+	return true
+}
+
+func TestEraseCustomer(t *testing.T) {
+	erasures := &fakeErasureRepo{}
+	service, files := newTestPrivacyService(t, erasures)
+
+	response := service.Erase(actorContext("dpo@example.com"), entity.EraseCustomerRequest{CustomerID: 1, Reason: "request #42"})
+
+	if len(erasures.erased) != 1 {
+		t.Fatalf("erased %d customers, want 1", len(erasures.erased))
+	}
+	if customer := erasures.erased[0]; customer.Username != "erased-1" || customer.Email != "erased-1@erased.invalid" {
+		t.Errorf("erased as %s <%s>", customer.Username, customer.Email)
+	}
+	if response.CustomerID != 1 || response.Actor != "dpo@example.com" || response.Reason != "request #42" || !reflect.DeepEqual(response.ErasedFields, erasedFields) {
+		t.Errorf("receipt %+v", response)
+	}
+
+	if stored(files, "attachments/1/contract.pdf") {
+		t.Errorf("attachment of the erased customer was kept")
+	}
+	if !stored(files, "attachments/2/contract.pdf") {
+		t.Errorf("attachment of another customer was removed")
+	}
+}
+
+func TestEraseCustomerFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		request entity.EraseCustomerRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "unknown customer",
+			request: entity.EraseCustomerRequest{CustomerID: 9, Reason: "request #42"},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:    "erasure rolled back",
+			err:     errors.New("connection reset"),
+			request: entity.EraseCustomerRequest{CustomerID: 1, Reason: "request #42"},
+			check:   func(value interface{}) bool { _, ok := value.(*exception.InternalServerErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		service, files := newTestPrivacyService(t, &fakeErasureRepo{err: test.err})
+
+		value := raised(func() { service.Erase(actorContext("dpo@example.com"), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if !stored(files, "attachments/1/contract.pdf") {
+			t.Errorf("%s: attachment was removed", test.name)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/helper"
+	"scylla/pkg/tabular"
+	"scylla/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+// customerSchema describes customer imports and exports. The Owner column
+// resolves account managers through owners and custom fields follow the
+// fixed columns in definition order.
+func customerSchema(owners *customerOwners, definitions []model.CustomFieldDefinition) tabular.Schema[entity.CustomerResponse] {
+	schema := tabular.Schema[entity.CustomerResponse]{
+		Sheet: "Customer",
+		Columns: []tabular.Column[entity.CustomerResponse]{
+			{
+				Key:    "id",
+				Header: "ID",
+				Format: func(row entity.CustomerResponse) interface{} { return row.ID },
+			},
+			{
+				Key:      "username",
+				Header:   "Username",
+				Aliases:  []string{"Name", "Full Name", "Customer Name"},
+				Validate: "required",
+				Example:  "Jane Doe",
+				Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+					row.Username = value
+					return nil
+				},
+				Format: func(row entity.CustomerResponse) interface{} { return row.Username },
+			},
+			{
+				// Contacts are compared and stored in their normalized form.
+				Key:         "email",
+				Header:      "Email",
+				Aliases:     []string{"Email Address", "Mail"},
+				Validate:    "required,email",
+				Unique:      true,
+				Description: "Compared without regard to case. Rows matching a stored customer follow the on_conflict setting.",
+				Example:     "jane.doe@example.com",
+				Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+					row.Email = helper.NormalizeEmail(value)
+					return nil
+				},
+				Format: func(row entity.CustomerResponse) interface{} { return row.Email },
+			},
+			{
+				Key:         "phone",
+				Header:      "Phone",
+				Aliases:     []string{"Phone Number", "Mobile", "Telephone"},
+				Validate:    "required",
+				Description: "Numbers without a country code are read as " + helper.PhoneRegion() + " numbers.",
+				Example:     "+6281234567890",
+				Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+					phone, err := helper.NormalizePhone(value, helper.PhoneRegion())
+					if err != nil {
+						return fmt.Errorf("phone '%s' is not a valid phone number", value)
+					}
+					row.Phone = phone
+					return nil
+				},
+				Format: func(row entity.CustomerResponse) interface{} { return row.Phone },
+			},
+			{
+				Key:      "address",
+				Header:   "Address",
+				Aliases:  []string{"Street Address", "Full Address"},
+				Validate: "required",
+				Example:  "Jl. Sudirman No. 1, Jakarta",
+				Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+					row.Address = value
+					return nil
+				},
+				Format: func(row entity.CustomerResponse) interface{} { return row.Address },
+			},
+			{
+				Key:    "tags",
+				Header: "Tags",
+				Format: func(row entity.CustomerResponse) interface{} { return strings.Join(row.Tags, ", ") },
+			},
+			{
+				Key:    "status",
+				Header: "Status",
+				Format: func(row entity.CustomerResponse) interface{} { return row.Status },
+			},
+			{
+				// Holds the email of the customer's account manager.
+				Key:         "owner",
+				Header:      helper.OwnerExcelColumn,
+				Aliases:     []string{"Account Manager", "Owner Email"},
+				Optional:    true,
+				Description: "Email of the user who manages the customer.",
+				Example:     "manager@example.com",
+				Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+					id, ok := owners.id(ctx, helper.NormalizeEmail(value))
+					if !ok {
+						return fmt.Errorf("owner '%s' is not a user", value)
+					}
+					row.OwnerID = &id
+					return nil
+				},
+				Format: func(row entity.CustomerResponse) interface{} {
+					if row.OwnerID == nil {
+						return ""
+					}
+					return owners.email(*row.OwnerID)
+				},
+			},
+			{
+				Key:    "created_at",
+				Header: "CreatedAt",
+				Format: func(row entity.CustomerResponse) interface{} {
+					createdAt, err := time.Parse(time.RFC3339Nano, row.CreatedAt)
+					if err != nil {
+						return row.CreatedAt
+					}
+					return createdAt
+				},
+			},
+		},
+	}
+
+	for _, definition := range definitions {
+		definition := definition
+		column := tabular.Column[entity.CustomerResponse]{
+			Key:      definition.Key,
+			Header:   definition.Label,
+			Optional: true,
+			Parse: func(ctx context.Context, value string, row *entity.CustomerResponse) error {
+				normalized, err := helper.CustomFieldValue(definition, value)
+				if err != nil {
+					return err
+				}
+				if row.CustomFields == nil {
+					row.CustomFields = make(map[string]interface{})
+				}
+				row.CustomFields[definition.Key] = normalized
+				return nil
+			},
+			Format: func(row entity.CustomerResponse) interface{} {
+				value := row.CustomFields[definition.Key]
+				if text, ok := value.(string); ok && definition.Type == model.CustomFieldDate {
+					if date, ok := tabular.ParseDate(text); ok {
+						return date
+					}
+				}
+				return value
+			},
+		}
+		if definition.Required {
+			column.Validate = "required"
+		}
+		switch definition.Type {
+		case model.CustomFieldNumber:
+			column.Description = "A number."
+			column.Example = "10"
+		case model.CustomFieldBoolean:
+			column.Options = []string{"true", "false"}
+			column.Example = "true"
+		case model.CustomFieldDate:
+			column.Description = "A date written yyyy-mm-dd."
+			column.Example = "2026-01-31"
+		case model.CustomFieldEnum:
+			column.Options = definition.Options
+			if len(definition.Options) > 0 {
+				column.Example = definition.Options[0]
+			}
+		}
+		schema = schema.With(column)
+	}
+
+	return schema
+}
+
+// customerOwners resolves account managers for one import or export: owner
+// emails to user ids, looking each email up once, and user ids to emails.
+type customerOwners struct {
+	userRepo repository.UserRepo
+	mu       sync.Mutex
+	ids      map[string]int
+	emails   map[int]string
+}
+
+func newCustomerOwners(userRepo repository.UserRepo, emails map[int]string) *customerOwners {
+	return &customerOwners{
+		userRepo: userRepo,
+		ids:      make(map[string]int),
+		emails:   emails,
+	}
+}
+
+// id returns the id of the user with the normalized email.
+func (owners *customerOwners) id(ctx context.Context, email string) (int, bool) {
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	if id, ok := owners.ids[email]; ok {
+		return id, id != 0
+	}
+
+	user, err := owners.userRepo.FindByColumns(ctx, []string{"lower(email)"}, []any{email})
+	if err != nil {
+		owners.ids[email] = 0
+		return 0, false
+	}
+	owners.ids[email] = user.ID
+	return user.ID, true
+}
+
+// remember adds user emails by id, for exports that read customers in
+// batches.
+func (owners *customerOwners) remember(emails map[int]string) {
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	for id, email := range emails {
+		owners.emails[id] = email
+	}
+}
+
+func (owners *customerOwners) email(id int) string {
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	return owners.emails[id]
+}
@@ -1,88 +1,176 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"github.com/tealeg/xlsx"
+	"gorm.io/gorm"
+	"io"
+	"log"
 	"math"
 	"mime/multipart"
 	"scylla/entity"
 	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/event"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"scylla/pkg/tabular"
+	"scylla/pkg/utils"
 	"scylla/repository"
-	"sync"
+	"sort"
+	"strings"
 	"time"
 )
 
 type CustomerService interface {
 	Create(ctx context.Context, request entity.CreateCustomerRequest) error
-	CreateBatch(ctx context.Context, request entity.CreateCustomerBatchRequest)
+	CreateBatch(ctx context.Context, request entity.CreateCustomerBatchRequest) (response entity.UpsertCustomerResponse)
 	Update(ctx context.Context, request entity.UpdateCustomerRequest)
+	UpdateBatch(ctx context.Context, request entity.UpdateBatchCustomerRequest) (response entity.UpdateBatchCustomerResponse)
+	Delete(ctx context.Context, request entity.DeleteCustomerRequest)
 	DeleteBatch(ctx context.Context, request entity.DeleteBatchCustomerRequest)
 	FindById(ctx context.Context, request entity.CustomerParams) (response entity.CustomerResponse)
 	FindAll(ctx context.Context, dataFilter entity.CustomerQueryFilter) (response []entity.CustomerResponse)
 	FindAllPaging(ctx context.Context, dataFilter entity.CustomerQueryFilter) (response []entity.CustomerResponse, paging entity.Meta)
-	Export(ctx context.Context, dataFilter entity.CustomerQueryFilter) (string, error)
-	Import(ctx context.Context, file *multipart.FileHeader) error
+	Export(ctx context.Context, dataFilter entity.CustomerQueryFilter, options tabular.Options) (response entity.JobResponse)
+	ImportTemplate(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, file *multipart.FileHeader, request entity.ImportCustomerRequest) (response entity.JobResponse)
+	FindHistory(ctx context.Context, request entity.CustomerParams, dataFilter entity.CustomerHistoryQueryFilter) (response []entity.CustomerRevisionResponse, paging entity.Meta)
+	Revert(ctx context.Context, request entity.RevertCustomerRequest)
+	FindDuplicates(ctx context.Context, dataFilter entity.CustomerDuplicateQueryFilter) (response []entity.CustomerDuplicateResponse, paging entity.Meta)
+	Merge(ctx context.Context, request entity.MergeCustomerRequest) (response entity.CustomerResponse)
+	Transition(ctx context.Context, request entity.TransitionCustomerRequest) (response entity.CustomerResponse)
+	AssignOwner(ctx context.Context, request entity.AssignCustomerOwnerRequest) (response entity.CustomerResponse)
+	Reassign(ctx context.Context, request entity.ReassignCustomersRequest) (response entity.ReassignCustomersResponse)
 }
 
+// maxBatchUpdate caps how many customers one bulk update may touch.
+const maxBatchUpdate = 1000
+
+// upsertBatchSize is the number of rows written per INSERT statement.
+const upsertBatchSize = 500
+
 type CustomerServiceImpl struct {
-	customerRepo repository.CustomerRepo
-	validate     *validator.Validate
+	customerRepo      repository.CustomerRepo
+	revisionRepo      repository.CustomerRevisionRepo
+	transactor        repository.Transactor
+	tagRepo           repository.TagRepo
+	customFieldRepo   repository.CustomFieldRepo
+	userRepo          repository.UserRepo
+	importProfileRepo repository.ImportProfileRepo
+	lifecycle         config.CustomerLifecycle
+	eventBus          event.Bus
+	fileStorage       storage.Storage
+	jobService        JobService
+	importOptions     config.ImportOptions
+	validate          *validator.Validate
 }
 
-func NewCustomerServiceImpl(customerRepo repository.CustomerRepo, validate *validator.Validate) CustomerService {
+func NewCustomerServiceImpl(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo, transactor repository.Transactor, tagRepo repository.TagRepo, customFieldRepo repository.CustomFieldRepo, userRepo repository.UserRepo, importProfileRepo repository.ImportProfileRepo, lifecycle config.CustomerLifecycle, eventBus event.Bus, fileStorage storage.Storage, jobService JobService, importOptions config.ImportOptions, validate *validator.Validate) CustomerService {
 	return &CustomerServiceImpl{
-		customerRepo: customerRepo,
-		validate:     validate,
+		customerRepo:      customerRepo,
+		revisionRepo:      revisionRepo,
+		transactor:        transactor,
+		tagRepo:           tagRepo,
+		customFieldRepo:   customFieldRepo,
+		userRepo:          userRepo,
+		importProfileRepo: importProfileRepo,
+		lifecycle:         lifecycle,
+		eventBus:          eventBus,
+		fileStorage:       fileStorage,
+		jobService:        jobService,
+		importOptions:     importOptions,
+		validate:          validate,
 	}
 }
 
 func (service *CustomerServiceImpl) Create(ctx context.Context, request entity.CreateCustomerRequest) error {
+	request.Email = helper.NormalizeEmail(request.Email)
+	request.Phone = helper.FormatPhone(request.Phone)
+
 	err := service.validate.Struct(request)
 	helper.ErrorPanic(err)
 
+	definitions := service.customFieldDefinitions(ctx)
+
 	dataset := model.Customer{
-		Username: request.Username,
-		Email:    request.Email,
-		Phone:    request.Phone,
-		Address:  request.Address,
+		Username:     request.Username,
+		Email:        request.Email,
+		Phone:        request.Phone,
+		Address:      request.Address,
+		CustomFields: validateCustomFields(definitions, request.CustomFields, "custom_fields"),
+		Status:       config.InitialCustomerStatus,
+
+		StatusTimestamps: map[string]time.Time{config.InitialCustomerStatus: time.Now()},
 	}
 
-	err = service.customerRepo.Insert(ctx, dataset)
-	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
-	}
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		dataset, err = customerRepo.Insert(ctx, dataset)
+		if errors.Is(err, repository.ErrEmailTaken) {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("email '%s' already taken", dataset.Email)))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		service.recordRevision(ctx, revisionRepo, model.RevisionCreate, nil, &dataset)
+	})
 
 	return nil
 }
 
-func (service *CustomerServiceImpl) CreateBatch(ctx context.Context, request entity.CreateCustomerBatchRequest) {
-	err := service.validate.Struct(request)
+func (service *CustomerServiceImpl) CreateBatch(ctx context.Context, request entity.CreateCustomerBatchRequest) (response entity.UpsertCustomerResponse) {
+	emails := make(map[string]bool, len(request.Customers))
+	for i := range request.Customers {
+		request.Customers[i].Email = helper.NormalizeEmail(request.Customers[i].Email)
+		request.Customers[i].Phone = helper.FormatPhone(request.Customers[i].Phone)
+
+		email := request.Customers[i].Email
+		if email != "" && emails[email] {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("customers[%d].email '%s' is duplicated in the batch", i, email)))
+		}
+		emails[email] = true
+	}
+
+	if request.OnConflict == "" {
+		request.OnConflict = entity.OnConflictError
+	}
+
+	// Existing emails are only an error when the batch is not an upsert.
+	validateCtx := ctx
+	if request.OnConflict != entity.OnConflictError {
+		validateCtx = utils.SkipUnique(ctx)
+	}
+
+	err := service.validate.StructCtx(validateCtx, request)
 	helper.ErrorPanic(err)
 
+	definitions := service.customFieldDefinitions(ctx)
+
 	var customers []model.Customer
-	for _, req := range request.Customers {
+	for i, req := range request.Customers {
 		customer := model.Customer{
-			Username: req.Username,
-			Email:    req.Email,
-			Phone:    req.Phone,
-			Address:  req.Address,
+			Username:     req.Username,
+			Email:        req.Email,
+			Phone:        req.Phone,
+			Address:      req.Address,
+			CustomFields: validateCustomFields(definitions, req.CustomFields, fmt.Sprintf("customers[%d].custom_fields", i)),
 		}
 		customers = append(customers, customer)
 	}
 
-	batchSize := len(request.Customers)
-
-	err = service.customerRepo.InsertBatch(ctx, customers, batchSize)
-	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
-	}
+	return service.upsert(ctx, customers, request.OnConflict, model.RevisionCreate)
 }
 
 func (service *CustomerServiceImpl) Update(ctx context.Context, request entity.UpdateCustomerRequest) {
+	request.Email = helper.NormalizeEmail(request.Email)
+	request.Phone = helper.FormatPhone(request.Phone)
+
 	err := service.validate.Struct(request)
 	helper.ErrorPanic(err)
 
@@ -91,24 +179,273 @@ func (service *CustomerServiceImpl) Update(ctx context.Context, request entity.U
 		panic(exception.NewNotFoundHandler(err.Error()))
 	}
 
+	if dataset.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
+
+	service.checkEmailAvailable(ctx, request.Email, request.ID)
+
+	customFields := validateCustomFields(service.customFieldDefinitions(ctx), request.CustomFields, "custom_fields")
+
+	before := dataset
 	dataset.Username = request.Username
 	dataset.Email = request.Email
 	dataset.Phone = request.Phone
 	dataset.Address = request.Address
+	dataset.CustomFields = customFields
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		err = customerRepo.Update(ctx, dataset)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if errors.Is(err, repository.ErrEmailTaken) {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("email '%s' already taken", dataset.Email)))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		dataset.Version++
+		service.recordRevision(ctx, revisionRepo, model.RevisionUpdate, &before, &dataset)
+	})
+}
+
+func (service *CustomerServiceImpl) UpdateBatch(ctx context.Context, request entity.UpdateBatchCustomerRequest) (response entity.UpdateBatchCustomerResponse) {
+	if (len(request.Items) == 0) == (request.Filter == "") {
+		panic(exception.NewBadRequestHandler("either items or filter is required"))
+	}
+
+	for i := range request.Items {
+		normalizeChanges(&request.Items[i].Changes)
+	}
+	if request.Changes != nil {
+		normalizeChanges(request.Changes)
+	}
+
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	// Items name the customers directly; a filter applies one change set to
+	// every match.
+	items := request.Items
+	if request.Filter != "" {
+		if request.Changes.Email != nil {
+			panic(exception.NewBadRequestHandler("email cannot be changed through a filter"))
+		}
+
+		dataFilter, err := helper.ParseCustomerFilter(request.Filter)
+		if err != nil {
+			panic(exception.NewBadRequestHandler(err.Error()))
+		}
+
+		matches, err := service.customerRepo.FindAll(ctx, dataFilter)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		if len(matches) > maxBatchUpdate {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("filter matches %d customers, at most %d can be updated at once", len(matches), maxBatchUpdate)))
+		}
+
+		for _, match := range matches {
+			items = append(items, entity.UpdateBatchCustomerItem{ID: match.ID, Changes: *request.Changes})
+		}
+	}
+
+	var ids []int
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
 
-	err = service.customerRepo.Update(ctx, dataset)
+	customers, err := service.customerRepo.FindByIds(ctx, ids)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	byId := make(map[int]model.Customer, len(customers))
+	for _, customer := range customers {
+		byId[customer.ID] = customer
+	}
+
+	definitions := service.customFieldDefinitions(ctx)
+
+	response.Results = make([]entity.UpdateBatchCustomerResult, len(items))
+	seen := make(map[int]bool, len(items))
+	emails := make(map[string]int)
+
+	var before, after []model.Customer
+	var positions []int
+	for i, item := range items {
+		result := &response.Results[i]
+		result.ID = item.ID
+
+		current, ok := byId[item.ID]
+		switch {
+		case seen[item.ID]:
+			result.Error = "customer is listed more than once"
+		case !ok:
+			result.Error = "record not found"
+		case item.Version != 0 && item.Version != current.Version:
+			result.Error = repository.ErrVersionConflict.Error()
+		}
+		seen[item.ID] = true
+		if result.Error != "" {
+			result.Status = "failed"
+			continue
+		}
+
+		dataset := current
+		applyChanges(&dataset, item.Changes)
+
+		if item.Changes.CustomFields != nil {
+			merged := make(map[string]interface{})
+			for key, value := range current.CustomFields {
+				merged[key] = value
+			}
+			for key, value := range item.Changes.CustomFields {
+				merged[key] = value
+			}
+
+			customFields, errs := helper.ValidateCustomFields(definitions, merged)
+			if len(errs) > 0 {
+				result.Status = "failed"
+				result.Error = "custom_fields: " + strings.Join(errs, "; ")
+				continue
+			}
+			dataset.CustomFields = customFields
+		}
+
+		if item.Changes.Email != nil && dataset.Email != current.Email {
+			owner, err := service.customerRepo.FindByColumns(ctx, []string{"lower(email)"}, []any{dataset.Email})
+			if other, taken := emails[dataset.Email]; (err == nil && owner.ID != item.ID) || (taken && other != item.ID) {
+				result.Status = "failed"
+				result.Error = fmt.Sprintf("email '%s' already taken", dataset.Email)
+				continue
+			}
+			emails[dataset.Email] = item.ID
+		}
+
+		before = append(before, current)
+		after = append(after, dataset)
+		positions = append(positions, i)
+	}
+
+	for i := range response.Results {
+		if response.Results[i].Status == "failed" {
+			response.Failed++
+		}
+	}
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		// In atomic mode nothing is written once an item has failed.
+		if !request.Atomic || response.Failed == 0 {
+			errs, err := customerRepo.UpdateBatch(ctx, after, request.Atomic)
+			if err != nil {
+				panic(exception.NewInternalServerErrorHandler(err.Error()))
+			}
+
+			for j, err := range errs {
+				if err != nil {
+					response.Results[positions[j]].Status = "failed"
+					response.Results[positions[j]].Error = err.Error()
+					response.Failed++
+				}
+			}
+		}
+
+		rolledBack := request.Atomic && response.Failed > 0
+
+		var revisions []model.CustomerRevision
+		for j, i := range positions {
+			result := &response.Results[i]
+			if result.Status == "failed" {
+				continue
+			}
+			if rolledBack {
+				result.Status = "rolled_back"
+				continue
+			}
+
+			after[j].Version++
+			result.Status = "updated"
+			result.Version = after[j].Version
+			response.Updated++
+			revisions = append(revisions, service.newRevision(ctx, model.RevisionUpdate, &before[j], &after[j]))
+		}
+
+		err := revisionRepo.InsertBatch(ctx, revisions, len(revisions))
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	})
+
+	response.Applied = response.Updated > 0
+	return response
+}
+
+func (service *CustomerServiceImpl) Delete(ctx context.Context, request entity.DeleteCustomerRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.customerRepo.FindById(ctx, request.ID)
 	if err != nil {
 		panic(exception.NewNotFoundHandler(err.Error()))
 	}
+
+	var storageKeys []string
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		storageKeys, err = customerRepo.Delete(ctx, dataset.ID, request.Version)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		service.recordRevision(ctx, revisionRepo, model.RevisionDelete, &dataset, nil)
+	})
+
+	service.removeAttachmentFiles(ctx, storageKeys)
 }
 
 func (service *CustomerServiceImpl) DeleteBatch(ctx context.Context, request entity.DeleteBatchCustomerRequest) {
 	err := service.validate.Struct(request)
 	helper.ErrorPanic(err)
 
-	err = service.customerRepo.DeleteBatch(ctx, request.ID)
+	customers, err := service.customerRepo.FindByIds(ctx, request.ID)
 	if err != nil {
-		panic(exception.NewNotFoundHandler(err.Error()))
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	var storageKeys []string
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		storageKeys, err = customerRepo.DeleteBatch(ctx, request.ID)
+		if err != nil {
+			panic(exception.NewNotFoundHandler(err.Error()))
+		}
+
+		var revisions []model.CustomerRevision
+		for i := range customers {
+			revisions = append(revisions, service.newRevision(ctx, model.RevisionDelete, &customers[i], nil))
+		}
+
+		err = revisionRepo.InsertBatch(ctx, revisions, len(revisions))
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	})
+
+	service.removeAttachmentFiles(ctx, storageKeys)
+}
+
+// removeAttachmentFiles deletes the stored files of attachments whose rows
+// were deleted with their customer. A failure only leaves an orphaned file
+// behind, so it is logged rather than failing the committed delete.
+func (service *CustomerServiceImpl) removeAttachmentFiles(ctx context.Context, storageKeys []string) {
+	for _, key := range storageKeys {
+		if err := service.fileStorage.Delete(ctx, key); err != nil {
+			log.Printf("customer: removing %s: %v", key, err)
+		}
 	}
 }
 
@@ -120,10 +457,19 @@ func (service *CustomerServiceImpl) FindById(ctx context.Context, request entity
 	}
 
 	helper.Automapper(result, &response)
+
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, []int{result.ID})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Tags = tags[result.ID]
+
 	return response
 }
 
 func (service *CustomerServiceImpl) FindAll(ctx context.Context, dataFilter entity.CustomerQueryFilter) (response []entity.CustomerResponse) {
+	service.resolveMine(ctx, &dataFilter)
+
 	result, err := service.customerRepo.FindAll(ctx, dataFilter)
 
 	if err != nil {
@@ -135,10 +481,14 @@ func (service *CustomerServiceImpl) FindAll(ctx context.Context, dataFilter enti
 		helper.Automapper(row, &res)
 		response = append(response, res)
 	}
-	return response
+	return service.withTags(ctx, response)
 }
 
 func (service *CustomerServiceImpl) FindAllPaging(ctx context.Context, dataFilter entity.CustomerQueryFilter) (response []entity.CustomerResponse, paging entity.Meta) {
+	if _, err := helper.ParseCustomerSort(dataFilter.Sort); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	service.resolveMine(ctx, &dataFilter)
 
 	result := service.customerRepo.FindAllPaging(ctx, dataFilter)
 
@@ -148,6 +498,7 @@ func (service *CustomerServiceImpl) FindAllPaging(ctx context.Context, dataFilte
 
 		response = append(response, res)
 	}
+	response = service.withTags(ctx, response)
 
 	if dataFilter.Limit == 0 {
 		dataFilter.Limit = 10
@@ -172,183 +523,834 @@ func (service *CustomerServiceImpl) FindAllPaging(ctx context.Context, dataFilte
 	return response, paging
 }
 
-func (service *CustomerServiceImpl) Export(ctx context.Context, dataFilter entity.CustomerQueryFilter) (string, error) {
-	// Create a new Excel file
-	file := xlsx.NewFile()
-	sheet, err := file.AddSheet("Customer")
+// Export enqueues a job writing the customers matching dataFilter to a file
+// in the format and with the columns of options.
+func (service *CustomerServiceImpl) Export(ctx context.Context, dataFilter entity.CustomerQueryFilter, options tabular.Options) (response entity.JobResponse) {
+	service.resolveMine(ctx, &dataFilter)
+
+	schema := customerSchema(newCustomerOwners(service.userRepo, nil), service.customFieldDefinitions(ctx))
+	if _, err := schema.Select(options.Columns); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+
+	job := model.Job{
+		Type:        model.JobExport,
+		Resource:    model.ImportResourceCustomers,
+		FileName:    options.FileName("customer"),
+		ContentType: options.ContentType(),
+	}
+	return service.jobService.Enqueue(ctx, job, nil, func(ctx context.Context, upload *bytes.Reader, output io.Writer) (interface{}, error) {
+		rows, err := service.export(ctx, dataFilter, options, output)
+		return entity.ExportResponse{Rows: rows}, err
+	})
+}
+
+// export streams the customers matching dataFilter to w, reading them from
+// the database in batches; tags and owners are looked up per batch.
+func (service *CustomerServiceImpl) export(ctx context.Context, dataFilter entity.CustomerQueryFilter, options tabular.Options, w io.Writer) (int, error) {
+	total, err := service.customerRepo.Count(ctx, dataFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	owners := newCustomerOwners(service.userRepo, make(map[int]string))
+	schema := customerSchema(owners, service.customFieldDefinitions(ctx))
+
+	return writeSheet(ctx, w, schema, options, total, func(write func(batch []entity.CustomerResponse) error) error {
+		return service.customerRepo.FindAllInBatches(ctx, dataFilter, exportBatchSize, func(batch []entity.CustomerResponse) error {
+			batch = service.withTags(ctx, batch)
+			owners.remember(service.ownerEmails(ctx, batch))
+			return write(batch)
+		})
+	})
+}
+
+// ImportTemplate builds the workbook partners fill in for Import, with the
+// columns and rules of the current custom field definitions.
+func (service *CustomerServiceImpl) ImportTemplate(ctx context.Context, w io.Writer) error {
+	owners := newCustomerOwners(service.userRepo, nil)
+	schema := customerSchema(owners, service.customFieldDefinitions(ctx))
+
+	return writeTemplate(w, schema)
+}
+
+// Import checks the request and enqueues a job importing the file, or only
+// previewing the import with mode=dry_run.
+func (service *CustomerServiceImpl) Import(ctx context.Context, file *multipart.FileHeader, request entity.ImportCustomerRequest) (response entity.JobResponse) {
+	request = service.importRequest(request)
+	mapping := importMapping(ctx, service.importProfileRepo, model.ImportResourceCustomers, request.Profile, request.Mapping)
+	options := importOptions(request.Delimiter, request.Encoding)
+
+	job := model.Job{Type: model.JobImport, Resource: model.ImportResourceCustomers}
+	return service.jobService.Enqueue(ctx, job, file, func(ctx context.Context, upload *bytes.Reader, output io.Writer) (interface{}, error) {
+		imported := service.readImport(ctx, upload, request, mapping, options)
+		if request.Mode == entity.ImportModeDryRun {
+			return service.previewImport(ctx, imported, request), nil
+		}
+		return service.writeImport(ctx, imported, request)
+	})
+}
+
+// writeImport writes the valid rows of an import. Invalid rows are returned
+// as an *exception.ExcelValidation, after writing the valid ones unless the
+// import is atomic.
+func (service *CustomerServiceImpl) writeImport(ctx context.Context, imported importedSheet[entity.CustomerResponse], request entity.ImportCustomerRequest) (response entity.UpsertCustomerResponse, err error) {
+	excelValidation := exception.ExcelValidation{}
+	for _, result := range imported.rejected {
+		for _, issue := range result.issues {
+			excelValidation.AddHandler(issue.Field, issue.Row, issue.Message)
+		}
+	}
+
+	// Atomic imports write nothing unless every row is valid, and then
+	// write all of them in a single upsert, which is one transaction.
+	batchSize := service.importOptions.BatchSize
+	if request.Mode == entity.ImportModeAtomic {
+		if len(excelValidation.Errors) > 0 {
+			excelValidation.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, true)
+			return response, &excelValidation
+		}
+		batchSize = len(imported.valid)
+	}
+
+	customers := make([]model.Customer, len(imported.valid))
+	for i, result := range imported.valid {
+		customers[i] = model.Customer{
+			Username:     result.value.Username,
+			Email:        result.value.Email,
+			Phone:        result.value.Phone,
+			Address:      result.value.Address,
+			CustomFields: result.value.CustomFields,
+			OwnerID:      result.value.OwnerID,
+		}
+	}
+
+	err = importChunks(ctx, customers, batchSize, func(chunk []model.Customer) error {
+		result := service.upsert(ctx, chunk, request.OnConflict, model.RevisionImport)
+		response.Inserted += result.Inserted
+		response.Updated += result.Updated
+		response.Skipped += result.Skipped
+		response.InsertedIDs = append(response.InsertedIDs, result.InsertedIDs...)
+		response.UpdatedIDs = append(response.UpdatedIDs, result.UpdatedIDs...)
+		response.SkippedIDs = append(response.SkippedIDs, result.SkippedIDs...)
+		return nil
+	})
+	if err != nil {
+		return response, exception.NewInternalServerErrorHandler(err.Error())
+	}
+
+	if len(excelValidation.Errors) > 0 {
+		excelValidation.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, false)
+		return response, &excelValidation
+	}
+
+	return response, nil
+}
+
+// previewImport tells what writeImport would do with each row.
+func (service *CustomerServiceImpl) previewImport(ctx context.Context, imported importedSheet[entity.CustomerResponse], request entity.ImportCustomerRequest) (response entity.ImportPreviewResponse) {
+	valid, rejected := imported.valid, imported.rejected
+
+	// With on_conflict=error existing emails were already rejected.
+	existing := make(map[string]bool)
+	if request.OnConflict != entity.OnConflictError && len(valid) > 0 {
+		var emails []string
+		for _, result := range valid {
+			emails = append(emails, result.value.Email)
+		}
+
+		customers, err := service.customerRepo.FindByEmails(ctx, emails)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		for _, customer := range customers {
+			existing[customer.Email] = true
+		}
+	}
+
+	response.Rows = []entity.ImportPreviewRow{}
+	for _, result := range valid {
+		row := entity.ImportPreviewRow{Row: result.rowIndex + 1, Action: entity.ImportActionInsert, Email: result.value.Email}
+		switch {
+		case !existing[row.Email]:
+			response.Inserted++
+		case request.OnConflict == entity.OnConflictUpdate:
+			row.Action = entity.ImportActionUpdate
+			response.Updated++
+		default:
+			row.Action = entity.ImportActionSkip
+			response.Skipped++
+		}
+		response.Rows = append(response.Rows, row)
+	}
+
+	for _, result := range rejected {
+		response.Rejected++
+		response.Rows = append(response.Rows, importPreviewRejected(result, result.value.Email))
+	}
+
+	sort.Slice(response.Rows, func(i, j int) bool {
+		return response.Rows[i].Row < response.Rows[j].Row
+	})
+	response.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, true)
+	return response
+}
+
+func (service *CustomerServiceImpl) importRequest(request entity.ImportCustomerRequest) entity.ImportCustomerRequest {
+	err := service.validate.Struct(request)
 	helper.ErrorPanic(err)
 
-	headers := []string{"ID", "Username", "Email", "Phone", "Address", "CreatedAt"}
-	headerRow := sheet.AddRow()
+	if request.OnConflict == "" {
+		request.OnConflict = entity.OnConflictError
+	}
+	if request.Mode == "" {
+		request.Mode = entity.ImportModePartial
+	}
+	return request
+}
+
+// readImport validates every row of the uploaded file.
+func (service *CustomerServiceImpl) readImport(ctx context.Context, file *bytes.Reader, request entity.ImportCustomerRequest, mapping map[string]string, options tabular.Options) importedSheet[entity.CustomerResponse] {
+	owners := newCustomerOwners(service.userRepo, nil)
+	schema := customerSchema(owners, service.customFieldDefinitions(ctx))
+
+	// Check uniqueness in the database; upserts resolve existing emails
+	// themselves.
+	var exists existsFunc
+	if request.OnConflict == entity.OnConflictError {
+		exists = func(ctx context.Context, key string, value string) bool {
+			return service.customerRepo.CheckColumnExists(ctx, "lower("+key+")", value)
+		}
+	}
+
+	return readSheet(ctx, file, schema, mapping, options, service.validate, service.importOptions.Workers, exists)
+}
+
+func (service *CustomerServiceImpl) FindHistory(ctx context.Context, request entity.CustomerParams, dataFilter entity.CustomerHistoryQueryFilter) (response []entity.CustomerRevisionResponse, paging entity.Meta) {
+	_, err := service.customerRepo.FindById(ctx, request.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
 
-	// Define the style for the header row
-	style := xlsx.NewStyle()
-	style.Font = *xlsx.NewFont(12, "Calibri")
-	style.Fill = *xlsx.NewFill("solid", "00FFFF00", "00FFFF00") // Yellow background
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
 
-	for _, header := range headers {
-		cell := headerRow.AddCell()
-		cell.Value = header
-		cell.SetStyle(style)
+	if dataFilter.Page == 0 {
+		dataFilter.Page = 1
 	}
 
-	result, err := service.customerRepo.FindAll(ctx, dataFilter)
+	result, total, err := service.revisionRepo.FindByCustomerId(ctx, request.CustomerId, dataFilter)
 	if err != nil {
 		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
 
-	dataStyle := xlsx.NewStyle()
-	dataStyle.Font = *xlsx.NewFont(12, "Calibri")
-
 	for _, value := range result {
-		dataRow := sheet.AddRow()
-		cell := dataRow.AddCell()
-		cell.SetInt(int(value.ID))
-		cell.SetStyle(dataStyle)
+		response = append(response, revisionResponse(value))
+	}
+
+	paging.Page = dataFilter.Page
+	paging.Limit = dataFilter.Limit
+	paging.TotalData = int(total)
+	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+
+	return response, paging
+}
+
+func (service *CustomerServiceImpl) Revert(ctx context.Context, request entity.RevertCustomerRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	revision, err := service.revisionRepo.FindById(ctx, request.RevisionId)
+	if err != nil || revision.CustomerID != request.CustomerId {
+		panic(exception.NewNotFoundHandler("revision not found"))
+	}
+
+	var target model.Customer
+	if len(revision.Snapshot) == 0 || json.Unmarshal(revision.Snapshot, &target) != nil || target.ID == 0 {
+		panic(exception.NewBadRequestHandler("revision has no state to restore"))
+	}
+
+	// A deleted customer has no version to match and is not brought back.
+	current, err := service.customerRepo.FindById(ctx, request.CustomerId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if current.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
 
-		cell = dataRow.AddCell()
-		cell.Value = value.Username
-		cell.SetStyle(dataStyle)
+	service.checkEmailAvailable(ctx, target.Email, request.CustomerId)
 
-		cell = dataRow.AddCell()
-		cell.Value = value.Email
-		cell.SetStyle(dataStyle)
+	before := current
+	current.Username = target.Username
+	current.Email = target.Email
+	current.Phone = target.Phone
+	current.Address = target.Address
+	current.CustomFields = target.CustomFields
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		err = customerRepo.Update(ctx, current)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if errors.Is(err, repository.ErrEmailTaken) {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("email '%s' already taken", current.Email)))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
 
-		cell = dataRow.AddCell()
-		cell.Value = value.Phone
-		cell.SetStyle(dataStyle)
+		current.Version++
+		service.recordRevision(ctx, revisionRepo, model.RevisionRevert, &before, &current)
+	})
+}
 
-		cell = dataRow.AddCell()
-		cell.Value = value.Address
-		cell.SetStyle(dataStyle)
+func (service *CustomerServiceImpl) FindDuplicates(ctx context.Context, dataFilter entity.CustomerDuplicateQueryFilter) (response []entity.CustomerDuplicateResponse, paging entity.Meta) {
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
 
-		cell = dataRow.AddCell()
-		cell.Value = value.CreatedAt
-		cell.SetStyle(dataStyle)
+	if dataFilter.Page == 0 {
+		dataFilter.Page = 1
 	}
 
-	// Save the Excel file
-	timestamp := time.Now().Format("2006-01-02_150405")
-	filePath := fmt.Sprintf("customer_%s.xlsx", timestamp)
+	if dataFilter.MinScore < 0 || dataFilter.MinScore > 1 {
+		panic(exception.NewBadRequestHandler("min_score must be between 0 and 1"))
+	}
 
-	err = file.Save(filePath)
+	response, total, err := service.customerRepo.FindDuplicates(ctx, dataFilter)
 	if err != nil {
-		return "", err
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
-	return filePath, nil
+
+	paging.Page = dataFilter.Page
+	paging.Limit = dataFilter.Limit
+	paging.TotalData = int(total)
+	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+
+	return response, paging
 }
 
-func (service *CustomerServiceImpl) Import(ctx context.Context, file *multipart.FileHeader) error {
-	src, err := file.Open()
+func (service *CustomerServiceImpl) Merge(ctx context.Context, request entity.MergeCustomerRequest) (response entity.CustomerResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	ids := []int{request.SurvivorID}
+	for _, id := range request.DuplicateIDs {
+		if id == request.SurvivorID {
+			panic(exception.NewBadRequestHandler("survivor_id must not be listed in duplicate_ids"))
+		}
+		ids = append(ids, id)
+	}
+
+	customers, err := service.customerRepo.FindByIds(ctx, ids)
 	if err != nil {
 		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
-	defer src.Close()
 
-	xlFile, err := xlsx.OpenReaderAt(src, file.Size)
+	if missing := missingIds(ids, len(customers), func(i int) int { return customers[i].ID }); len(missing) > 0 {
+		panic(exception.NewNotFoundHandler(fmt.Sprintf("customers not found: %v", missing)))
+	}
+
+	byId := make(map[int]model.Customer, len(customers))
+	for _, customer := range customers {
+		byId[customer.ID] = customer
+	}
+
+	for field, id := range request.Fields {
+		if _, ok := byId[id]; !ok {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("fields.%s: customer %d is not part of the merge", field, id)))
+		}
+	}
+
+	survivor := byId[request.SurvivorID]
+	if survivor.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
+
+	duplicates := make([]model.Customer, 0, len(request.DuplicateIDs))
+	for _, id := range request.DuplicateIDs {
+		duplicates = append(duplicates, byId[id])
+	}
+
+	before := survivor
+	pick := func(field string, value func(model.Customer) string) string {
+		if id, ok := request.Fields[field]; ok {
+			return value(byId[id])
+		}
+		if value(survivor) != "" {
+			return value(survivor)
+		}
+		for _, duplicate := range duplicates {
+			if value(duplicate) != "" {
+				return value(duplicate)
+			}
+		}
+		return ""
+	}
+
+	survivor.Username = pick("username", func(c model.Customer) string { return c.Username })
+	survivor.Email = pick("email", func(c model.Customer) string { return c.Email })
+	survivor.Phone = pick("phone", func(c model.Customer) string { return c.Phone })
+	survivor.Address = pick("address", func(c model.Customer) string { return c.Address })
+
+	// Custom fields are unioned; the survivor's own values win.
+	customFields := make(map[string]interface{})
+	for i := len(duplicates) - 1; i >= 0; i-- {
+		for key, value := range duplicates[i].CustomFields {
+			customFields[key] = value
+		}
+	}
+	for key, value := range before.CustomFields {
+		customFields[key] = value
+	}
+	survivor.CustomFields = customFields
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		moved, err := customerRepo.Merge(ctx, survivor, request.DuplicateIDs)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		survivor.Version++
+
+		revision := service.newRevision(ctx, model.RevisionMerge, &before, &survivor)
+		changes := helper.Diff(&before, &survivor, "id", "version", "status_timestamps", "created_at", "updated_at")
+		changes["merged_from"] = entity.FieldChange{New: request.DuplicateIDs}
+		if len(moved.ActivityIDs) > 0 {
+			changes["moved_activities"] = entity.FieldChange{New: moved.ActivityIDs}
+		}
+		if len(moved.AttachmentIDs) > 0 {
+			changes["moved_attachments"] = entity.FieldChange{New: moved.AttachmentIDs}
+		}
+		revision.Changes = json.RawMessage(helper.StructToJson(changes))
+
+		revisions := []model.CustomerRevision{revision}
+		for i := range duplicates {
+			revisions = append(revisions, service.newRevision(ctx, model.RevisionMerge, &duplicates[i], nil))
+		}
+
+		err = revisionRepo.InsertBatch(ctx, revisions, len(revisions))
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	})
+
+	helper.Automapper(survivor, &response)
+
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, []int{survivor.ID})
 	if err != nil {
 		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
+	response.Tags = tags[survivor.ID]
 
-	sheet := xlFile.Sheets[0]
+	return response
+}
 
-	// Create channels for error handling and synchronization
-	errorChan := make(chan error)
-	excelValidation := exception.ExcelValidation{}
-	wg := sync.WaitGroup{}
+func (service *CustomerServiceImpl) Transition(ctx context.Context, request entity.TransitionCustomerRequest) (response entity.CustomerResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
 
-	// Track unique fields to ensure no duplicates within the same file
-	uniqueTracker := make(map[string]map[string]bool)
+	if !service.lifecycle.Has(request.Status) {
+		panic(exception.NewBadRequestHandler(fmt.Sprintf("status '%s' is not a customer status", request.Status)))
+	}
 
-	for colIndex := range helper.UniqueExcelCustomer {
-		uniqueTracker[helper.UniqueExcelCustomer[colIndex]] = make(map[string]bool)
+	before, err := service.customerRepo.FindById(ctx, request.CustomerID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
 	}
 
-	for rowIndex, row := range sheet.Rows {
-		if rowIndex == 0 {
-			continue
+	if before.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
+
+	if !service.lifecycle.Allows(before.Status, request.Status) {
+		panic(exception.NewConflictHandler(fmt.Sprintf("transition from '%s' to '%s' is not allowed", before.Status, request.Status)))
+	}
+
+	var after model.Customer
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		err = customerRepo.Transition(ctx, before.ID, before.Status, request.Status, request.Version)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
 		}
 
-		wg.Add(1)
+		after, err = customerRepo.FindById(ctx, before.ID)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
 
-		go func(rowIndex int, row *xlsx.Row) {
-			defer wg.Done()
+		service.recordRevision(ctx, revisionRepo, model.RevisionTransition, &before, &after)
+	})
 
-			// Validate each cell dynamically
-			for colIndex, errorMsg := range helper.ColumnExcelCustomer {
-				if colIndex < len(row.Cells) {
-					cell := row.Cells[colIndex]
-					if cell.String() == "" {
-						fieldName := sheet.Rows[0].Cells[colIndex].String()
-						excelValidation.AddHandler(fieldName, rowIndex, errorMsg)
-					}
-				}
-			}
+	service.eventBus.Publish(ctx, event.Event{
+		Name: event.CustomerStatusChanged,
+		Payload: entity.CustomerStatusChanged{
+			CustomerID: after.ID,
+			From:       before.Status,
+			To:         after.Status,
+			Reason:     request.Reason,
+			Actor:      utils.ActorFromContext(ctx),
+		},
+	})
 
-			// If there are validation errors, skip further processing for this row
-			if len(excelValidation.Errors) > 0 {
-				return
-			}
+	helper.Automapper(after, &response)
 
-			// Check uniqueness within the file
-			for colIndex, fieldName := range helper.UniqueExcelCustomer {
-				if colIndex < len(row.Cells) {
-					cell := row.Cells[colIndex]
-					if uniqueTracker[fieldName][cell.String()] {
-						excelValidation.AddHandler(fieldName, rowIndex, fmt.Sprintf("%s '%s' is not unique", fieldName, cell.String()))
-						return
-					}
-					uniqueTracker[fieldName][cell.String()] = true
-				}
-			}
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, []int{after.ID})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Tags = tags[after.ID]
 
-			// Check uniqueness in the database
-			for colIndex, fieldName := range helper.UniqueExcelCustomer {
-				if colIndex < len(row.Cells) {
-					cell := row.Cells[1]
-					data := service.customerRepo.CheckColumnExists(ctx, fieldName, cell.String())
-					if data != false {
-						excelValidation.AddHandler(fieldName, rowIndex+1, fmt.Sprintf("%s '%s' already taken", fieldName, cell.String()))
-						return
-					}
-				}
-			}
+	return response
+}
+
+func (service *CustomerServiceImpl) AssignOwner(ctx context.Context, request entity.AssignCustomerOwnerRequest) (response entity.CustomerResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
 
-			var customer model.Customer
-
-			for i, cell := range row.Cells {
-				switch i {
-				case 0:
-					customer.Username = cell.String()
-				case 1:
-					customer.Email = cell.String()
-				case 2:
-					customer.Phone = cell.String()
-				case 3:
-					customer.Address = cell.String()
+	if request.OwnerID != nil {
+		if _, err := service.userRepo.FindById(ctx, *request.OwnerID); err != nil {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("owner_id %d is not a user", *request.OwnerID)))
+		}
+	}
+
+	before, err := service.customerRepo.FindById(ctx, request.CustomerID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	if before.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
+
+	after := before
+	after.OwnerID = request.OwnerID
+	after.Version++
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		err = customerRepo.AssignOwner(ctx, before.ID, request.OwnerID, request.Version)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			panic(exception.NewPreconditionFailedHandler(err.Error()))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		service.recordRevision(ctx, revisionRepo, model.RevisionAssign, &before, &after)
+	})
+
+	helper.Automapper(after, &response)
+
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, []int{after.ID})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	response.Tags = tags[after.ID]
+
+	return response
+}
+
+func (service *CustomerServiceImpl) Reassign(ctx context.Context, request entity.ReassignCustomersRequest) (response entity.ReassignCustomersResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	if request.ToOwnerID != nil {
+		if *request.ToOwnerID == request.FromOwnerID {
+			panic(exception.NewBadRequestHandler("to_owner_id must differ from from_owner_id"))
+		}
+		if _, err := service.userRepo.FindById(ctx, *request.ToOwnerID); err != nil {
+			panic(exception.NewBadRequestHandler(fmt.Sprintf("to_owner_id %d is not a user", *request.ToOwnerID)))
+		}
+	}
+
+	var ids []int
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		ids, err = customerRepo.ReassignOwner(ctx, request.FromOwnerID, request.ToOwnerID)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		if len(ids) == 0 {
+			return
+		}
+
+		customers, err := customerRepo.FindByIds(ctx, ids)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		fromOwnerId := request.FromOwnerID
+		var revisions []model.CustomerRevision
+		for i := range customers {
+			before := customers[i]
+			before.OwnerID = &fromOwnerId
+			before.Version--
+			revisions = append(revisions, service.newRevision(ctx, model.RevisionAssign, &before, &customers[i]))
+		}
+
+		err = revisionRepo.InsertBatch(ctx, revisions, upsertBatchSize)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	})
+
+	response.Reassigned = len(ids)
+	response.CustomerIDs = ids
+	if len(ids) == 0 {
+		response.CustomerIDs = []int{}
+	}
+
+	return response
+}
+
+func revisionResponse(value model.CustomerRevision) entity.CustomerRevisionResponse {
+	response := entity.CustomerRevisionResponse{
+		ID:         value.ID,
+		CustomerID: value.CustomerID,
+		Action:     value.Action,
+		Actor:      value.Actor,
+		CreatedAt:  value.CreatedAt.Format(time.RFC3339),
+	}
+	json.Unmarshal(value.Changes, &response.Changes)
+	return response
+}
+
+func (service *CustomerServiceImpl) customFieldDefinitions(ctx context.Context) []model.CustomFieldDefinition {
+	definitions, err := service.customFieldRepo.FindAll(ctx)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	return definitions
+}
+
+// validateCustomFields checks request values against the admin-defined
+// schema and returns them normalized for storage.
+func validateCustomFields(definitions []model.CustomFieldDefinition, values map[string]interface{}, field string) map[string]interface{} {
+	result, errs := helper.ValidateCustomFields(definitions, values)
+	if len(errs) > 0 {
+		panic(exception.NewBadRequestHandler(fmt.Sprintf("%s: %s", field, strings.Join(errs, "; "))))
+	}
+	return result
+}
+
+// upsert writes the customers according to onConflict and records their
+// revisions: action for inserted rows and an update for overwritten ones.
+func (service *CustomerServiceImpl) upsert(ctx context.Context, customers []model.Customer, onConflict string, action string) (response entity.UpsertCustomerResponse) {
+	existing := make(map[string]model.Customer)
+	if onConflict == entity.OnConflictUpdate {
+		var emails []string
+		for _, customer := range customers {
+			emails = append(emails, customer.Email)
+		}
+
+		rows, err := service.customerRepo.FindByEmails(ctx, emails)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		for _, row := range rows {
+			existing[row.Email] = row
+		}
+	}
+
+	for i := range customers {
+		customers[i].Status = config.InitialCustomerStatus
+	}
+
+	service.inTransaction(ctx, func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo) {
+		actions, err := customerRepo.Upsert(ctx, customers, onConflict, upsertBatchSize)
+		if errors.Is(err, repository.ErrEmailTaken) {
+			panic(exception.NewBadRequestHandler("an email in the batch is already taken, use on_conflict skip or update"))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		var revisions []model.CustomerRevision
+		for i := range customers {
+			switch actions[i] {
+			case repository.UpsertInserted:
+				response.Inserted++
+				response.InsertedIDs = append(response.InsertedIDs, customers[i].ID)
+				revisions = append(revisions, service.newRevision(ctx, action, nil, &customers[i]))
+			case repository.UpsertUpdated:
+				response.Updated++
+				response.UpdatedIDs = append(response.UpdatedIDs, customers[i].ID)
+
+				before := existing[customers[i].Email]
+				after := before
+				after.Username = customers[i].Username
+				after.Phone = customers[i].Phone
+				after.Address = customers[i].Address
+				after.Version = customers[i].Version
+				if customers[i].OwnerID != nil {
+					after.OwnerID = customers[i].OwnerID
 				}
+				after.CustomFields = make(map[string]interface{})
+				for key, value := range before.CustomFields {
+					after.CustomFields[key] = value
+				}
+				for key, value := range customers[i].CustomFields {
+					after.CustomFields[key] = value
+				}
+				revisions = append(revisions, service.newRevision(ctx, model.RevisionUpdate, &before, &after))
+			case repository.UpsertSkipped:
+				response.Skipped++
+				response.SkippedIDs = append(response.SkippedIDs, customers[i].ID)
 			}
+		}
 
-			err := service.customerRepo.Insert(ctx, customer)
-			if err != nil {
-				panic(exception.NewInternalServerErrorHandler(err.Error()))
-			}
+		err = revisionRepo.InsertBatch(ctx, revisions, upsertBatchSize)
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	})
+
+	return response
+}
 
-		}(rowIndex, row)
+// normalizeChanges brings the contact fields of a change set into the form
+// they are validated and stored in.
+func normalizeChanges(changes *entity.CustomerChanges) {
+	if changes.Email != nil {
+		email := helper.NormalizeEmail(*changes.Email)
+		changes.Email = &email
 	}
+	if changes.Phone != nil {
+		phone := helper.FormatPhone(*changes.Phone)
+		changes.Phone = &phone
+	}
+}
 
-	go func() {
-		wg.Wait()
-		close(errorChan)
-	}()
+func applyChanges(customer *model.Customer, changes entity.CustomerChanges) {
+	if changes.Username != nil {
+		customer.Username = *changes.Username
+	}
+	if changes.Email != nil {
+		customer.Email = *changes.Email
+	}
+	if changes.Phone != nil {
+		customer.Phone = *changes.Phone
+	}
+	if changes.Address != nil {
+		customer.Address = *changes.Address
+	}
+}
 
-	for err := range errorChan {
-		return exception.NewInternalServerErrorHandler(err.Error())
+// checkEmailAvailable rejects email when it belongs to a customer other than
+// the one with the given id. Concurrent writes can still race past it; the
+// unique index on lower(email) rejects those with ErrEmailTaken.
+func (service *CustomerServiceImpl) checkEmailAvailable(ctx context.Context, email string, id int) {
+	owner, err := service.customerRepo.FindByColumns(ctx, []string{"lower(email)"}, []any{helper.NormalizeEmail(email)})
+	if err == nil && owner.ID != id {
+		panic(exception.NewBadRequestHandler(fmt.Sprintf("email '%s' already taken", email)))
 	}
+}
 
-	if len(excelValidation.Errors) > 0 {
-		return &excelValidation
+// resolveMine narrows a mine=true listing to the customers owned by the
+// authenticated user.
+func (service *CustomerServiceImpl) resolveMine(ctx context.Context, dataFilter *entity.CustomerQueryFilter) {
+	if !dataFilter.Mine {
+		return
 	}
 
-	return nil
+	user, err := service.userRepo.FindByColumns(ctx, []string{"email"}, []any{utils.ActorFromContext(ctx)})
+	if err != nil {
+		panic(exception.NewUnauthorizedHandler("current user not found"))
+	}
+	dataFilter.OwnerID = user.ID
+}
+
+// ownerEmails maps the owner ids of the customers to the owners' emails.
+func (service *CustomerServiceImpl) ownerEmails(ctx context.Context, customers []entity.CustomerResponse) map[int]string {
+	var ids []int
+	for _, customer := range customers {
+		if customer.OwnerID != nil {
+			ids = append(ids, *customer.OwnerID)
+		}
+	}
+
+	emails := make(map[int]string)
+	if len(ids) == 0 {
+		return emails
+	}
+
+	users, err := service.userRepo.FindByIds(ctx, ids)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	return emails
+}
+
+// withTags fills in the tag names of every customer in one query.
+func (service *CustomerServiceImpl) withTags(ctx context.Context, customers []entity.CustomerResponse) []entity.CustomerResponse {
+	var ids []int
+	for _, customer := range customers {
+		ids = append(ids, customer.ID)
+	}
+
+	tags, err := service.tagRepo.FindNamesByCustomerIds(ctx, ids)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for i := range customers {
+		customers[i].Tags = tags[customers[i].ID]
+	}
+	return customers
+}
+
+// inTransaction runs fn with the customer and revision repositories bound
+// to one transaction, so a change is never saved without its revision. fn
+// reports errors by panicking like the rest of the service, which rolls the
+// transaction back.
+func (service *CustomerServiceImpl) inTransaction(ctx context.Context, fn func(customerRepo repository.CustomerRepo, revisionRepo repository.CustomerRevisionRepo)) {
+	err := service.transactor.Transaction(ctx, func(tx *gorm.DB) error {
+		fn(service.customerRepo.WithTx(tx), service.revisionRepo.WithTx(tx))
+		return nil
+	})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *CustomerServiceImpl) recordRevision(ctx context.Context, revisionRepo repository.CustomerRevisionRepo, action string, before *model.Customer, after *model.Customer) {
+	err := revisionRepo.Insert(ctx, service.newRevision(ctx, action, before, after))
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+// newRevision builds the audit row for a change from before to after; a nil
+// before marks a creation and a nil after marks a deletion.
+func (service *CustomerServiceImpl) newRevision(ctx context.Context, action string, before *model.Customer, after *model.Customer) model.CustomerRevision {
+	var oldValue, newValue interface{}
+	revision := model.CustomerRevision{
+		Action: action,
+		Actor:  utils.ActorFromContext(ctx),
+	}
+
+	if before != nil {
+		oldValue = before
+		revision.CustomerID = before.ID
+	}
+	if after != nil {
+		newValue = after
+		revision.CustomerID = after.ID
+		revision.Snapshot = json.RawMessage(helper.StructToJson(after))
+	}
+
+	changes := helper.Diff(oldValue, newValue, "id", "version", "status_timestamps", "created_at", "updated_at")
+	revision.Changes = json.RawMessage(helper.StructToJson(changes))
+
+	return revision
 }
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/event"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"testing"
+)
+
+func newTestCustomerService(customerRepo *fakeCustomerRepo, revisionRepo *fakeRevisionRepo) *CustomerServiceImpl {
+	lifecycle, _ := config.ParseCustomerLifecycle("")
+	return NewCustomerServiceImpl(customerRepo, revisionRepo, fakeTransactor{}, &fakeTagRepo{}, &fakeCustomFieldRepo{}, testUsers(), nil,
+		lifecycle, event.NewBusImpl(), nil, nil, config.ImportOptions{}, utils.InitializeValidator(nil)).(*CustomerServiceImpl)
+}
+
+// testUsers are the account managers customers can be assigned to.
+func testUsers() *fakeUserRepo {
+	return &fakeUserRepo{users: []model.User{
+		{ID: 7, Username: "mia", Email: "mia@example.com"},
+		{ID: 8, Username: "noah", Email: "noah@example.com"},
+	}}
+}
+
+func storedCustomers() *fakeCustomerRepo {
+	return newFakeCustomerRepo(
+		model.Customer{ID: 1, Username: "ann", Email: "ann@example.com", Phone: "+6281234567890", Address: "Jl. Sudirman 1", Version: 3},
+		model.Customer{ID: 2, Username: "bob", Email: "bob@example.com", Phone: "+6281234567891", Address: "Jl. Thamrin 2", Version: 1},
+	)
+}
+
+func updateRequest(id int, version int, email string) entity.UpdateCustomerRequest {
+	return entity.UpdateCustomerRequest{ID: id, Version: version, Username: "ann", Email: email, Phone: "+6281234567890", Address: "Jl. Sudirman 1"}
+}
+
+func TestUpdateCustomer(t *testing.T) {
+	customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+	service := newTestCustomerService(customers, revisions)
+
+	service.Update(actorContext("admin@example.com"), updateRequest(1, 3, " Ann@Example.ORG "))
+
+	stored := customers.customers[1]
+	if stored.Email != "ann@example.org" || stored.Version != 4 {
+		t.Errorf("stored email %s at version %d, want ann@example.org at 4", stored.Email, stored.Version)
+	}
+	if len(revisions.revisions) != 1 || revisions.revisions[0].Action != model.RevisionUpdate {
+		t.Errorf("recorded revisions %+v, want one update", revisions.revisions)
+	}
+}
+
+func TestUpdateCustomerPreconditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		request entity.UpdateCustomerRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			// The customer is looked up first: a taken email does not hide
+			// that it does not exist.
+			name:    "unknown customer with a taken email",
+			request: updateRequest(9, 1, "bob@example.com"),
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:    "stale version with a taken email",
+			request: updateRequest(1, 2, "bob@example.com"),
+			check:   func(value interface{}) bool { _, ok := value.(*exception.PreconditionFailedErrorStruct); return ok },
+		},
+		{
+			name:    "email of another customer",
+			request: updateRequest(1, 3, "BOB@example.com"),
+			check:   func(value interface{}) bool { _, ok := value.(*exception.BadRequestErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		customers := storedCustomers()
+		service := newTestCustomerService(customers, &fakeRevisionRepo{})
+
+		value := raised(func() { service.Update(actorContext("admin@example.com"), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+		if customers.customers[1].Version != 3 {
+			t.Errorf("%s: customer was updated", test.name)
+		}
+	}
+}
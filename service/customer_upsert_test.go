@@ -0,0 +1,76 @@
+package service
+
+import (
+	"reflect"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"testing"
+)
+
+func upsertBatch() []model.Customer {
+	return []model.Customer{
+		{Username: "ann b", Email: "ann@example.com", Phone: "+6281234567899", Address: "Jl. Gatot Subroto 3", CustomFields: map[string]interface{}{"tier": "gold"}},
+		{Username: "cid", Email: "cid@example.com", Phone: "+6281234567892", Address: "Jl. Kuningan 4"},
+	}
+}
+
+func TestUpsertCustomers(t *testing.T) {
+	tests := []struct {
+		onConflict string
+		want       entity.UpsertCustomerResponse
+		username   string
+		revisions  []string
+	}{
+		{
+			onConflict: entity.OnConflictSkip,
+			want:       entity.UpsertCustomerResponse{Inserted: 1, Skipped: 1, InsertedIDs: []int{3}, SkippedIDs: []int{1}},
+			username:   "ann",
+			revisions:  []string{model.RevisionCreate},
+		},
+		{
+			onConflict: entity.OnConflictUpdate,
+			want:       entity.UpsertCustomerResponse{Inserted: 1, Updated: 1, InsertedIDs: []int{3}, UpdatedIDs: []int{1}},
+			username:   "ann b",
+			revisions:  []string{model.RevisionUpdate, model.RevisionCreate},
+		},
+	}
+
+	for _, test := range tests {
+		customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+		service := newTestCustomerService(customers, revisions)
+
+		response := service.upsert(actorContext("admin@example.com"), upsertBatch(), test.onConflict, model.RevisionCreate)
+
+		if !reflect.DeepEqual(response, test.want) {
+			t.Errorf("%s: response %+v, want %+v", test.onConflict, response, test.want)
+		}
+		if customers.customers[1].Username != test.username {
+			t.Errorf("%s: existing customer is named %s, want %s", test.onConflict, customers.customers[1].Username, test.username)
+		}
+
+		var actions []string
+		for _, revision := range revisions.revisions {
+			actions = append(actions, revision.Action)
+		}
+		if !reflect.DeepEqual(actions, test.revisions) {
+			t.Errorf("%s: revisions %v, want %v", test.onConflict, actions, test.revisions)
+		}
+	}
+}
+
+func TestUpsertCustomersConflict(t *testing.T) {
+	customers, revisions := storedCustomers(), &fakeRevisionRepo{}
+	service := newTestCustomerService(customers, revisions)
+
+	value := raised(func() {
+		service.upsert(actorContext("admin@example.com"), upsertBatch(), entity.OnConflictError, model.RevisionCreate)
+	})
+
+	if _, ok := value.(*exception.BadRequestErrorStruct); !ok {
+		t.Errorf("raised %#v, want a bad request", value)
+	}
+	if len(customers.customers) != 2 || len(revisions.revisions) != 0 {
+		t.Errorf("the batch was written")
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"io"
+	"scylla/pkg/tabular"
+)
+
+// exportBatchSize is how many rows an export reads from the database at a
+// time; the file is flushed after each batch.
+const exportBatchSize = 500
+
+// exportBatches reads the rows of an export, calling write with each batch.
+type exportBatches[T any] func(write func(batch []T) error) error
+
+// writeSheet streams the columns of schema selected by options to w in the
+// format of options, one batch of rows at a time, without keeping the rows
+// or the file around. It reports progress towards total rows and returns
+// how many were written.
+func writeSheet[T any](ctx context.Context, w io.Writer, schema tabular.Schema[T], options tabular.Options, total int64, batches exportBatches[T]) (int, error) {
+	columns, err := schema.Select(options.Columns)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, len(columns))
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = column.Key
+		headers[i] = column.Header
+	}
+
+	writer, err := tabular.NewWriter(w, options, schema.Sheet, keys, headers)
+	if err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	written := 0
+	err = batches(func(batch []T) error {
+		for _, row := range batch {
+			for i, column := range columns {
+				values[i] = column.Format(row)
+			}
+			if err := writer.Write(values); err != nil {
+				return err
+			}
+		}
+		written += len(batch)
+		reportProgress(ctx, percentOf(written, int(total), 0, 100))
+		return writer.Flush()
+	})
+	if err != nil {
+		return written, err
+	}
+
+	return written, writer.Close()
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"scylla/pkg/tabular"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type exportedRow struct {
+	id   int
+	name string
+}
+
+var exportedSchema = tabular.Schema[exportedRow]{
+	Sheet: "Rows",
+	Columns: []tabular.Column[exportedRow]{
+		{Key: "id", Header: "ID", Format: func(row exportedRow) interface{} { return row.id }},
+		{Key: "name", Header: "Name", Format: func(row exportedRow) interface{} { return row.name }},
+	},
+}
+
+// exportedBatches hands out n rows in batches of size, recording how much
+// of the file had been written when each batch was done.
+func exportedBatches(n int, size int, buffer *bytes.Buffer, sizes *[]int) exportBatches[exportedRow] {
+	return func(write func(batch []exportedRow) error) error {
+		for start := 1; start <= n; start += size {
+			var batch []exportedRow
+			for id := start; id < start+size && id <= n; id++ {
+				batch = append(batch, exportedRow{id: id, name: "row " + strconv.Itoa(id)})
+			}
+			if err := write(batch); err != nil {
+				return err
+			}
+			*sizes = append(*sizes, buffer.Len())
+		}
+		return nil
+	}
+}
+
+func TestWriteSheet(t *testing.T) {
+	var buffer bytes.Buffer
+	var sizes []int
+	options := tabular.Options{Format: tabular.FormatCSV}
+
+	written, err := writeSheet(context.Background(), &buffer, exportedSchema, options, 5, exportedBatches(5, 2, &buffer, &sizes))
+	if err != nil || written != 5 {
+		t.Fatalf("writeSheet = %d, %v; want 5 rows", written, err)
+	}
+
+	want := "ID,Name\n1,row 1\n2,row 2\n3,row 3\n4,row 4\n5,row 5\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("file %q, want %q", got, want)
+	}
+
+	// Each batch reaches w before the next one is read.
+	if len(sizes) != 3 || sizes[0] == 0 || sizes[0] >= sizes[1] || sizes[1] >= sizes[2] {
+		t.Errorf("file sizes after each batch %v, want them growing", sizes)
+	}
+}
+
+func TestWriteSheetColumns(t *testing.T) {
+	var buffer bytes.Buffer
+	var sizes []int
+	options := tabular.Options{Format: tabular.FormatCSV, Columns: []string{"name"}}
+
+	if _, err := writeSheet(context.Background(), &buffer, exportedSchema, options, 2, exportedBatches(2, 10, &buffer, &sizes)); err != nil {
+		t.Fatalf("writeSheet: %v", err)
+	}
+	if got, want := buffer.String(), "Name\nrow 1\nrow 2\n"; got != want {
+		t.Errorf("file %q, want %q", got, want)
+	}
+
+	options.Columns = []string{"email"}
+	if _, err := writeSheet(context.Background(), &buffer, exportedSchema, options, 2, exportedBatches(2, 10, &buffer, &sizes)); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("writeSheet of an unknown column returned %v", err)
+	}
+}
+
+func TestWriteSheetStopped(t *testing.T) {
+	var buffer bytes.Buffer
+	stopped := errors.New("cursor closed")
+	batches := func(write func(batch []exportedRow) error) error {
+		if err := write([]exportedRow{{id: 1, name: "row 1"}}); err != nil {
+			return err
+		}
+		return stopped
+	}
+
+	written, err := writeSheet(context.Background(), &buffer, exportedSchema, tabular.Options{Format: tabular.FormatCSV}, 2, batches)
+	if !errors.Is(err, stopped) || written != 1 {
+		t.Errorf("writeSheet = %d, %v; want the batch error after 1 row", written, err)
+	}
+}
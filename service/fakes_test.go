@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http/httptest"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"strings"
+)
+
+// The fakes below keep rows in memory. They embed the repository interface,
+// so methods a test does not need stay nil and panic if called.
+
+type fakeCustomerRepo struct {
+	repository.CustomerRepo
+	customers map[int]model.Customer
+	nextId    int
+}
+
+func newFakeCustomerRepo(customers ...model.Customer) *fakeCustomerRepo {
+	repo := &fakeCustomerRepo{customers: make(map[int]model.Customer)}
+	for _, customer := range customers {
+		repo.customers[customer.ID] = customer
+		if customer.ID > repo.nextId {
+			repo.nextId = customer.ID
+		}
+	}
+	return repo
+}
+
+func (repo *fakeCustomerRepo) WithTx(tx *gorm.DB) repository.CustomerRepo {
+	return repo
+}
+
+func (repo *fakeCustomerRepo) Insert(ctx context.Context, data model.Customer) (model.Customer, error) {
+	if repo.emailOwner(data.Email) != 0 {
+		return data, repository.ErrEmailTaken
+	}
+	repo.nextId++
+	data.ID = repo.nextId
+	data.Version = 1
+	repo.customers[data.ID] = data
+	return data, nil
+}
+
+func (repo *fakeCustomerRepo) Update(ctx context.Context, data model.Customer) error {
+	stored, ok := repo.customers[data.ID]
+	if !ok || stored.Version != data.Version {
+		return repository.ErrVersionConflict
+	}
+	if owner := repo.emailOwner(data.Email); owner != 0 && owner != data.ID {
+		return repository.ErrEmailTaken
+	}
+	data.Version++
+	repo.customers[data.ID] = data
+	return nil
+}
+
+// Upsert resolves each conflict the way the ON CONFLICT clauses of the
+// real one do; in error mode nothing is written.
+func (repo *fakeCustomerRepo) Upsert(ctx context.Context, data []model.Customer, onConflict string, batchSize int) ([]string, error) {
+	if onConflict == entity.OnConflictError {
+		for _, customer := range data {
+			if repo.emailOwner(customer.Email) != 0 {
+				return nil, repository.ErrEmailTaken
+			}
+		}
+	}
+
+	actions := make([]string, len(data))
+	for i, customer := range data {
+		id := repo.emailOwner(customer.Email)
+		if id == 0 {
+			data[i], _ = repo.Insert(ctx, customer)
+			actions[i] = repository.UpsertInserted
+			continue
+		}
+
+		stored := repo.customers[id]
+		if onConflict == entity.OnConflictSkip {
+			data[i].ID, data[i].Version = stored.ID, stored.Version
+			actions[i] = repository.UpsertSkipped
+			continue
+		}
+
+		customFields := make(map[string]interface{})
+		for key, value := range stored.CustomFields {
+			customFields[key] = value
+		}
+		for key, value := range customer.CustomFields {
+			customFields[key] = value
+		}
+		stored.Username, stored.Phone, stored.Address = customer.Username, customer.Phone, customer.Address
+		stored.CustomFields = customFields
+		stored.Version++
+		repo.customers[id] = stored
+		data[i].ID, data[i].Version = stored.ID, stored.Version
+		actions[i] = repository.UpsertUpdated
+	}
+	return actions, nil
+}
+
+func (repo *fakeCustomerRepo) FindByEmails(ctx context.Context, emails []string) (domain []model.Customer, err error) {
+	for _, email := range emails {
+		if id := repo.emailOwner(email); id != 0 {
+			domain = append(domain, repo.customers[id])
+		}
+	}
+	return domain, nil
+}
+
+// UpdateBatch applies Update row by row; in atomic mode the first failure
+// restores the rows as they were, like the rolled back transaction.
+func (repo *fakeCustomerRepo) UpdateBatch(ctx context.Context, data []model.Customer, atomic bool) ([]error, error) {
+	saved := make(map[int]model.Customer, len(repo.customers))
+	for id, customer := range repo.customers {
+		saved[id] = customer
+	}
+
+	errs := make([]error, len(data))
+	for i, customer := range data {
+		errs[i] = repo.Update(ctx, customer)
+		if errs[i] != nil && atomic {
+			repo.customers = saved
+			break
+		}
+	}
+	return errs, nil
+}
+
+func (repo *fakeCustomerRepo) Merge(ctx context.Context, survivor model.Customer, duplicateIds []int) (repository.MergeResult, error) {
+	if repo.customers[survivor.ID].Version != survivor.Version {
+		return repository.MergeResult{}, repository.ErrVersionConflict
+	}
+	for _, id := range duplicateIds {
+		delete(repo.customers, id)
+	}
+	survivor.Version++
+	repo.customers[survivor.ID] = survivor
+	return repository.MergeResult{}, nil
+}
+
+func (repo *fakeCustomerRepo) AssignOwner(ctx context.Context, Id int, ownerId *int, version int) error {
+	customer, ok := repo.customers[Id]
+	if !ok || customer.Version != version {
+		return repository.ErrVersionConflict
+	}
+	customer.OwnerID = ownerId
+	customer.Version++
+	repo.customers[Id] = customer
+	return nil
+}
+
+func (repo *fakeCustomerRepo) ReassignOwner(ctx context.Context, fromOwnerId int, toOwnerId *int) (ids []int, err error) {
+	for id := 1; id <= repo.nextId; id++ {
+		customer, ok := repo.customers[id]
+		if !ok || customer.OwnerID == nil || *customer.OwnerID != fromOwnerId {
+			continue
+		}
+		customer.OwnerID = toOwnerId
+		customer.Version++
+		repo.customers[id] = customer
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (repo *fakeCustomerRepo) FindById(ctx context.Context, Id int) (model.Customer, error) {
+	customer, ok := repo.customers[Id]
+	if !ok {
+		return customer, gorm.ErrRecordNotFound
+	}
+	return customer, nil
+}
+
+func (repo *fakeCustomerRepo) FindByIds(ctx context.Context, Ids []int) (domain []model.Customer, err error) {
+	for _, id := range Ids {
+		if customer, ok := repo.customers[id]; ok {
+			domain = append(domain, customer)
+		}
+	}
+	return domain, nil
+}
+
+// FindByColumns only supports the email lookups of the service.
+func (repo *fakeCustomerRepo) FindByColumns(ctx context.Context, columns []string, queries []any) (model.Customer, error) {
+	if len(columns) == 1 && strings.Contains(columns[0], "email") {
+		if id := repo.emailOwner(queries[0].(string)); id != 0 {
+			return repo.customers[id], nil
+		}
+	}
+	return model.Customer{}, gorm.ErrRecordNotFound
+}
+
+func (repo *fakeCustomerRepo) emailOwner(email string) int {
+	for id, customer := range repo.customers {
+		if strings.EqualFold(customer.Email, email) {
+			return id
+		}
+	}
+	return 0
+}
+
+type fakeRevisionRepo struct {
+	repository.CustomerRevisionRepo
+	revisions []model.CustomerRevision
+}
+
+func (repo *fakeRevisionRepo) WithTx(tx *gorm.DB) repository.CustomerRevisionRepo {
+	return repo
+}
+
+func (repo *fakeRevisionRepo) Insert(ctx context.Context, data model.CustomerRevision) error {
+	data.ID = len(repo.revisions) + 1
+	repo.revisions = append(repo.revisions, data)
+	return nil
+}
+
+func (repo *fakeRevisionRepo) InsertBatch(ctx context.Context, data []model.CustomerRevision, batchSize int) error {
+	for _, revision := range data {
+		repo.Insert(ctx, revision)
+	}
+	return nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepo
+	users []model.User
+}
+
+func (repo *fakeUserRepo) FindById(ctx context.Context, Id int) (model.User, error) {
+	for _, user := range repo.users {
+		if user.ID == Id {
+			return user, nil
+		}
+	}
+	return model.User{}, gorm.ErrRecordNotFound
+}
+
+// FindByColumns only supports the email lookup of the service.
+func (repo *fakeUserRepo) FindByColumns(ctx context.Context, columns []string, queries []any) (model.User, error) {
+	for _, user := range repo.users {
+		if len(columns) == 1 && columns[0] == "email" && user.Email == queries[0] {
+			return user, nil
+		}
+	}
+	return model.User{}, gorm.ErrRecordNotFound
+}
+
+type fakeTagRepo struct {
+	repository.TagRepo
+}
+
+func (repo *fakeTagRepo) FindNamesByCustomerIds(ctx context.Context, customerIds []int) (map[int][]string, error) {
+	return map[int][]string{}, nil
+}
+
+func (repo *fakeRevisionRepo) FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerRevision, err error) {
+	for _, revision := range repo.revisions {
+		for _, id := range Ids {
+			if revision.ID == id {
+				domain = append(domain, revision)
+			}
+		}
+	}
+	return domain, nil
+}
+
+type fakeCustomFieldRepo struct {
+	repository.CustomFieldRepo
+	definitions []model.CustomFieldDefinition
+}
+
+func (repo *fakeCustomFieldRepo) FindAll(ctx context.Context) ([]model.CustomFieldDefinition, error) {
+	return repo.definitions, nil
+}
+
+// fakeTransactor runs fn without a transaction; the fakes ignore tx.
+type fakeTransactor struct{}
+
+func (fakeTransactor) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+// raised runs fn and returns the value it panicked with, nil if none.
+func raised(fn func()) (value interface{}) {
+	defer func() {
+		value = recover()
+	}()
+	fn()
+	return nil
+}
+
+// actorContext is the context of a request made by the user with email.
+func actorContext(email string) context.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Set("currentUser", email)
+	return utils.WithActor(context.Background(), ctx)
+}
+
+type fakeCustomerAddressRepo struct {
+	repository.CustomerAddressRepo
+	addresses []model.CustomerAddress
+}
+
+func (repo *fakeCustomerAddressRepo) Insert(ctx context.Context, data model.CustomerAddress) (model.CustomerAddress, error) {
+	data.ID = len(repo.addresses) + 1
+	repo.addresses = append(repo.addresses, data)
+	return data, nil
+}
+
+type fakeAttachmentRepo struct {
+	repository.CustomerAttachmentRepo
+	attachments []model.CustomerAttachment
+}
+
+func (repo *fakeAttachmentRepo) Insert(ctx context.Context, data model.CustomerAttachment) (model.CustomerAttachment, error) {
+	data.ID = len(repo.attachments) + 1
+	repo.attachments = append(repo.attachments, data)
+	return data, nil
+}
+
+func (repo *fakeAttachmentRepo) FindById(ctx context.Context, Id int) (model.CustomerAttachment, error) {
+	for _, attachment := range repo.attachments {
+		if attachment.ID == Id {
+			return attachment, nil
+		}
+	}
+	return model.CustomerAttachment{}, gorm.ErrRecordNotFound
+}
+
+func (repo *fakeAttachmentRepo) FindByCustomerId(ctx context.Context, customerId int) (domain []model.CustomerAttachment, err error) {
+	for _, attachment := range repo.attachments {
+		if attachment.CustomerID == customerId {
+			domain = append(domain, attachment)
+		}
+	}
+	return domain, nil
+}
+
+// fakeErasureRepo records what Erase was given; err makes it fail instead.
+type fakeErasureRepo struct {
+	repository.CustomerErasureRepo
+	erased   []model.Customer
+	receipts []model.CustomerErasure
+	err      error
+}
+
+func (repo *fakeErasureRepo) Erase(ctx context.Context, customer model.Customer, receipt model.CustomerErasure) (model.CustomerErasure, error) {
+	if repo.err != nil {
+		return receipt, repo.err
+	}
+	receipt.ID = len(repo.receipts) + 1
+	repo.erased = append(repo.erased, customer)
+	repo.receipts = append(repo.receipts, receipt)
+	return receipt, nil
+}
+
+// fakeActivityRepo serves the timeline it is given; FindTimeline does not
+// page.
+type fakeActivityRepo struct {
+	repository.CustomerActivityRepo
+	activities []model.CustomerActivity
+	timeline   []repository.TimelineRef
+}
+
+func (repo *fakeActivityRepo) Insert(ctx context.Context, data model.CustomerActivity) (model.CustomerActivity, error) {
+	data.ID = len(repo.activities) + 1
+	repo.activities = append(repo.activities, data)
+	return data, nil
+}
+
+func (repo *fakeActivityRepo) Update(ctx context.Context, data model.CustomerActivity) error {
+	repo.activities[data.ID-1] = data
+	return nil
+}
+
+func (repo *fakeActivityRepo) FindById(ctx context.Context, customerId int, Id int) (model.CustomerActivity, error) {
+	if Id < 1 || Id > len(repo.activities) || repo.activities[Id-1].CustomerID != customerId {
+		return model.CustomerActivity{}, gorm.ErrRecordNotFound
+	}
+	return repo.activities[Id-1], nil
+}
+
+func (repo *fakeActivityRepo) FindByIds(ctx context.Context, Ids []int) (domain []model.CustomerActivity, err error) {
+	for _, id := range Ids {
+		if id >= 1 && id <= len(repo.activities) {
+			domain = append(domain, repo.activities[id-1])
+		}
+	}
+	return domain, nil
+}
+
+func (repo *fakeActivityRepo) FindTimeline(ctx context.Context, customerId int, dataFilter entity.CustomerTimelineQueryFilter) ([]repository.TimelineRef, int64, error) {
+	return repo.timeline, int64(len(repo.timeline)), nil
+}
+
+type fakeImportProfileRepo struct {
+	repository.ImportProfileRepo
+	profiles []model.ImportProfile
+}
+
+func (repo *fakeImportProfileRepo) FindByName(ctx context.Context, resource string, name string) (model.ImportProfile, error) {
+	for _, profile := range repo.profiles {
+		if profile.Resource == resource && profile.Name == name {
+			return profile, nil
+		}
+	}
+	return model.ImportProfile{}, gorm.ErrRecordNotFound
+}
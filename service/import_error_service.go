@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importErrorsPrefix is the storage folder of the error workbooks.
+const importErrorsPrefix = "import-errors/"
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type ImportErrorService interface {
+	Download(ctx context.Context, request entity.DownloadImportErrorsRequest) (body io.ReadCloser)
+}
+
+type ImportErrorServiceImpl struct {
+	fileStorage storage.Storage
+	options     config.ImportOptions
+	validate    *validator.Validate
+}
+
+func NewImportErrorServiceImpl(fileStorage storage.Storage, options config.ImportOptions, validate *validator.Validate) ImportErrorService {
+	return &ImportErrorServiceImpl{
+		fileStorage: fileStorage,
+		options:     options,
+		validate:    validate,
+	}
+}
+
+func (service *ImportErrorServiceImpl) Download(ctx context.Context, request entity.DownloadImportErrorsRequest) (body io.ReadCloser) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	expected := importErrorsSignature(service.options, request.ErrorFileId, request.Expires)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(request.Signature))) {
+		panic(exception.NewUnauthorizedHandler("invalid download signature"))
+	}
+	if time.Now().Unix() > request.Expires {
+		panic(exception.NewUnauthorizedHandler("download link has expired"))
+	}
+
+	body, err = service.fileStorage.Open(ctx, importErrorsPrefix+request.ErrorFileId+".xlsx")
+	if err == storage.ErrNotFound {
+		panic(exception.NewNotFoundHandler("error file not found"))
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	return body
+}
+
+// importErrorFile stores the error workbook of an import with rejected rows
+// and returns a link to download it, valid for options.ErrorTTL. It returns
+// "" when every row was valid. Failing to store the workbook only costs the
+// link, so it is logged rather than failing the import.
+func importErrorFile[T any](ctx context.Context, fileStorage storage.Storage, options config.ImportOptions, imported importedSheet[T], keepValid bool) string {
+	if len(imported.rejected) == 0 {
+		return ""
+	}
+
+	url, err := saveImportErrors(ctx, fileStorage, options, imported, keepValid)
+	if err != nil {
+		log.Printf("import: saving error workbook: %v", err)
+		return ""
+	}
+	return url
+}
+
+func saveImportErrors[T any](ctx context.Context, fileStorage storage.Storage, options config.ImportOptions, imported importedSheet[T], keepValid bool) (string, error) {
+	workbook, err := imported.errorWorkbook(keepValid)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	if err := workbook.Write(&buffer); err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	key := importErrorsPrefix + id + ".xlsx"
+	if err := fileStorage.Put(ctx, key, &buffer, int64(buffer.Len()), xlsxContentType); err != nil {
+		return "", err
+	}
+
+	ttl := options.ErrorTTL
+	if ttl <= 0 {
+		ttl = config.DefaultImportErrorTTL
+	}
+
+	if signer, ok := fileStorage.(storage.URLSigner); ok {
+		return signer.SignedURL(ctx, key, "import-errors.xlsx", ttl)
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	return fmt.Sprintf("%s/api/v1/imports/errors/%s?expires=%d&signature=%s",
+		strings.TrimSuffix(options.PublicURL, "/"), id, expires, importErrorsSignature(options, id, expires)), nil
+}
+
+// importErrorsSignature signs a download link of an error workbook. The
+// prefix keeps it from ever matching an attachment link signature.
+func importErrorsSignature(options config.ImportOptions, id string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(options.SigningKey))
+	mac.Write([]byte("import-errors:" + id + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path"
+	"reflect"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestErrorWorkbook(t *testing.T) {
+	tests := []struct {
+		keepValid bool
+		emails    []string
+	}{
+		{keepValid: true, emails: []string{"Email", "ANN@example.com", "cid@example.com", "not an email"}},
+		{keepValid: false, emails: []string{"Email", "not an email"}},
+	}
+
+	request := entity.ImportCustomerRequest{OnConflict: entity.OnConflictUpdate}
+	_, imported := newTestImport(t, storedCustomers(), &fakeRevisionRepo{}, request, importFile)
+
+	for _, test := range tests {
+		workbook, err := imported.errorWorkbook(test.keepValid)
+		if err != nil {
+			t.Fatalf("errorWorkbook: %v", err)
+		}
+		rows := workbook.Sheets[0].Rows
+
+		var emails []string
+		for _, row := range rows {
+			emails = append(emails, row.Cells[1].Value)
+		}
+		if !reflect.DeepEqual(emails, test.emails) {
+			t.Errorf("keepValid %v: emails %v, want %v", test.keepValid, emails, test.emails)
+		}
+
+		if errors := rows[0].Cells[4].Value; errors != "Errors" {
+			t.Errorf("keepValid %v: last header is %q, want Errors", test.keepValid, errors)
+		}
+		rejected := rows[len(rows)-1]
+		if !strings.Contains(rejected.Cells[4].Value, "email") {
+			t.Errorf("keepValid %v: rejected row errors %q, want the email issue", test.keepValid, rejected.Cells[4].Value)
+		}
+		if fill := rejected.Cells[1].GetStyle().Fill.PatternType; fill != "solid" {
+			t.Errorf("keepValid %v: bad email cell is not highlighted", test.keepValid)
+		}
+		if fill := rejected.Cells[0].GetStyle().Fill.PatternType; fill == "solid" {
+			t.Errorf("keepValid %v: valid username cell is highlighted", test.keepValid)
+		}
+	}
+}
+
+func TestImportErrorDownload(t *testing.T) {
+	files := storage.NewLocalStorage(t.TempDir())
+	options := config.ImportOptions{PublicURL: "https://crm.example.com/", SigningKey: "secret"}
+	service := NewImportErrorServiceImpl(files, options, utils.InitializeValidator(nil))
+
+	request := entity.ImportCustomerRequest{OnConflict: entity.OnConflictUpdate}
+	_, imported := newTestImport(t, storedCustomers(), &fakeRevisionRepo{}, request, importFile)
+	link := importErrorFile(context.Background(), files, options, imported, false)
+
+	parsed, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(link, "https://crm.example.com/api/v1/imports/errors/") {
+		t.Fatalf("error file link %q", link)
+	}
+	expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	valid := entity.DownloadImportErrorsRequest{
+		ErrorFileId: path.Base(parsed.Path),
+		Expires:     expires,
+		Signature:   parsed.Query().Get("signature"),
+	}
+
+	body := service.Download(context.Background(), valid)
+	content, _ := io.ReadAll(body)
+	body.Close()
+	if len(content) == 0 || string(content[:2]) != "PK" {
+		t.Errorf("downloaded %d bytes, want the xlsx workbook", len(content))
+	}
+
+	tampered := valid
+	tampered.ErrorFileId = "0b9e3c7c-5d0a-4f6b-9a59-2f7d8a3f2b11"
+	expired := valid
+	expired.Expires = 1
+	expired.Signature = importErrorsSignature(options, expired.ErrorFileId, expired.Expires)
+	missing := tampered
+	missing.Signature = importErrorsSignature(options, missing.ErrorFileId, missing.Expires)
+
+	tests := []struct {
+		name    string
+		request entity.DownloadImportErrorsRequest
+		check   func(value interface{}) bool
+	}{
+		{
+			name:    "tampered",
+			request: tampered,
+			check:   func(value interface{}) bool { _, ok := value.(*exception.UnauthorizedErrorStruct); return ok },
+		},
+		{
+			name:    "expired",
+			request: expired,
+			check:   func(value interface{}) bool { _, ok := value.(*exception.UnauthorizedErrorStruct); return ok },
+		},
+		{
+			name:    "missing",
+			request: missing,
+			check:   func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+	}
+
+	for _, test := range tests {
+		value := raised(func() { service.Download(context.Background(), test.request) })
+		if !test.check(value) {
+			t.Errorf("%s: Download raised %#v", test.name, value)
+		}
+	}
+}
+
+func TestImportErrorFileWithoutRejected(t *testing.T) {
+	request := entity.ImportCustomerRequest{OnConflict: entity.OnConflictUpdate}
+	_, imported := newTestImport(t, storedCustomers(), &fakeRevisionRepo{}, request, validImportFile)
+
+	if link := importErrorFile(context.Background(), storage.NewLocalStorage(t.TempDir()), config.ImportOptions{}, imported, true); link != "" {
+		t.Errorf("importErrorFile = %q for a file without errors, want no link", link)
+	}
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/tealeg/xlsx"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/tabular"
+	"scylla/repository"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// importReadShare is the part of an import's progress taken by validating
+// the rows; writing them takes the rest.
+const importReadShare = 50
+
+// importIssue is a validation problem found on one row of an imported file.
+// Row is the row number as shown in a spreadsheet, the header being row 1.
+type importIssue struct {
+	Field   string
+	Row     int
+	Message string
+}
+
+type importResult[T any] struct {
+	rowIndex int
+	value    T
+	issues   []importIssue
+}
+
+// importRowFunc turns one data row into a value. Problems with the row's
+// data are returned as issues; an error means the import cannot go on.
+type importRowFunc[T any] func(ctx context.Context, rowIndex int, row []string) (T, []importIssue, error)
+
+// importRows parses every row below the header on a pool of at most workers
+// goroutines and returns the results in file order. The first error, a
+// panic in parse, or ctx ending stops the remaining rows and is returned.
+// Progress is reported up to importReadShare.
+func importRows[T any](ctx context.Context, rows [][]string, workers int, parse importRowFunc[T]) ([]importResult[T], error) {
+	if workers <= 0 {
+		workers = config.DefaultImportWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan importResult[T])
+	// Every worker sends at most one error, so sends never block.
+	errs := make(chan error, workers)
+
+	go func() {
+		defer close(jobs)
+		for rowIndex := 1; rowIndex < len(rows); rowIndex++ {
+			select {
+			case jobs <- rowIndex:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rowIndex := range jobs {
+				result, err := parseImportRow(ctx, rowIndex, rows[rowIndex], parse)
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+		close(errs)
+	}()
+
+	var collected []importResult[T]
+	for result := range results {
+		collected = append(collected, result)
+		reportProgress(ctx, percentOf(len(collected), len(rows)-1, 0, importReadShare))
+	}
+
+	if err, ok := <-errs; ok {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("import stopped: %w", err)
+	}
+
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i].rowIndex < collected[j].rowIndex
+	})
+	return collected, nil
+}
+
+// parseImportRow runs parse, turning a panic into an error so it stays on
+// the request instead of crashing the process.
+func parseImportRow[T any](ctx context.Context, rowIndex int, row []string, parse importRowFunc[T]) (result importResult[T], err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if recovered, ok := r.(error); ok {
+				err = fmt.Errorf("row %d: %w", rowIndex+1, recovered)
+				return
+			}
+			err = fmt.Errorf("row %d: %v", rowIndex+1, r)
+		}
+	}()
+
+	result.rowIndex = rowIndex
+	result.value, result.issues, err = parse(ctx, rowIndex, row)
+	return result, err
+}
+
+// importChunks calls write with consecutive chunks of at most batchSize
+// values, stopping when ctx ends. Progress is reported from importReadShare
+// on.
+func importChunks[T any](ctx context.Context, values []T, batchSize int, write func(chunk []T) error) error {
+	if batchSize <= 0 {
+		batchSize = config.DefaultImportBatchSize
+	}
+
+	for start := 0; start < len(values); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import stopped: %w", err)
+		}
+
+		end := start + batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		if err := write(values[start:end]); err != nil {
+			return err
+		}
+		reportProgress(ctx, percentOf(end, len(values), importReadShare, 100))
+	}
+	return nil
+}
+
+// importPreviewRejected describes a rejected row in a dry-run preview.
+func importPreviewRejected[T any](result importResult[T], email string) entity.ImportPreviewRow {
+	row := entity.ImportPreviewRow{Row: result.rowIndex + 1, Action: entity.ImportActionReject, Email: email}
+	for _, issue := range result.issues {
+		row.Errors = append(row.Errors, issue.Message)
+	}
+	return row
+}
+
+// existsFunc reports whether a unique column value is already stored.
+type existsFunc func(ctx context.Context, key string, value string) bool
+
+// importedSheet is an uploaded file read with a schema: the cell index of
+// each import column, -1 when absent, and the valid and rejected rows in
+// file order.
+type importedSheet[T any] struct {
+	table     tabular.Table
+	columns   []tabular.Column[T]
+	positions []int
+	valid     []importResult[T]
+	rejected  []importResult[T]
+}
+
+// readSheet reads the uploaded file with schema, in any format tabular.Read
+// detects. The columns are found by the header row, see
+// tabular.Schema.Locate. Every row below it is validated on a pool of
+// workers and, when exists is set, checked against stored rows.
+func readSheet[T any](ctx context.Context, file *bytes.Reader, schema tabular.Schema[T], mapping map[string]string, options tabular.Options, validate *validator.Validate, workers int, exists existsFunc) (imported importedSheet[T]) {
+	table := openTable(file, options)
+	if table.Name == "" {
+		table.Name = schema.Sheet
+	}
+
+	var headers []string
+	if len(table.Rows) > 0 {
+		headers = table.Rows[0]
+	}
+
+	columns := schema.Imported()
+	positions, err := schema.Locate(headers, mapping)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	imported = importedSheet[T]{table: table, columns: columns, positions: positions}
+
+	results, err := importRows(ctx, table.Rows, workers, func(ctx context.Context, rowIndex int, row []string) (value T, issues []importIssue, err error) {
+		rowNumber := rowIndex + 1
+
+		for i, column := range columns {
+			text := strings.TrimSpace(tabular.Value(row, positions[i]))
+			if column.Validate != "" {
+				if err := validate.Var(text, column.Validate); err != nil {
+					issues = append(issues, importIssue{Field: column.Key, Row: rowNumber, Message: importMessage(column.Key, err)})
+					continue
+				}
+			}
+			if text == "" {
+				continue
+			}
+			if err := column.Parse(ctx, text, &value); err != nil {
+				issues = append(issues, importIssue{Field: column.Key, Row: rowNumber, Message: err.Error()})
+			}
+		}
+
+		if len(issues) > 0 || exists == nil {
+			return value, issues, nil
+		}
+		for _, column := range columns {
+			if !column.Unique {
+				continue
+			}
+			unique := uniqueValue(column, value)
+			if exists(ctx, column.Key, unique) {
+				issues = append(issues, importIssue{Field: column.Key, Row: rowNumber, Message: fmt.Sprintf("%s '%s' already taken", column.Key, unique)})
+				break
+			}
+		}
+		return value, issues, nil
+	})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	// Duplicates within the file are settled in file order, so the first
+	// of two rows sharing a unique value is the one kept.
+	uniqueTracker := make(map[string]map[string]bool)
+	for _, column := range columns {
+		if column.Unique {
+			uniqueTracker[column.Key] = make(map[string]bool)
+		}
+	}
+
+	for _, result := range results {
+		if len(result.issues) == 0 {
+			for _, column := range columns {
+				if !column.Unique {
+					continue
+				}
+				unique := uniqueValue(column, result.value)
+				if uniqueTracker[column.Key][unique] {
+					result.issues = append(result.issues, importIssue{Field: column.Key, Row: result.rowIndex + 1, Message: fmt.Sprintf("%s '%s' is not unique", column.Key, unique)})
+					break
+				}
+			}
+		}
+		if len(result.issues) > 0 {
+			imported.rejected = append(imported.rejected, result)
+			continue
+		}
+
+		for _, column := range columns {
+			if column.Unique {
+				uniqueTracker[column.Key][uniqueValue(column, result.value)] = true
+			}
+		}
+		imported.valid = append(imported.valid, result)
+	}
+
+	return imported
+}
+
+// errorWorkbook copies the file to a workbook with an Errors column listing the issues
+// of each row and the offending cells highlighted. Without keepValid only
+// the header and the rejected rows are copied, so the fixed file can be
+// uploaded again without repeating the rows already imported.
+func (imported importedSheet[T]) errorWorkbook(keepValid bool) (*xlsx.File, error) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet(imported.table.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	header := tabular.HeaderStyle()
+
+	errorStyle := xlsx.NewStyle()
+	errorStyle.Fill = *xlsx.NewFill("solid", "00FFC7CE", "00FFC7CE") // Light red background
+
+	issues := make(map[int][]importIssue, len(imported.rejected))
+	for _, result := range imported.rejected {
+		issues[result.rowIndex] = result.issues
+	}
+	positions := make(map[string]int, len(imported.columns))
+	for i, column := range imported.columns {
+		positions[column.Key] = imported.positions[i]
+	}
+
+	width := 0
+	for _, row := range imported.table.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	for rowIndex, row := range imported.table.Rows {
+		rowIssues, rejected := issues[rowIndex]
+		if rowIndex > 0 && !rejected && !keepValid {
+			continue
+		}
+
+		highlighted := make(map[int]bool)
+		var messages []string
+		for _, issue := range rowIssues {
+			if position, ok := positions[issue.Field]; ok && position >= 0 {
+				highlighted[position] = true
+			}
+			messages = append(messages, issue.Message)
+		}
+
+		copied := sheet.AddRow()
+		for colIndex := 0; colIndex < width; colIndex++ {
+			cell := copied.AddCell()
+			cell.Value = tabular.Value(row, colIndex)
+			if rowIndex == 0 {
+				cell.SetStyle(header)
+			} else if highlighted[colIndex] {
+				cell.SetStyle(errorStyle)
+			}
+		}
+
+		cell := copied.AddCell()
+		if rowIndex == 0 {
+			cell.Value = "Errors"
+			cell.SetStyle(header)
+			continue
+		}
+		cell.Value = strings.Join(messages, "; ")
+	}
+
+	return file, nil
+}
+
+// openTable reads the rows of an uploaded file. A file that cannot be read
+// in any of the formats is a bad request.
+func openTable(file *bytes.Reader, options tabular.Options) tabular.Table {
+	table, err := tabular.Read(file, file.Size(), options)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	return table
+}
+
+// importOptions reads the CSV options of an import request. The format
+// itself is detected from the file.
+func importOptions(delimiter string, encoding string) tabular.Options {
+	options, err := tabular.ParseOptions("", delimiter, encoding)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	return options
+}
+
+// uniqueValue is the value a unique column is compared by: the exported
+// form of the parsed value, so differently written duplicates still match.
+func uniqueValue[T any](column tabular.Column[T], value T) string {
+	if column.Format == nil {
+		return ""
+	}
+	return fmt.Sprint(column.Format(value))
+}
+
+// importMessage words a failed validation tag of a column.
+func importMessage(key string, err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) == 0 {
+		return err.Error()
+	}
+
+	e := validationErrors[0]
+	switch e.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", key)
+	case "email":
+		return fmt.Sprintf("%s is not valid email", key)
+	case "phone":
+		return fmt.Sprintf("%s value must be a valid phone number, e.g. +6281234567890", key)
+	case "date":
+		return fmt.Sprintf("%s value must be date (yyyy-mm-dd)", key)
+	case "oneof":
+		return fmt.Sprintf("%s value must be %s", key, e.Param())
+	case "max":
+		return fmt.Sprintf("%s value must be lower than %s", key, e.Param())
+	case "min":
+		return fmt.Sprintf("%s value must be greater than %s", key, e.Param())
+	}
+	return fmt.Sprintf("%s value is not valid", key)
+}
+
+// importMapping returns the header mapping of an import: the saved profile
+// named in the request, overridden by the request's own mapping, a JSON
+// object from file header to column key.
+func importMapping(ctx context.Context, importProfileRepo repository.ImportProfileRepo, resource string, profile string, mapping string) map[string]string {
+	result := make(map[string]string)
+	if profile != "" {
+		saved, err := importProfileRepo.FindByName(ctx, resource, profile)
+		if err != nil {
+			panic(exception.NewNotFoundHandler(fmt.Sprintf("import profile '%s' not found", profile)))
+		}
+		for header, key := range saved.Mapping {
+			result[tabular.NormalizeHeader(header)] = key
+		}
+	}
+
+	if mapping != "" {
+		var requested map[string]string
+		if err := json.Unmarshal([]byte(mapping), &requested); err != nil {
+			panic(exception.NewBadRequestHandler("mapping must be a JSON object from file header to column key"))
+		}
+		for header, key := range requested {
+			result[tabular.NormalizeHeader(header)] = key
+		}
+	}
+
+	return result
+}
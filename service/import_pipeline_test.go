@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// numberedRows is a header and n data rows holding their own row index.
+func numberedRows(n int) [][]string {
+	rows := [][]string{{"index"}}
+	for i := 1; i <= n; i++ {
+		rows = append(rows, []string{strconv.Itoa(i)})
+	}
+	return rows
+}
+
+func parseIndex(ctx context.Context, rowIndex int, row []string) (int, []importIssue, error) {
+	// Later rows finish first, so results arrive out of order.
+	time.Sleep(time.Duration(10-rowIndex%10) * 100 * time.Microsecond)
+
+	value, err := strconv.Atoi(row[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	var issues []importIssue
+	if value%7 == 0 {
+		issues = append(issues, importIssue{Field: "index", Row: rowIndex + 1, Message: "multiple of 7"})
+	}
+	return value, issues, nil
+}
+
+func TestImportRowsOrder(t *testing.T) {
+	results, err := importRows(context.Background(), numberedRows(200), 8, parseIndex)
+	if err != nil {
+		t.Fatalf("importRows: %v", err)
+	}
+
+	if len(results) != 200 {
+		t.Fatalf("got %d results, want 200", len(results))
+	}
+	for i, result := range results {
+		if result.rowIndex != i+1 || result.value != i+1 {
+			t.Fatalf("result %d is row %d with value %d, want row and value %d", i, result.rowIndex, result.value, i+1)
+		}
+		if wantIssue := result.value%7 == 0; (len(result.issues) == 1) != wantIssue {
+			t.Errorf("row %d has issues %v", result.rowIndex, result.issues)
+		}
+	}
+}
+
+func TestImportRowsHeaderOnly(t *testing.T) {
+	results, err := importRows(context.Background(), numberedRows(0), 4, parseIndex)
+	if err != nil || len(results) != 0 {
+		t.Errorf("importRows of a header = %v, %v; want no results", results, err)
+	}
+}
+
+func TestImportRowsError(t *testing.T) {
+	rows := numberedRows(100)
+	rows[40] = []string{"forty"}
+
+	results, err := importRows(context.Background(), rows, 4, parseIndex)
+	if err == nil || !strings.Contains(err.Error(), "forty") {
+		t.Errorf("importRows returned %v, want the parse error", err)
+	}
+	if results != nil {
+		t.Errorf("importRows returned %d results with its error", len(results))
+	}
+}
+
+func TestImportRowsPanic(t *testing.T) {
+	parse := func(ctx context.Context, rowIndex int, row []string) (int, []importIssue, error) {
+		if rowIndex == 3 {
+			panic(errors.New("mapping broke"))
+		}
+		return rowIndex, nil, nil
+	}
+
+	_, err := importRows(context.Background(), numberedRows(10), 2, parse)
+	if err == nil || err.Error() != "row 4: mapping broke" {
+		t.Errorf("importRows returned %v, want row 4: mapping broke", err)
+	}
+}
+
+func TestImportRowsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var parsed atomic.Int32
+	parse := func(ctx context.Context, rowIndex int, row []string) (int, []importIssue, error) {
+		if parsed.Add(1) == 10 {
+			cancel()
+		}
+		return rowIndex, nil, nil
+	}
+
+	results, err := importRows(ctx, numberedRows(10000), 4, parse)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("importRows returned %v, want context.Canceled", err)
+	}
+	if results != nil {
+		t.Errorf("importRows returned %d results after being cancelled", len(results))
+	}
+	if n := parsed.Load(); n >= 10000 {
+		t.Errorf("parsed all %d rows after being cancelled", n)
+	}
+}
+
+func TestImportChunks(t *testing.T) {
+	values := []int{1, 2, 3, 4, 5, 6, 7}
+
+	var sizes []int
+	err := importChunks(context.Background(), values, 3, func(chunk []int) error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Errorf("importChunks wrote chunks of %v, %v; want 3, 3 and 1", sizes, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	written := 0
+	err = importChunks(ctx, values, 2, func(chunk []int) error {
+		written += len(chunk)
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || written != 2 {
+		t.Errorf("cancelled importChunks wrote %d values and returned %v, want 2 and context.Canceled", written, err)
+	}
+}
+
+func TestImportMapping(t *testing.T) {
+	profiles := &fakeImportProfileRepo{profiles: []model.ImportProfile{{
+		Resource: model.ImportResourceCustomers,
+		Name:     "partner",
+		Mapping:  map[string]string{"Full Name": "username", "E-Mail": "email", "Fax": ""},
+	}}}
+
+	mapping := importMapping(context.Background(), profiles, model.ImportResourceCustomers, "partner", `{"Fax": "phone", "Mobile": ""}`)
+
+	want := map[string]string{"fullname": "username", "email": "email", "fax": "phone", "mobile": ""}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("importMapping = %v, want %v", mapping, want)
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		profile  string
+		mapping  string
+		check    func(value interface{}) bool
+	}{
+		{
+			name:     "profile of another resource",
+			resource: model.ImportResourceUsers,
+			profile:  "partner",
+			check:    func(value interface{}) bool { _, ok := value.(*exception.NotFoundErrorStruct); return ok },
+		},
+		{
+			name:     "mapping that is not an object",
+			resource: model.ImportResourceCustomers,
+			mapping:  `["username"]`,
+			check:    func(value interface{}) bool { _, ok := value.(*exception.BadRequestErrorStruct); return ok },
+		},
+	}
+	for _, test := range tests {
+		value := raised(func() { importMapping(context.Background(), profiles, test.resource, test.profile, test.mapping) })
+		if !test.check(value) {
+			t.Errorf("%s: raised %#v", test.name, value)
+		}
+	}
+}
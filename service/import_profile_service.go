@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/utils"
+	"scylla/repository"
+)
+
+type ImportProfileService interface {
+	Create(ctx context.Context, request entity.CreateImportProfileRequest) (response entity.ImportProfileResponse)
+	Update(ctx context.Context, request entity.UpdateImportProfileRequest)
+	Delete(ctx context.Context, params entity.ImportProfileParams)
+	FindAll(ctx context.Context, dataFilter entity.ImportProfileQueryFilter) (response []entity.ImportProfileResponse)
+}
+
+type ImportProfileServiceImpl struct {
+	importProfileRepo repository.ImportProfileRepo
+	customFieldRepo   repository.CustomFieldRepo
+	validate          *validator.Validate
+}
+
+func NewImportProfileServiceImpl(importProfileRepo repository.ImportProfileRepo, customFieldRepo repository.CustomFieldRepo, validate *validator.Validate) ImportProfileService {
+	return &ImportProfileServiceImpl{
+		importProfileRepo: importProfileRepo,
+		customFieldRepo:   customFieldRepo,
+		validate:          validate,
+	}
+}
+
+func (service *ImportProfileServiceImpl) Create(ctx context.Context, request entity.CreateImportProfileRequest) (response entity.ImportProfileResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	service.checkMapping(ctx, request.Resource, request.Mapping)
+	service.checkName(ctx, request.Resource, request.Name, 0)
+
+	dataset := model.ImportProfile{
+		Resource:  request.Resource,
+		Name:      request.Name,
+		Mapping:   request.Mapping,
+		CreatedBy: utils.ActorFromContext(ctx),
+	}
+
+	dataset, err = service.importProfileRepo.Insert(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	helper.Automapper(dataset, &response)
+	return response
+}
+
+func (service *ImportProfileServiceImpl) Update(ctx context.Context, request entity.UpdateImportProfileRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.importProfileRepo.FindById(ctx, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	service.checkMapping(ctx, dataset.Resource, request.Mapping)
+	service.checkName(ctx, dataset.Resource, request.Name, dataset.ID)
+
+	dataset.Name = request.Name
+	dataset.Mapping = request.Mapping
+
+	err = service.importProfileRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *ImportProfileServiceImpl) Delete(ctx context.Context, params entity.ImportProfileParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.importProfileRepo.Delete(ctx, params.ImportProfileId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *ImportProfileServiceImpl) FindAll(ctx context.Context, dataFilter entity.ImportProfileQueryFilter) (response []entity.ImportProfileResponse) {
+	err := service.validate.Struct(dataFilter)
+	helper.ErrorPanic(err)
+
+	result, err := service.importProfileRepo.FindAll(ctx, dataFilter)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		var res entity.ImportProfileResponse
+		helper.Automapper(row, &res)
+		response = append(response, res)
+	}
+	return response
+}
+
+// checkMapping rejects a mapping to columns the resource's import does not
+// read. Custom fields count as customer columns.
+func (service *ImportProfileServiceImpl) checkMapping(ctx context.Context, resource string, mapping map[string]string) {
+	var err error
+	switch resource {
+	case model.ImportResourceCustomers:
+		definitions, findErr := service.customFieldRepo.FindAll(ctx)
+		if findErr != nil {
+			panic(exception.NewInternalServerErrorHandler(findErr.Error()))
+		}
+		err = customerSchema(nil, definitions).CheckMapping(mapping)
+	case model.ImportResourceUsers:
+		err = userSchema.CheckMapping(mapping)
+	}
+
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+}
+
+// checkName rejects a name another profile of the resource already has.
+func (service *ImportProfileServiceImpl) checkName(ctx context.Context, resource string, name string, Id int) {
+	existing, err := service.importProfileRepo.FindByName(ctx, resource, name)
+	if err == nil && existing.ID != Id {
+		panic(exception.NewConflictHandler(fmt.Sprintf("import profile '%s' already exists", name)))
+	}
+}
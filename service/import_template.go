@@ -0,0 +1,100 @@
+package service
+
+import (
+	"github.com/tealeg/xlsx"
+	"io"
+	"scylla/pkg/tabular"
+)
+
+const (
+	// templateRows is how many rows below the header of an import template
+	// offer the dropdowns of enumerated columns.
+	templateRows = 1000
+
+	templateNotesSheet   = "Notes"
+	templateOptionsSheet = "Options"
+)
+
+// writeTemplate writes the import template of schema to w. The first sheet has the
+// headers of the import columns and an example row; enumerated columns
+// offer their options as a dropdown, read from an Options sheet. A Notes
+// sheet lists the rules the importer applies to each column.
+func writeTemplate[T any](w io.Writer, schema tabular.Schema[T]) error {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet(schema.Sheet)
+	if err != nil {
+		return err
+	}
+	notes, err := file.AddSheet(templateNotesSheet)
+	if err != nil {
+		return err
+	}
+
+	columns := schema.Imported()
+	style := tabular.HeaderStyle()
+
+	headerRow := sheet.AddRow()
+	exampleRow := sheet.AddRow()
+	for _, column := range columns {
+		cell := headerRow.AddCell()
+		cell.Value = column.Header
+		cell.SetStyle(style)
+		exampleRow.AddCell().Value = column.Example
+	}
+	sheet.SetColWidth(0, len(columns)-1, 24)
+
+	noteHeader := notes.AddRow()
+	for _, title := range []string{"Column", "Key", "Required", "Rules", "Example"} {
+		cell := noteHeader.AddCell()
+		cell.Value = title
+		cell.SetStyle(style)
+	}
+	for _, column := range columns {
+		row := notes.AddRow()
+		row.AddCell().Value = column.Header
+		row.AddCell().Value = column.Key
+		row.AddCell().SetBool(column.Required())
+		row.AddCell().Value = column.Rules()
+		row.AddCell().Value = column.Example
+	}
+	notes.SetColWidth(0, 1, 20)
+	notes.SetColWidth(3, 3, 80)
+	notes.SetColWidth(4, 4, 28)
+
+	// Options are listed on a sheet of their own rather than inlined in the
+	// validation, whose formula is limited to 255 characters and cannot hold
+	// values with commas.
+	var options *xlsx.Sheet
+	optionCol := 0
+	for colIndex, column := range columns {
+		if len(column.Options) == 0 {
+			continue
+		}
+		if options == nil {
+			options, err = file.AddSheet(templateOptionsSheet)
+			if err != nil {
+				return err
+			}
+		}
+
+		cell := options.Cell(0, optionCol)
+		cell.Value = column.Header
+		cell.SetStyle(style)
+		for i, option := range column.Options {
+			options.Cell(i+1, optionCol).Value = option
+		}
+		options.SetColWidth(optionCol, optionCol, 24)
+
+		validation := xlsx.NewXlsxCellDataValidation(!column.Required())
+		if err := validation.SetInFileList(templateOptionsSheet, optionCol, 1, optionCol, len(column.Options)); err != nil {
+			return err
+		}
+		title := column.Header
+		message := "Choose a value from the list."
+		validation.SetError(xlsx.StyleStop, &title, &message)
+		sheet.Col(colIndex).SetDataValidation(validation, 1, templateRows)
+		optionCol++
+	}
+
+	return file.Write(w)
+}
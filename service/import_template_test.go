@@ -0,0 +1,123 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"github.com/tealeg/xlsx"
+	"io"
+	"reflect"
+	"scylla/model"
+	"scylla/pkg/helper"
+	"strings"
+	"testing"
+)
+
+// sheetXML returns the xml of the first worksheet of an xlsx file.
+func sheetXML(t *testing.T, content []byte) string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("reading xlsx: %v", err)
+	}
+	for _, file := range archive.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		body, err := file.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", file.Name, err)
+		}
+		defer body.Close()
+		xml, _ := io.ReadAll(body)
+		return string(xml)
+	}
+	t.Fatal("xlsx has no first worksheet")
+	return ""
+}
+
+func cellValues(row *xlsx.Row) []string {
+	var values []string
+	for _, cell := range row.Cells {
+		values = append(values, cell.Value)
+	}
+	return values
+}
+
+func TestCustomerImportTemplate(t *testing.T) {
+	service := newTestCustomerService(storedCustomers(), &fakeRevisionRepo{})
+	service.customFieldRepo = &fakeCustomFieldRepo{definitions: []model.CustomFieldDefinition{
+		{Key: "tier", Label: "Tier", Type: model.CustomFieldEnum, Required: true, Options: []string{"gold", "silver", "bronze"}},
+		{Key: "seats", Label: "Seats", Type: model.CustomFieldNumber},
+	}}
+
+	var buffer bytes.Buffer
+	if err := service.ImportTemplate(context.Background(), &buffer); err != nil {
+		t.Fatalf("ImportTemplate: %v", err)
+	}
+	workbook, err := xlsx.OpenBinary(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("opening template: %v", err)
+	}
+
+	var names []string
+	for _, sheet := range workbook.Sheets {
+		names = append(names, sheet.Name)
+	}
+	if want := []string{"Customer", templateNotesSheet, templateOptionsSheet}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("sheets %v, want %v", names, want)
+	}
+
+	customer := workbook.Sheets[0]
+	headers := cellValues(customer.Rows[0])
+	if want := []string{"Username", "Email", "Phone", "Address", helper.OwnerExcelColumn, "Tier", "Seats"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers %v, want %v", headers, want)
+	}
+	if example := customer.Rows[1].Cells[5].Value; example != "gold" {
+		t.Errorf("tier example %q, want the first option", example)
+	}
+
+	notes := workbook.Sheets[1]
+	if len(notes.Rows) != len(headers)+1 {
+		t.Errorf("notes have %d rows, want a header and one row per column", len(notes.Rows))
+	}
+	if tier := cellValues(notes.Rows[6]); tier[1] != "tier" || tier[2] != "1" {
+		t.Errorf("tier note %v, want the key and required", tier)
+	}
+
+	var options []string
+	for _, row := range workbook.Sheets[2].Rows {
+		options = append(options, row.Cells[0].Value)
+	}
+	if want := []string{"Tier", "gold", "silver", "bronze"}; !reflect.DeepEqual(options, want) {
+		t.Errorf("options %v, want %v", options, want)
+	}
+
+	xml := sheetXML(t, buffer.Bytes())
+	if !strings.Contains(xml, `sqref="F2:F1001"`) || !strings.Contains(xml, "&#39;"+templateOptionsSheet+"&#39;!$A$2:$A$4") {
+		t.Errorf("tier column has no dropdown of the options sheet")
+	}
+}
+
+func TestUserImportTemplate(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := (&UserServiceImpl{}).ImportTemplate(context.Background(), &buffer); err != nil {
+		t.Fatalf("ImportTemplate: %v", err)
+	}
+	workbook, err := xlsx.OpenBinary(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("opening template: %v", err)
+	}
+
+	headers := cellValues(workbook.Sheets[0].Rows[0])
+	var want []string
+	for _, column := range userSchema.Imported() {
+		want = append(want, column.Header)
+	}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers %v, want %v", headers, want)
+	}
+	if len(workbook.Sheets) != 2 {
+		t.Errorf("template has %d sheets, want no options sheet", len(workbook.Sheets))
+	}
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"mime/multipart"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"sync"
+	"time"
+)
+
+// jobsPrefix is the storage folder of the files uploaded to and produced by
+// jobs, one folder per job.
+const jobsPrefix = "jobs/"
+
+// JobFunc does the work of a job. upload holds the file the job was
+// enqueued with, nil without one, and output receives the file the job
+// produces, discarded unless the job names one. result becomes the job's
+// result payload, also when err fails the job.
+type JobFunc func(ctx context.Context, upload *bytes.Reader, output io.Writer) (result interface{}, err error)
+
+type JobService interface {
+	Enqueue(ctx context.Context, job model.Job, upload *multipart.FileHeader, run JobFunc) (response entity.JobResponse)
+	FindById(ctx context.Context, params entity.JobParams) (response entity.JobResponse)
+	Result(ctx context.Context, params entity.JobParams) (response entity.JobResponse, body io.ReadCloser)
+	Cancel(ctx context.Context, params entity.JobParams) (response entity.JobResponse)
+	Monitor(ctx context.Context)
+}
+
+// JobServiceImpl runs jobs in this process, at most workers at a time; the
+// others wait in the queued state. Several servers can share the jobs
+// table: each owns the jobs it enqueued under its instance id.
+type JobServiceImpl struct {
+	jobRepo     repository.JobRepo
+	fileStorage storage.Storage
+	validate    *validator.Validate
+	instance    string
+	// slots holds a token for every running job.
+	slots chan struct{}
+
+	mutex sync.Mutex
+	runs  map[string]*jobRun
+}
+
+// jobRun is a job enqueued by this process that has not finished yet.
+type jobRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewJobServiceImpl(jobRepo repository.JobRepo, fileStorage storage.Storage, workers int, validate *validator.Validate) JobService {
+	if workers <= 0 {
+		workers = config.DefaultJobWorkers
+	}
+
+	return &JobServiceImpl{
+		jobRepo:     jobRepo,
+		fileStorage: fileStorage,
+		validate:    validate,
+		instance:    uuid.New().String(),
+		slots:       make(chan struct{}, workers),
+		runs:        make(map[string]*jobRun),
+	}
+}
+
+// Enqueue records job as queued for the current user, stores the uploaded
+// file it reads, if any, and starts run in the background. The job outlives
+// ctx: it keeps its values, such as the actor, but not its deadline.
+func (service *JobServiceImpl) Enqueue(ctx context.Context, job model.Job, upload *multipart.FileHeader, run JobFunc) (response entity.JobResponse) {
+	job.ID = uuid.New().String()
+	job.State = model.JobQueued
+	job.CreatedBy = utils.ActorFromContext(ctx)
+	job.Owner = service.instance
+	now := time.Now()
+	job.HeartbeatAt = &now
+
+	if upload != nil {
+		job.UploadKey = jobsPrefix + job.ID + "/upload"
+
+		src, err := upload.Open()
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+		defer src.Close()
+
+		err = service.fileStorage.Put(ctx, job.UploadKey, src, upload.Size, "application/octet-stream")
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	}
+
+	job, err := service.jobRepo.Insert(ctx, job)
+	if err != nil {
+		service.removeFile(job.UploadKey)
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	current := &jobRun{cancel: cancel, done: make(chan struct{})}
+
+	service.mutex.Lock()
+	service.runs[job.ID] = current
+	service.mutex.Unlock()
+
+	go service.run(runCtx, job, run, current)
+
+	return jobResponse(job)
+}
+
+func (service *JobServiceImpl) FindById(ctx context.Context, params entity.JobParams) (response entity.JobResponse) {
+	return jobResponse(service.findJob(ctx, params))
+}
+
+// Result opens the file of a succeeded job.
+func (service *JobServiceImpl) Result(ctx context.Context, params entity.JobParams) (response entity.JobResponse, body io.ReadCloser) {
+	job := service.findJob(ctx, params)
+	if job.State != model.JobSucceeded {
+		panic(exception.NewConflictHandler(fmt.Sprintf("job is %s", job.State)))
+	}
+	if job.FileKey == "" {
+		panic(exception.NewNotFoundHandler("job has no result file"))
+	}
+
+	body, err := service.fileStorage.Open(ctx, job.FileKey)
+	if err == storage.ErrNotFound {
+		panic(exception.NewNotFoundHandler("result file not found"))
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	return jobResponse(job), body
+}
+
+// Cancel stops a queued or running job and waits for it to record that it
+// was cancelled, for as long as ctx allows. A job run by another server is
+// flagged for it to cancel on its next heartbeat.
+func (service *JobServiceImpl) Cancel(ctx context.Context, params entity.JobParams) (response entity.JobResponse) {
+	job := service.findJob(ctx, params)
+	if job.Finished() {
+		panic(exception.NewConflictHandler(fmt.Sprintf("job is already %s", job.State)))
+	}
+
+	service.mutex.Lock()
+	current, ok := service.runs[job.ID]
+	service.mutex.Unlock()
+	if ok {
+		current.cancel()
+		select {
+		case <-current.done:
+		case <-ctx.Done():
+		}
+		return service.FindById(ctx, params)
+	}
+
+	if err := service.jobRepo.RequestCancel(ctx, job.ID); err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return service.FindById(context.WithoutCancel(ctx), params)
+		}
+
+		if job, err := service.jobRepo.FindById(ctx, job.ID); err == nil && job.Finished() {
+			return jobResponse(job)
+		}
+	}
+}
+
+// Monitor keeps the jobs of this server alive until ctx is done. Every
+// config.JobHeartbeat it refreshes their heartbeat, cancels those asked to
+// be cancelled elsewhere and fails the jobs of any server that stopped
+// beating, whose work was lost with it.
+func (service *JobServiceImpl) Monitor(ctx context.Context) {
+	ticker := time.NewTicker(config.JobHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		service.heartbeat(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *JobServiceImpl) heartbeat(ctx context.Context) {
+	cancelled, err := service.jobRepo.Heartbeat(ctx, service.instance)
+	if err != nil {
+		log.Printf("job: heartbeat: %v", err)
+	}
+
+	service.mutex.Lock()
+	for _, id := range cancelled {
+		if current, ok := service.runs[id]; ok {
+			current.cancel()
+		}
+	}
+	service.mutex.Unlock()
+
+	failed, err := service.jobRepo.FailAbandoned(ctx, time.Now().Add(-config.JobAbandonedAfter), jobError(errors.New("job was abandoned by a stopped server")))
+	if err != nil {
+		log.Printf("job: failing abandoned jobs: %v", err)
+		return
+	}
+	if failed > 0 {
+		log.Printf("job: failed %d jobs abandoned by a stopped server", failed)
+	}
+}
+
+// findJob loads a job of the current user; the jobs of others are not found.
+func (service *JobServiceImpl) findJob(ctx context.Context, params entity.JobParams) model.Job {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	job, err := service.jobRepo.FindById(ctx, params.JobId)
+	if err != nil || job.CreatedBy != utils.ActorFromContext(ctx) {
+		panic(exception.NewNotFoundHandler("job not found"))
+	}
+	return job
+}
+
+// run waits for a free worker, does the job and records how it ended. The
+// job is cancelled when ctx is.
+func (service *JobServiceImpl) run(ctx context.Context, job model.Job, fn JobFunc, current *jobRun) {
+	defer func() {
+		service.mutex.Lock()
+		delete(service.runs, job.ID)
+		service.mutex.Unlock()
+		current.cancel()
+		close(current.done)
+	}()
+	defer service.removeFile(job.UploadKey)
+
+	// The outcome is recorded even when the job was cancelled.
+	record := context.WithoutCancel(ctx)
+
+	select {
+	case service.slots <- struct{}{}:
+		defer func() { <-service.slots }()
+	case <-ctx.Done():
+		service.finish(record, &job, true, nil, nil)
+		return
+	}
+
+	startedAt := time.Now()
+	job.State = model.JobRunning
+	job.StartedAt = &startedAt
+	if err := service.jobRepo.Update(record, job); err != nil {
+		log.Printf("job: %s: recording start: %v", job.ID, err)
+	}
+
+	progress := &jobProgress{jobRepo: service.jobRepo, id: job.ID}
+	result, err := service.execute(context.WithValue(ctx, jobProgressKey{}, progress), &job, fn)
+	service.finish(record, &job, ctx.Err() != nil, result, err)
+}
+
+// execute calls fn with the job's upload and output and stores the output.
+// A panic in fn, such as an exception raised by a service, is returned as
+// its error.
+func (service *JobServiceImpl) execute(ctx context.Context, job *model.Job, fn JobFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+	}()
+
+	var upload *bytes.Reader
+	if job.UploadKey != "" {
+		upload, err = service.readUpload(ctx, job.UploadKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if job.FileName == "" {
+		return fn(ctx, upload, io.Discard)
+	}
+
+	// The output streams into storage as fn writes it. An error of fn
+	// reaches Put through the pipe, which then stores nothing; an error of
+	// Put makes the next write of fn fail.
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(r)
+			}
+			writer.CloseWithError(err)
+		}()
+
+		result, err = fn(ctx, upload, writer)
+	}()
+
+	key := jobsPrefix + job.ID + "/" + job.FileName
+	putErr := service.fileStorage.Put(ctx, key, reader, -1, job.ContentType)
+	reader.CloseWithError(putErr)
+	<-done
+
+	if err != nil {
+		return result, err
+	}
+	if putErr != nil {
+		return result, putErr
+	}
+	job.FileKey = key
+	return result, nil
+}
+
+// recoveredError turns the value of a recovered panic into an error.
+func recoveredError(r interface{}) error {
+	if recovered, ok := r.(error); ok {
+		return recovered
+	}
+	return fmt.Errorf("%v", r)
+}
+
+// finish records the final state of job: cancelled, failed on err or
+// succeeded.
+func (service *JobServiceImpl) finish(ctx context.Context, job *model.Job, cancelled bool, result interface{}, err error) {
+	if result != nil {
+		encoded, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			log.Printf("job: %s: encoding result: %v", job.ID, marshalErr)
+		}
+		job.Result = encoded
+	}
+
+	switch {
+	case cancelled:
+		job.State = model.JobCancelled
+		job.Error = jobError(errors.New("job was cancelled"))
+	case err != nil:
+		job.State = model.JobFailed
+		job.Error = jobError(err)
+		log.Printf("job: %s: %s of %s failed: %v", job.ID, job.Type, job.Resource, err)
+	default:
+		job.State = model.JobSucceeded
+		job.Progress = 100
+	}
+
+	// Only a succeeded job offers its file.
+	if job.State != model.JobSucceeded && job.FileKey != "" {
+		service.removeFile(job.FileKey)
+		job.FileKey = ""
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if err := service.jobRepo.Update(ctx, *job); err != nil {
+		log.Printf("job: %s: recording %s: %v", job.ID, job.State, err)
+	}
+}
+
+// readUpload loads the uploaded file of a job. Every format is read whole
+// anyway, so it is kept in memory.
+func (service *JobServiceImpl) readUpload(ctx context.Context, key string) (*bytes.Reader, error) {
+	body, err := service.fileStorage.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+// removeFile deletes a stored file no job needs anymore. Failing to remove
+// it only leaves it behind, so it is logged.
+func (service *JobServiceImpl) removeFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := service.fileStorage.Delete(context.Background(), key); err != nil {
+		log.Printf("job: removing %s: %v", key, err)
+	}
+}
+
+type jobProgressKey struct{}
+
+// jobProgress saves the progress of a running job whenever it grows.
+type jobProgress struct {
+	jobRepo repository.JobRepo
+	id      string
+
+	mutex   sync.Mutex
+	percent int
+}
+
+// reportProgress records that the job running with ctx is percent done.
+// Outside of a job it does nothing. 100 is only reached when the job
+// succeeds.
+func reportProgress(ctx context.Context, percent int) {
+	progress, ok := ctx.Value(jobProgressKey{}).(*jobProgress)
+	if !ok {
+		return
+	}
+	if percent > 99 {
+		percent = 99
+	}
+
+	progress.mutex.Lock()
+	if percent <= progress.percent {
+		progress.mutex.Unlock()
+		return
+	}
+	progress.percent = percent
+	progress.mutex.Unlock()
+
+	if err := progress.jobRepo.UpdateProgress(ctx, progress.id, percent); err != nil && ctx.Err() == nil {
+		log.Printf("job: %s: recording progress: %v", progress.id, err)
+	}
+}
+
+// percentOf maps done of total steps onto the range from..to of a job's
+// progress.
+func percentOf(done int, total int, from int, to int) int {
+	if total <= 0 {
+		return to
+	}
+	return from + (to-from)*done/total
+}
+
+// jobError is the error payload of a job that failed with err. The
+// validation errors of an import keep their details.
+func jobError(err error) json.RawMessage {
+	payload := entity.JobError{Message: err.Error()}
+
+	var validation *exception.ExcelValidation
+	var userValidation *exception.NewExcelValidationError
+	switch {
+	case errors.As(err, &validation):
+		payload.Errors = validation.Errors
+		payload.ErrorFile = validation.ErrorFile
+	case errors.As(err, &userValidation):
+		payload.Errors = userValidation.Errors
+		payload.ErrorFile = userValidation.ErrorFile
+	}
+
+	encoded, _ := json.Marshal(payload)
+	return encoded
+}
+
+func jobResponse(job model.Job) entity.JobResponse {
+	response := entity.JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Resource:    job.Resource,
+		State:       job.State,
+		Progress:    job.Progress,
+		Result:      job.Result,
+		Error:       job.Error,
+		FileName:    job.FileName,
+		ContentType: job.ContentType,
+		CreatedBy:   job.CreatedBy,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+	}
+	if job.StartedAt != nil {
+		response.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if job.FinishedAt != nil {
+		response.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"scylla/pkg/utils"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJobRepo is the jobs table several servers share.
+type fakeJobRepo struct {
+	mutex sync.Mutex
+	jobs  map[string]model.Job
+}
+
+func newFakeJobRepo() *fakeJobRepo {
+	return &fakeJobRepo{jobs: make(map[string]model.Job)}
+}
+
+func (repo *fakeJobRepo) Insert(ctx context.Context, data model.Job) (model.Job, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	data.CreatedAt = time.Now()
+	repo.jobs[data.ID] = data
+	return data, nil
+}
+
+func (repo *fakeJobRepo) Update(ctx context.Context, data model.Job) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	stored, ok := repo.jobs[data.ID]
+	if !ok {
+		return errors.New("record not found")
+	}
+	stored.State, stored.Progress, stored.Result, stored.Error = data.State, data.Progress, data.Result, data.Error
+	stored.FileKey, stored.StartedAt, stored.FinishedAt = data.FileKey, data.StartedAt, data.FinishedAt
+	repo.jobs[data.ID] = stored
+	return nil
+}
+
+func (repo *fakeJobRepo) UpdateProgress(ctx context.Context, Id string, progress int) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if job, ok := repo.jobs[Id]; ok && job.State == model.JobRunning && job.Progress < progress {
+		job.Progress = progress
+		repo.jobs[Id] = job
+	}
+	return nil
+}
+
+func (repo *fakeJobRepo) FindById(ctx context.Context, Id string) (model.Job, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	job, ok := repo.jobs[Id]
+	if !ok {
+		return job, errors.New("record not found")
+	}
+	return job, nil
+}
+
+func (repo *fakeJobRepo) RequestCancel(ctx context.Context, Id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if job, ok := repo.jobs[Id]; ok && !job.Finished() {
+		job.CancelRequested = true
+		repo.jobs[Id] = job
+	}
+	return nil
+}
+
+func (repo *fakeJobRepo) Heartbeat(ctx context.Context, owner string) (cancelled []string, err error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	now := time.Now()
+	for id, job := range repo.jobs {
+		if job.Owner != owner || job.Finished() {
+			continue
+		}
+		job.HeartbeatAt = &now
+		repo.jobs[id] = job
+		if job.CancelRequested {
+			cancelled = append(cancelled, id)
+		}
+	}
+	return cancelled, nil
+}
+
+func (repo *fakeJobRepo) FailAbandoned(ctx context.Context, before time.Time, jobError json.RawMessage) (int64, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	var failed int64
+	for id, job := range repo.jobs {
+		if job.Finished() || (job.HeartbeatAt != nil && !job.HeartbeatAt.Before(before)) {
+			continue
+		}
+		now := time.Now()
+		job.State, job.Error, job.FinishedAt = model.JobFailed, jobError, &now
+		repo.jobs[id] = job
+		failed++
+	}
+	return failed, nil
+}
+
+func (repo *fakeJobRepo) job(id string) model.Job {
+	job, _ := repo.FindById(context.Background(), id)
+	return job
+}
+
+func newTestJobService(t *testing.T, jobs *fakeJobRepo, workers int) *JobServiceImpl {
+	return NewJobServiceImpl(jobs, storage.NewLocalStorage(t.TempDir()), workers, utils.InitializeValidator(nil)).(*JobServiceImpl)
+}
+
+// blockingJob runs until it is cancelled, closing started once it runs.
+func blockingJob(started chan struct{}) JobFunc {
+	return func(ctx context.Context, upload *bytes.Reader, output io.Writer) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+func exportJob() model.Job {
+	return model.Job{Type: model.JobExport, Resource: model.ImportResourceCustomers}
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	jobs := newFakeJobRepo()
+	service := newTestJobService(t, jobs, 1)
+	ctx := actorContext("mia@example.com")
+
+	started := make(chan struct{})
+	job := service.Enqueue(ctx, exportJob(), nil, blockingJob(started))
+	<-started
+
+	response := service.Cancel(ctx, entity.JobParams{JobId: job.ID})
+	if response.State != model.JobCancelled {
+		t.Fatalf("cancelled job is %s, want %s", response.State, model.JobCancelled)
+	}
+	if stored := jobs.job(job.ID); stored.FinishedAt == nil || !strings.Contains(string(stored.Error), "cancelled") {
+		t.Errorf("stored job %+v, want it finished with the cancellation", stored)
+	}
+
+	value := raised(func() { service.Cancel(ctx, entity.JobParams{JobId: job.ID}) })
+	if _, ok := value.(*exception.ConflictErrorStruct); !ok {
+		t.Errorf("cancelling a finished job raised %#v, want a conflict", value)
+	}
+}
+
+func TestCancelQueuedJob(t *testing.T) {
+	jobs := newFakeJobRepo()
+	service := newTestJobService(t, jobs, 1)
+	ctx := actorContext("mia@example.com")
+
+	started := make(chan struct{})
+	running := service.Enqueue(ctx, exportJob(), nil, blockingJob(started))
+	<-started
+
+	ran := make(chan struct{})
+	queued := service.Enqueue(ctx, exportJob(), nil, blockingJob(ran))
+
+	response := service.Cancel(ctx, entity.JobParams{JobId: queued.ID})
+	if response.State != model.JobCancelled || response.StartedAt != "" {
+		t.Errorf("cancelled queued job %+v, want it cancelled without starting", response)
+	}
+	select {
+	case <-ran:
+		t.Error("the cancelled queued job ran")
+	default:
+	}
+
+	if state := jobs.job(running.ID).State; state != model.JobRunning {
+		t.Errorf("the other job is %s, want it still running", state)
+	}
+	service.Cancel(ctx, entity.JobParams{JobId: running.ID})
+}
+
+func TestCancelJobOfOtherUser(t *testing.T) {
+	service := newTestJobService(t, newFakeJobRepo(), 1)
+
+	started := make(chan struct{})
+	job := service.Enqueue(actorContext("mia@example.com"), exportJob(), nil, blockingJob(started))
+	<-started
+	defer service.Cancel(actorContext("mia@example.com"), entity.JobParams{JobId: job.ID})
+
+	value := raised(func() { service.Cancel(actorContext("noah@example.com"), entity.JobParams{JobId: job.ID}) })
+	if _, ok := value.(*exception.NotFoundErrorStruct); !ok {
+		t.Errorf("cancelling the job of another user raised %#v, want not found", value)
+	}
+}
+
+func TestCancelJobOnOtherServer(t *testing.T) {
+	jobs := newFakeJobRepo()
+	runner, other := newTestJobService(t, jobs, 1), newTestJobService(t, jobs, 1)
+	ctx := actorContext("mia@example.com")
+
+	started := make(chan struct{})
+	job := runner.Enqueue(ctx, exportJob(), nil, blockingJob(started))
+	<-started
+
+	// The runner picks up the request on its next heartbeat.
+	go func() {
+		for !jobs.job(job.ID).CancelRequested {
+			time.Sleep(10 * time.Millisecond)
+		}
+		runner.heartbeat(context.Background())
+	}()
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	response := other.Cancel(cancelCtx, entity.JobParams{JobId: job.ID})
+	if response.State != model.JobCancelled {
+		t.Errorf("job cancelled through another server is %s, want %s", response.State, model.JobCancelled)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	jobs := newFakeJobRepo()
+	service := newTestJobService(t, jobs, 1)
+	ctx := actorContext("mia@example.com")
+
+	started := make(chan struct{})
+	job := service.Enqueue(ctx, exportJob(), nil, blockingJob(started))
+	<-started
+	defer service.Cancel(ctx, entity.JobParams{JobId: job.ID})
+
+	stale := time.Now().Add(-2 * config.JobAbandonedAfter)
+	recent := time.Now().Add(-config.JobHeartbeat)
+	jobs.Insert(context.Background(), model.Job{ID: "abandoned", State: model.JobRunning, Owner: "stopped", HeartbeatAt: &stale})
+	jobs.Insert(context.Background(), model.Job{ID: "alive", State: model.JobQueued, Owner: "running", HeartbeatAt: &recent})
+	jobs.mutex.Lock()
+	owned := jobs.jobs[job.ID]
+	owned.HeartbeatAt = &stale
+	jobs.jobs[job.ID] = owned
+	jobs.mutex.Unlock()
+
+	service.heartbeat(context.Background())
+
+	if owned := jobs.job(job.ID); owned.State != model.JobRunning || !owned.HeartbeatAt.After(recent) {
+		t.Errorf("own job %+v, want it running with a fresh heartbeat", owned)
+	}
+	if abandoned := jobs.job("abandoned"); abandoned.State != model.JobFailed || !strings.Contains(string(abandoned.Error), "abandoned") {
+		t.Errorf("abandoned job %+v, want it failed", abandoned)
+	}
+	if alive := jobs.job("alive"); alive.State != model.JobQueued {
+		t.Errorf("job of a beating server is %s, want it left %s", alive.State, model.JobQueued)
+	}
+}
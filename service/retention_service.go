@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/storage"
+	"scylla/repository"
+	"time"
+)
+
+// retentionBatchSize is the number of rows deleted per statement.
+const retentionBatchSize = 1000
+
+type RetentionService interface {
+	Report(ctx context.Context) (response []entity.RetentionReportResponse)
+	FindLogs(ctx context.Context, dataFilter entity.RetentionLogQueryFilter) (response []entity.RetentionLogResponse, paging entity.Meta)
+	Run(ctx context.Context)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type RetentionServiceImpl struct {
+	retentionRepo repository.RetentionRepo
+	fileStorage   storage.Storage
+	policies      []config.RetentionPolicy
+}
+
+func NewRetentionServiceImpl(retentionRepo repository.RetentionRepo, fileStorage storage.Storage, policies []config.RetentionPolicy) RetentionService {
+	return &RetentionServiceImpl{
+		retentionRepo: retentionRepo,
+		fileStorage:   fileStorage,
+		policies:      policies,
+	}
+}
+
+func (service *RetentionServiceImpl) Report(ctx context.Context) (response []entity.RetentionReportResponse) {
+	now := time.Now()
+
+	for _, policy := range service.policies {
+		res := entity.RetentionReportResponse{
+			Policy: policy.Name,
+			Target: retentionTarget(policy),
+			After:  policy.After.String(),
+		}
+
+		var oldest *time.Time
+		var err error
+		if policy.Table != "" {
+			res.Due, oldest, err = service.retentionRepo.CountExpired(ctx, policy.Table, policy.Column, policy.Condition, now.Add(-policy.After))
+		} else {
+			var files []string
+			files, oldest, err = service.expiredFiles(ctx, policy, now)
+			res.Due = int64(len(files))
+		}
+		if err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+
+		if oldest != nil {
+			res.Oldest = oldest.Format(time.RFC3339)
+		}
+		response = append(response, res)
+	}
+	return response
+}
+
+func (service *RetentionServiceImpl) FindLogs(ctx context.Context, dataFilter entity.RetentionLogQueryFilter) (response []entity.RetentionLogResponse, paging entity.Meta) {
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
+
+	if dataFilter.Page == 0 {
+		dataFilter.Page = 1
+	}
+
+	result, total, err := service.retentionRepo.FindLogs(ctx, dataFilter)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, value := range result {
+		response = append(response, entity.RetentionLogResponse{
+			ID:         value.ID,
+			Policy:     value.Policy,
+			Target:     value.Target,
+			Affected:   value.Affected,
+			Error:      value.Error,
+			StartedAt:  value.StartedAt.Format(time.RFC3339),
+			FinishedAt: value.FinishedAt.Format(time.RFC3339),
+		})
+	}
+
+	paging.Page = dataFilter.Page
+	paging.Limit = dataFilter.Limit
+	paging.TotalData = int(total)
+	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+
+	return response, paging
+}
+
+// Run executes every policy once and logs each purge, failed or not. A
+// failing policy does not stop the others.
+func (service *RetentionServiceImpl) Run(ctx context.Context) {
+	for _, policy := range service.policies {
+		entry := model.RetentionLog{
+			Policy:    policy.Name,
+			Target:    retentionTarget(policy),
+			StartedAt: time.Now(),
+		}
+
+		affected, err := service.purge(ctx, policy, entry.StartedAt)
+		entry.Affected = affected
+		entry.FinishedAt = time.Now()
+		if err != nil {
+			entry.Error = err.Error()
+			log.Printf("retention: %s on %s failed after %d removed: %v", entry.Policy, entry.Target, affected, err)
+		} else {
+			log.Printf("retention: %s removed %d from %s", entry.Policy, affected, entry.Target)
+		}
+
+		if err := service.retentionRepo.InsertLog(ctx, entry); err != nil {
+			log.Printf("retention: logging %s: %v", entry.Policy, err)
+		}
+	}
+}
+
+// Start runs the policies now and then every interval until ctx is done.
+func (service *RetentionServiceImpl) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = config.DefaultRetentionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		service.runSafely(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSafely keeps the scheduler alive when a run panics, e.g. on a lost
+// database connection.
+func (service *RetentionServiceImpl) runSafely(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("retention: run aborted: %v", r)
+		}
+	}()
+
+	service.Run(ctx)
+}
+
+// purge deletes the expired rows or files of policy. The files of deleted
+// rows are removed after the rows, so a failure leaves at worst an orphan
+// file and never a row pointing to a missing one.
+func (service *RetentionServiceImpl) purge(ctx context.Context, policy config.RetentionPolicy, now time.Time) (int64, error) {
+	if policy.Table != "" {
+		affected, keys, err := service.retentionRepo.PurgeExpired(ctx, policy.Table, policy.Column, policy.Condition, policy.Files, now.Add(-policy.After), retentionBatchSize)
+		for _, key := range keys {
+			if err := service.fileStorage.Delete(ctx, key); err != nil {
+				log.Printf("retention: %s removing %s: %v", policy.Name, key, err)
+			}
+		}
+		return affected, err
+	}
+
+	files, _, err := service.expiredFiles(ctx, policy, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, file := range files {
+		if err := service.fileStorage.Delete(ctx, file); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func retentionTarget(policy config.RetentionPolicy) string {
+	if policy.Table != "" {
+		return policy.Table
+	}
+	return policy.Prefix
+}
+
+// expiredFiles lists the stored files of a file policy last modified before
+// now - After, with the oldest modification time among them.
+func (service *RetentionServiceImpl) expiredFiles(ctx context.Context, policy config.RetentionPolicy, now time.Time) (files []string, oldest *time.Time, err error) {
+	lister, ok := service.fileStorage.(storage.Lister)
+	if !ok {
+		return nil, nil, fmt.Errorf("policy %s: storage cannot list files", policy.Name)
+	}
+
+	objects, err := lister.List(ctx, policy.Prefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("policy %s: %w", policy.Name, err)
+	}
+
+	before := now.Add(-policy.After)
+	for _, object := range objects {
+		if !object.LastModified.Before(before) {
+			continue
+		}
+
+		files = append(files, object.Key)
+		if modified := object.LastModified; oldest == nil || modified.Before(*oldest) {
+			oldest = &modified
+		}
+	}
+	return files, oldest, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/config"
+	"scylla/pkg/storage"
+	"scylla/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRetentionRepo purges a fixed set of rows per table; failing tables
+// return their error instead.
+type fakeRetentionRepo struct {
+	repository.RetentionRepo
+	expired map[string][]string
+	failing map[string]error
+	before  map[string]time.Time
+	logs    []model.RetentionLog
+}
+
+func (repo *fakeRetentionRepo) CountExpired(ctx context.Context, table string, column string, condition string, before time.Time) (int64, *time.Time, error) {
+	return int64(len(repo.expired[table])), nil, repo.failing[table]
+}
+
+func (repo *fakeRetentionRepo) PurgeExpired(ctx context.Context, table string, column string, condition string, files []string, before time.Time, batchSize int) (int64, []string, error) {
+	repo.before[table] = before
+	if err := repo.failing[table]; err != nil {
+		return 0, nil, err
+	}
+	keys := repo.expired[table]
+	delete(repo.expired, table)
+	return int64(len(keys)), keys, nil
+}
+
+func (repo *fakeRetentionRepo) InsertLog(ctx context.Context, data model.RetentionLog) error {
+	repo.logs = append(repo.logs, data)
+	return nil
+}
+
+var testRetentionPolicies = []config.RetentionPolicy{
+	{Name: "finished_jobs", Table: "jobs", Column: "finished_at", Files: []string{"file_key"}, After: 7 * 24 * time.Hour},
+	{Name: "password_resets", Table: "password_resets", Column: "created_at", After: 24 * time.Hour},
+	{Name: "import_error_files", Prefix: "import-errors/", After: time.Hour},
+}
+
+// newTestRetentionService stores the job export and two error workbooks, one
+// of them written two hours ago.
+func newTestRetentionService(t *testing.T, retentionRepo *fakeRetentionRepo) (*RetentionServiceImpl, storage.Storage) {
+	dir := t.TempDir()
+	files := storage.NewLocalStorage(dir)
+	for _, key := range []string{"exports/1.csv", "import-errors/old.xlsx", "import-errors/new.xlsx"} {
+		if err := files.Put(context.Background(), key, strings.NewReader("x"), 1, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "import-errors", "old.xlsx"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRetentionServiceImpl(retentionRepo, files, testRetentionPolicies).(*RetentionServiceImpl), files
+}
+
+func TestRetentionRun(t *testing.T) {
+	retention := &fakeRetentionRepo{
+		expired: map[string][]string{"jobs": {"exports/1.csv"}},
+		failing: map[string]error{"password_resets": errors.New("connection reset")},
+		before:  make(map[string]time.Time),
+	}
+	service, files := newTestRetentionService(t, retention)
+
+	start := time.Now()
+	service.Run(context.Background())
+
+	want := []struct {
+		policy   string
+		affected int64
+		failed   bool
+	}{
+		{"finished_jobs", 1, false},
+		{"password_resets", 0, true},
+		{"import_error_files", 1, false},
+	}
+	if len(retention.logs) != len(want) {
+		t.Fatalf("logged %d runs, want %d", len(retention.logs), len(want))
+	}
+	for i, entry := range retention.logs {
+		if entry.Policy != want[i].policy || entry.Affected != want[i].affected || (entry.Error != "") != want[i].failed {
+			t.Errorf("log %d: %s removed %d, error %q", i, entry.Policy, entry.Affected, entry.Error)
+		}
+	}
+
+	if age := start.Sub(retention.before["jobs"]); age < 7*24*time.Hour-time.Minute || age > 7*24*time.Hour {
+		t.Errorf("jobs purged before %v, want a week ago", retention.before["jobs"])
+	}
+
+	for key, kept := range map[string]bool{"exports/1.csv": false, "import-errors/old.xlsx": false, "import-errors/new.xlsx": true} {
+		if stored(files, key) != kept {
+			t.Errorf("%s kept: %v, want %v", key, !kept, kept)
+		}
+	}
+}
+
+func TestRetentionReport(t *testing.T) {
+	retention := &fakeRetentionRepo{expired: map[string][]string{"jobs": {"exports/1.csv"}}}
+	service, _ := newTestRetentionService(t, retention)
+
+	response := service.Report(context.Background())
+
+	want := []entity.RetentionReportResponse{
+		{Policy: "finished_jobs", Target: "jobs", After: "168h0m0s", Due: 1},
+		{Policy: "password_resets", Target: "password_resets", After: "24h0m0s", Due: 0},
+		{Policy: "import_error_files", Target: "import-errors/", After: "1h0m0s", Due: 1},
+	}
+	if len(response) != len(want) {
+		t.Fatalf("reported %d policies, want %d", len(response), len(want))
+	}
+	for i, res := range response {
+		// Only the error workbooks know how old their oldest entry is.
+		if (res.Oldest != "") != (res.Target == "import-errors/") {
+			t.Errorf("policy %d: oldest %q", i, res.Oldest)
+		}
+		res.Oldest = ""
+		if res != want[i] {
+			t.Errorf("policy %d: %+v, want %+v", i, res, want[i])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/repository"
+)
+
+type SegmentService interface {
+	Create(ctx context.Context, request entity.CreateSegmentRequest) (response entity.SegmentResponse)
+	Update(ctx context.Context, request entity.UpdateSegmentRequest)
+	Delete(ctx context.Context, params entity.SegmentParams)
+	FindById(ctx context.Context, params entity.SegmentParams) (response entity.SegmentResponse)
+	FindAll(ctx context.Context) (response []entity.SegmentResponse)
+	FindCustomers(ctx context.Context, params entity.SegmentParams, dataFilter entity.SegmentQueryFilter) (response []entity.CustomerResponse, paging entity.Meta)
+}
+
+type SegmentServiceImpl struct {
+	segmentRepo     repository.SegmentRepo
+	customerService CustomerService
+	validate        *validator.Validate
+}
+
+func NewSegmentServiceImpl(segmentRepo repository.SegmentRepo, customerService CustomerService, validate *validator.Validate) SegmentService {
+	return &SegmentServiceImpl{
+		segmentRepo:     segmentRepo,
+		customerService: customerService,
+		validate:        validate,
+	}
+}
+
+func (service *SegmentServiceImpl) Create(ctx context.Context, request entity.CreateSegmentRequest) (response entity.SegmentResponse) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset := model.Segment{
+		Name:        request.Name,
+		Description: request.Description,
+		Filter:      request.Filter,
+	}
+
+	dataset, err = service.segmentRepo.Insert(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	helper.Automapper(dataset, &response)
+	return response
+}
+
+func (service *SegmentServiceImpl) Update(ctx context.Context, request entity.UpdateSegmentRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.segmentRepo.FindById(ctx, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	dataset.Name = request.Name
+	dataset.Description = request.Description
+	dataset.Filter = request.Filter
+
+	err = service.segmentRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *SegmentServiceImpl) Delete(ctx context.Context, params entity.SegmentParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.segmentRepo.Delete(ctx, params.SegmentId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *SegmentServiceImpl) FindById(ctx context.Context, params entity.SegmentParams) (response entity.SegmentResponse) {
+	result, err := service.segmentRepo.FindById(ctx, params.SegmentId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	helper.Automapper(result, &response)
+	return response
+}
+
+func (service *SegmentServiceImpl) FindAll(ctx context.Context) (response []entity.SegmentResponse) {
+	result, err := service.segmentRepo.FindAll(ctx)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		var res entity.SegmentResponse
+		helper.Automapper(row, &res)
+		response = append(response, res)
+	}
+	return response
+}
+
+// FindCustomers evaluates the segment's filter at query time, so the result
+// always reflects the customers' current data and tags.
+func (service *SegmentServiceImpl) FindCustomers(ctx context.Context, params entity.SegmentParams, dataFilter entity.SegmentQueryFilter) (response []entity.CustomerResponse, paging entity.Meta) {
+	segment, err := service.segmentRepo.FindById(ctx, params.SegmentId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	customerFilter, err := helper.ParseCustomerFilter(segment.Filter)
+	if err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
+	customerFilter.Limit = dataFilter.Limit
+	customerFilter.Page = dataFilter.Page
+
+	return service.customerService.FindAllPaging(ctx, customerFilter)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"scylla/entity"
+	"scylla/model"
+	"scylla/pkg/exception"
+	"scylla/pkg/helper"
+	"scylla/repository"
+	"strings"
+)
+
+type TagService interface {
+	Create(ctx context.Context, request entity.CreateTagRequest) (response entity.TagResponse)
+	Update(ctx context.Context, request entity.UpdateTagRequest)
+	Delete(ctx context.Context, params entity.TagParams)
+	FindAll(ctx context.Context) (response []entity.TagResponse)
+	Tag(ctx context.Context, request entity.BulkTagCustomerRequest)
+	Untag(ctx context.Context, request entity.BulkTagCustomerRequest)
+}
+
+type TagServiceImpl struct {
+	tagRepo      repository.TagRepo
+	customerRepo repository.CustomerRepo
+	validate     *validator.Validate
+}
+
+func NewTagServiceImpl(tagRepo repository.TagRepo, customerRepo repository.CustomerRepo, validate *validator.Validate) TagService {
+	return &TagServiceImpl{
+		tagRepo:      tagRepo,
+		customerRepo: customerRepo,
+		validate:     validate,
+	}
+}
+
+func (service *TagServiceImpl) Create(ctx context.Context, request entity.CreateTagRequest) (response entity.TagResponse) {
+	request.Name = strings.TrimSpace(request.Name)
+
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.tagRepo.Insert(ctx, model.Tag{Name: request.Name})
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	helper.Automapper(dataset, &response)
+	return response
+}
+
+func (service *TagServiceImpl) Update(ctx context.Context, request entity.UpdateTagRequest) {
+	request.Name = strings.TrimSpace(request.Name)
+
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.tagRepo.FindById(ctx, request.ID)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+
+	dataset.Name = request.Name
+
+	err = service.tagRepo.Update(ctx, dataset)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *TagServiceImpl) Delete(ctx context.Context, params entity.TagParams) {
+	err := service.validate.Struct(params)
+	helper.ErrorPanic(err)
+
+	err = service.tagRepo.Delete(ctx, params.TagId)
+	if err != nil {
+		panic(exception.NewNotFoundHandler(err.Error()))
+	}
+}
+
+func (service *TagServiceImpl) FindAll(ctx context.Context) (response []entity.TagResponse) {
+	result, err := service.tagRepo.FindAll(ctx)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	for _, row := range result {
+		var res entity.TagResponse
+		helper.Automapper(row, &res)
+		response = append(response, res)
+	}
+	return response
+}
+
+func (service *TagServiceImpl) Tag(ctx context.Context, request entity.BulkTagCustomerRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	service.checkExists(ctx, request)
+
+	err = service.tagRepo.Attach(ctx, request.CustomerIDs, request.TagIDs)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *TagServiceImpl) Untag(ctx context.Context, request entity.BulkTagCustomerRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	err = service.tagRepo.Detach(ctx, request.CustomerIDs, request.TagIDs)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+// checkExists rejects the request when any customer or tag id is unknown,
+// so a typo does not end up as a foreign key error.
+func (service *TagServiceImpl) checkExists(ctx context.Context, request entity.BulkTagCustomerRequest) {
+	customers, err := service.customerRepo.FindByIds(ctx, request.CustomerIDs)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	if missing := missingIds(request.CustomerIDs, len(customers), func(i int) int { return customers[i].ID }); len(missing) > 0 {
+		panic(exception.NewNotFoundHandler(fmt.Sprintf("customers not found: %v", missing)))
+	}
+
+	tags, err := service.tagRepo.FindByIds(ctx, request.TagIDs)
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+	if missing := missingIds(request.TagIDs, len(tags), func(i int) int { return tags[i].ID }); len(missing) > 0 {
+		panic(exception.NewNotFoundHandler(fmt.Sprintf("tags not found: %v", missing)))
+	}
+}
+
+func missingIds(requested []int, found int, idAt func(int) int) []int {
+	seen := make(map[int]bool, found)
+	for i := 0; i < found; i++ {
+		seen[idAt(i)] = true
+	}
+
+	var missing []int
+	for _, id := range requested {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
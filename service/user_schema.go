@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"scylla/model"
+	"scylla/pkg/tabular"
+	"scylla/pkg/utils"
+)
+
+// userSchema describes user imports and exports. Passwords are only
+// imported and are hashed as their row is read.
+var userSchema = tabular.Schema[model.User]{
+	Sheet: "Users",
+	Columns: []tabular.Column[model.User]{
+		{
+			Key:    "id",
+			Header: "ID",
+			Format: func(row model.User) interface{} { return row.ID },
+		},
+		{
+			Key:      "username",
+			Header:   "Username",
+			Aliases:  []string{"Name", "Full Name"},
+			Validate: "required",
+			Example:  "jdoe",
+			Parse: func(ctx context.Context, value string, row *model.User) error {
+				row.Username = value
+				return nil
+			},
+			Format: func(row model.User) interface{} { return row.Username },
+		},
+		{
+			Key:         "email",
+			Header:      "Email",
+			Aliases:     []string{"Email Address", "Mail"},
+			Validate:    "required,email",
+			Unique:      true,
+			Description: "Must not belong to a stored user.",
+			Example:     "jdoe@example.com",
+			Parse: func(ctx context.Context, value string, row *model.User) error {
+				row.Email = value
+				return nil
+			},
+			Format: func(row model.User) interface{} { return row.Email },
+		},
+		{
+			Key:         "password",
+			Header:      "Password",
+			Validate:    "required",
+			Description: "Stored hashed.",
+			Example:     "change-me-123",
+			Parse: func(ctx context.Context, value string, row *model.User) error {
+				hashedPassword, err := utils.HashPassword(value)
+				if err != nil {
+					return fmt.Errorf("password could not be hashed: %v", err)
+				}
+				row.Password = hashedPassword
+				return nil
+			},
+		},
+		{
+			Key:    "created_at",
+			Header: "CreatedAt",
+			Format: func(row model.User) interface{} { return row.CreatedAt },
+		},
+		{
+			Key:    "updated_at",
+			Header: "UpdatedAt",
+			Format: func(row model.User) interface{} { return row.UpdatedAt },
+		},
+	},
+}
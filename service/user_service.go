@@ -1,41 +1,53 @@
 package service
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"errors"
 	"github.com/go-playground/validator/v10"
-	"github.com/tealeg/xlsx"
+	"io"
 	"mime/multipart"
 	"scylla/entity"
 	"scylla/model"
+	"scylla/pkg/config"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/storage"
+	"scylla/pkg/tabular"
 	"scylla/pkg/utils"
 	"scylla/repository"
-	"strings"
-	"sync"
-	"time"
+	"sort"
 )
 
 type UserService interface {
 	Create(ctx context.Context, request entity.CreateUserRequest)
 	Update(ctx context.Context, request entity.UpdateUserRequest)
+	Delete(ctx context.Context, request entity.DeleteUserRequest)
 	DeleteBatch(ctx context.Context, request entity.DeleteBatchUserRequest)
 	FindAll(ctx context.Context, dataFilter entity.UserQueryFilter) (response []entity.UserResponse)
 	FindById(ctx context.Context, params entity.UserParams) (response entity.UserResponse)
-	Export(ctx context.Context, dataFilter entity.UserQueryFilter) (string, error)
-	Import(ctx context.Context, file *multipart.FileHeader) error
+	Export(ctx context.Context, dataFilter entity.UserQueryFilter, options tabular.Options) (response entity.JobResponse)
+	ImportTemplate(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, file *multipart.FileHeader, request entity.ImportUserRequest) (response entity.JobResponse)
 }
 
 type UserServiceImpl struct {
-	userRepo repository.UserRepo
-	validate *validator.Validate
+	userRepo          repository.UserRepo
+	importProfileRepo repository.ImportProfileRepo
+	fileStorage       storage.Storage
+	jobService        JobService
+	importOptions     config.ImportOptions
+	validate          *validator.Validate
 }
 
-func NewUserServiceImpl(userRepo repository.UserRepo, validate *validator.Validate) UserService {
+func NewUserServiceImpl(userRepo repository.UserRepo, importProfileRepo repository.ImportProfileRepo, fileStorage storage.Storage, jobService JobService, importOptions config.ImportOptions, validate *validator.Validate) UserService {
 	return &UserServiceImpl{
-		userRepo: userRepo,
-		validate: validate,
+		userRepo:          userRepo,
+		importProfileRepo: importProfileRepo,
+		fileStorage:       fileStorage,
+		jobService:        jobService,
+		importOptions:     importOptions,
+		validate:          validate,
 	}
 }
 
@@ -68,6 +80,10 @@ func (service *UserServiceImpl) Update(ctx context.Context, request entity.Updat
 		panic(exception.NewNotFoundHandler(err.Error()))
 	}
 
+	if dataset.Version != request.Version {
+		panic(exception.NewPreconditionFailedHandler(repository.ErrVersionConflict.Error()))
+	}
+
 	if dataset.Password != "" {
 		hashedPassword, err := utils.HashPassword(dataset.Password)
 		helper.ErrorPanic(err)
@@ -77,9 +93,30 @@ func (service *UserServiceImpl) Update(ctx context.Context, request entity.Updat
 	dataset.Email = request.Email
 
 	err = service.userRepo.Update(ctx, dataset)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		panic(exception.NewPreconditionFailedHandler(err.Error()))
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+}
+
+func (service *UserServiceImpl) Delete(ctx context.Context, request entity.DeleteUserRequest) {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	dataset, err := service.userRepo.FindById(ctx, request.ID)
 	if err != nil {
 		panic(exception.NewNotFoundHandler(err.Error()))
 	}
+
+	err = service.userRepo.Delete(ctx, dataset.ID, request.Version)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		panic(exception.NewPreconditionFailedHandler(err.Error()))
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
 }
 
 func (service *UserServiceImpl) DeleteBatch(ctx context.Context, request entity.DeleteBatchUserRequest) {
@@ -118,191 +155,143 @@ func (service *UserServiceImpl) FindById(ctx context.Context, params entity.User
 	return response
 }
 
-func (service *UserServiceImpl) Export(ctx context.Context, dataFilter entity.UserQueryFilter) (string, error) {
-	// Create a new Excel file
-	file := xlsx.NewFile()
-	sheet, err := file.AddSheet("Users")
-	helper.ErrorPanic(err)
-
-	headers := []string{"ID", "Username", "Email", "CreatedAt", "UpdatedAt"}
-	headerRow := sheet.AddRow()
-
-	// Define the style for the header row
-	style := xlsx.NewStyle()
-	style.Font = *xlsx.NewFont(12, "Calibri")
-	style.Fill = *xlsx.NewFill("solid", "00FFFF00", "00FFFF00") // Yellow background
+// Export enqueues a job writing the users matching dataFilter to a file in
+// the format and with the columns of options.
+func (service *UserServiceImpl) Export(ctx context.Context, dataFilter entity.UserQueryFilter, options tabular.Options) (response entity.JobResponse) {
+	if _, err := userSchema.Select(options.Columns); err != nil {
+		panic(exception.NewBadRequestHandler(err.Error()))
+	}
 
-	for _, header := range headers {
-		cell := headerRow.AddCell()
-		cell.Value = header
-		cell.SetStyle(style)
+	job := model.Job{
+		Type:        model.JobExport,
+		Resource:    model.ImportResourceUsers,
+		FileName:    options.FileName("user"),
+		ContentType: options.ContentType(),
 	}
+	return service.jobService.Enqueue(ctx, job, nil, func(ctx context.Context, upload *bytes.Reader, output io.Writer) (interface{}, error) {
+		rows, err := service.export(ctx, dataFilter, options, output)
+		return entity.ExportResponse{Rows: rows}, err
+	})
+}
 
-	users, err := service.userRepo.FindAll(ctx, dataFilter)
+// export streams the users matching dataFilter to w, reading them from the
+// database in batches.
+func (service *UserServiceImpl) export(ctx context.Context, dataFilter entity.UserQueryFilter, options tabular.Options, w io.Writer) (int, error) {
+	total, err := service.userRepo.Count(ctx, dataFilter)
 	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
+		return 0, err
 	}
 
-	dataStyle := xlsx.NewStyle()
-	dataStyle.Font = *xlsx.NewFont(12, "Calibri")
-
-	for _, user := range users {
-		dataRow := sheet.AddRow()
-		cell := dataRow.AddCell()
-		cell.SetInt(int(user.ID))
-		cell.SetStyle(dataStyle)
-
-		cell = dataRow.AddCell()
-		cell.Value = user.Username
-		cell.SetStyle(dataStyle)
+	return writeSheet(ctx, w, userSchema, options, total, func(write func(batch []model.User) error) error {
+		return service.userRepo.FindAllInBatches(ctx, dataFilter, exportBatchSize, write)
+	})
+}
 
-		cell = dataRow.AddCell()
-		cell.Value = user.Email
-		cell.SetStyle(dataStyle)
+// ImportTemplate builds the workbook to fill in for Import.
+func (service *UserServiceImpl) ImportTemplate(ctx context.Context, w io.Writer) error {
+	return writeTemplate(w, userSchema)
+}
 
-		cell = dataRow.AddCell()
-		cell.Value = user.CreatedAt.Format("2006-01-02")
-		cell.SetStyle(dataStyle)
+// Import checks the request and enqueues a job importing the file, or only
+// previewing the import with mode=dry_run.
+func (service *UserServiceImpl) Import(ctx context.Context, file *multipart.FileHeader, request entity.ImportUserRequest) (response entity.JobResponse) {
+	request = service.importRequest(request)
+	mapping := importMapping(ctx, service.importProfileRepo, model.ImportResourceUsers, request.Profile, request.Mapping)
+	options := importOptions(request.Delimiter, request.Encoding)
+
+	job := model.Job{Type: model.JobImport, Resource: model.ImportResourceUsers}
+	return service.jobService.Enqueue(ctx, job, file, func(ctx context.Context, upload *bytes.Reader, output io.Writer) (interface{}, error) {
+		imported := service.readImport(ctx, upload, mapping, options)
+		if request.Mode == entity.ImportModeDryRun {
+			return service.previewImport(ctx, imported), nil
+		}
+		return service.writeImport(ctx, imported, request)
+	})
+}
 
-		cell = dataRow.AddCell()
-		cell.Value = user.UpdatedAt.Format("2006-01-02")
-		cell.SetStyle(dataStyle)
+// writeImport inserts the valid rows of an import. Invalid rows are returned
+// as an *exception.NewExcelValidationError, after inserting the valid ones
+// unless the import is atomic.
+func (service *UserServiceImpl) writeImport(ctx context.Context, imported importedSheet[model.User], request entity.ImportUserRequest) (response entity.ImportUserResponse, err error) {
+	excelValidation := exception.NewExcelValidationError{}
+	for _, result := range imported.rejected {
+		for _, issue := range result.issues {
+			excelValidation.AddHandler(issue.Field, issue.Row, issue.Message)
+		}
 	}
 
-	// Save the Excel file
-	timestamp := time.Now().Format("2006-01-02_150405")
-	filePath := fmt.Sprintf("user_%s.xlsx", timestamp)
-
-	err = file.Save(filePath)
-	if err != nil {
-		return "", err
+	// Atomic imports write nothing unless every row is valid, and then
+	// write all of them in a single batch, which is one transaction.
+	batchSize := service.importOptions.BatchSize
+	if request.Mode == entity.ImportModeAtomic {
+		if len(excelValidation.Errors) > 0 {
+			excelValidation.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, true)
+			return response, &excelValidation
+		}
+		batchSize = len(imported.valid)
 	}
-	return filePath, nil
-}
 
-func (service *UserServiceImpl) Import(ctx context.Context, file *multipart.FileHeader) error {
-	src, err := file.Open()
-	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	users := make([]model.User, len(imported.valid))
+	for i, result := range imported.valid {
+		users[i] = result.value
 	}
-	defer src.Close()
 
-	xlFile, err := xlsx.OpenReaderAt(src, file.Size)
+	err = importChunks(ctx, users, batchSize, func(chunk []model.User) error {
+		if err := service.userRepo.InsertBatch(ctx, chunk, len(chunk)); err != nil {
+			return err
+		}
+		response.Inserted += len(chunk)
+		return nil
+	})
 	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
+		return response, exception.NewInternalServerErrorHandler(err.Error())
 	}
 
-	sheet := xlFile.Sheets[0]
+	if len(excelValidation.Errors) > 0 {
+		excelValidation.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, false)
+		return response, &excelValidation
+	}
 
-	// Create channels for error handling and synchronization
-	errorChan := make(chan error)
-	excelValidation := exception.NewExcelValidationError{}
-	wg := sync.WaitGroup{}
+	return response, nil
+}
 
-	uniqueTracker := make(map[string]map[string]bool)
+// previewImport tells what writeImport would do with each row.
+func (service *UserServiceImpl) previewImport(ctx context.Context, imported importedSheet[model.User]) (response entity.ImportPreviewResponse) {
+	valid, rejected := imported.valid, imported.rejected
 
-	for _, rule := range helper.RulesExcelUser {
-		fieldName := strings.Split(rule, ",")[0]
-		uniqueTracker[fieldName] = make(map[string]bool)
+	response.Rows = []entity.ImportPreviewRow{}
+	for _, result := range valid {
+		response.Inserted++
+		response.Rows = append(response.Rows, entity.ImportPreviewRow{Row: result.rowIndex + 1, Action: entity.ImportActionInsert, Email: result.value.Email})
 	}
 
-	for rowIndex, row := range sheet.Rows {
-		if rowIndex == 0 {
-			continue
-		}
-
-		wg.Add(1)
-
-		go func(rowIndex int, row *xlsx.Row) {
-			defer wg.Done()
-
-			rowErrors := map[string][]string{}
-
-			// Validate each cell dynamically
-			for colIndex, rule := range helper.RulesExcelUser {
-				fields := strings.Split(rule, ",")
-				fieldName := fields[0]
-				cell := row.Cells[colIndex]
-				rules := fields[1:]
-
-				for _, r := range rules {
-					switch r {
-					case "required":
-						if cell.String() == "" {
-							rowErrors[fieldName] = append(rowErrors[fieldName], fmt.Sprintf("%s row %d is required", fieldName, rowIndex+1))
-						}
-					case "unique":
-						if uniqueTracker[fieldName][cell.String()] {
-							rowErrors[fieldName] = append(rowErrors[fieldName], fmt.Sprintf("%s '%s' is not unique row %d", fieldName, cell.String(), rowIndex+1))
-							return
-						}
-						uniqueTracker[fieldName][cell.String()] = true
-					}
-				}
-			}
-
-			// If there are validation errors, skip further processing for this row
-			if len(rowErrors) > 0 {
-				for field, errs := range rowErrors {
-					for _, err := range errs {
-						excelValidation.AddHandler(field, rowIndex+1, err)
-					}
-				}
-				return
-			}
-
-			// Check unique in the database
-			for colIndex, rule := range helper.RulesExcelUser {
-				fields := strings.Split(rule, ",")
-				fieldName := fields[0]
-				rules := fields[1:]
-				for _, r := range rules {
-					if r == "unique" {
-						cell := row.Cells[colIndex]
-						data := service.userRepo.CheckColumnExists(ctx, fieldName, cell.String())
-						if data != false {
-							excelValidation.AddHandler(fieldName, rowIndex+1, fmt.Sprintf("%s '%s' already taken", fieldName, cell.String()))
-							return
-						}
-					}
-				}
-			}
-
-			var user model.User
-
-			for i, cell := range row.Cells {
-				switch i {
-				case 0:
-					user.Username = cell.String()
-				case 1:
-					user.Email = cell.String()
-				case 2:
-					hashedPassword, err := utils.HashPassword(cell.String())
-					if err != nil {
-						errorChan <- fmt.Errorf("error hashing password in row %d: %v", rowIndex, err)
-						return
-					}
-					user.Password = hashedPassword
-				}
-			}
-
-			service.userRepo.Insert(ctx, user)
-
-		}(rowIndex, row)
+	for _, result := range rejected {
+		response.Rejected++
+		response.Rows = append(response.Rows, importPreviewRejected(result, result.value.Email))
 	}
 
-	go func() {
-		wg.Wait()
-		close(errorChan)
-	}()
+	sort.Slice(response.Rows, func(i, j int) bool {
+		return response.Rows[i].Row < response.Rows[j].Row
+	})
+	response.ErrorFile = importErrorFile(ctx, service.fileStorage, service.importOptions, imported, true)
+	return response
+}
 
-	for err := range errorChan {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
+func (service *UserServiceImpl) importRequest(request entity.ImportUserRequest) entity.ImportUserRequest {
+	err := service.validate.Struct(request)
+	helper.ErrorPanic(err)
+
+	if request.Mode == "" {
+		request.Mode = entity.ImportModePartial
 	}
+	return request
+}
 
-	if len(excelValidation.Errors) > 0 {
-		return &excelValidation
+// readImport validates every row of the uploaded file.
+func (service *UserServiceImpl) readImport(ctx context.Context, file *bytes.Reader, mapping map[string]string, options tabular.Options) importedSheet[model.User] {
+	exists := func(ctx context.Context, key string, value string) bool {
+		return service.userRepo.CheckColumnExists(ctx, key, value)
 	}
 
-	return nil
+	return readSheet(ctx, file, userSchema, mapping, options, service.validate, service.importOptions.Workers, exists)
 }